@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"school_management_api/internal/api/handlers"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/internal/repository/memory"
+	"school_management_api/internal/repository/migrations"
+	"school_management_api/internal/repository/sqlconnect"
+	"school_management_api/internal/seed"
+)
+
+// repositories holds the data stores shared by the handlers and middlewares.
+type repositories struct {
+	handlers.Repositories
+	logins repository.LoginAttemptRepository
+}
+
+// openRepositories creates the repositories selected with DB_DRIVER.
+// "memory" keeps all data in process so the API can run without MariaDB;
+// any other value connects to MariaDB. The returned function releases them.
+func openRepositories(driver string) (repositories, func(), error) {
+	if driver == "memory" {
+		store := memory.NewStore()
+		if err := seedMemoryStore(store, os.Getenv("MEMORY_SEED_DIR")); err != nil {
+			return repositories{}, nil, err
+		}
+		fmt.Println("Using in-memory repository")
+		repos := repositories{
+			Repositories: handlers.Repositories{
+				Students:    store.Students,
+				Teachers:    store.Teachers,
+				Classes:     store.Classes,
+				Subjects:    store.Subjects,
+				Enrollments: store.Enrollments,
+				Assessments: store.Assessments,
+				Attendance:  store.Attendance,
+				Executives:  store.Executives,
+				Accounts:    store.Accounts,
+				Tokens:      store.Tokens,
+				TwoFactor:   store.TwoFactor,
+				APIKeys:     store.APIKeys,
+				Audit:       store.Audit,
+			},
+			logins: store.Logins,
+		}
+		return repos, func() {}, nil
+	}
+
+	// Create the shared database connection pool once at startup
+	db, err := sqlconnect.ConnectDb()
+	if err != nil {
+		return repositories{}, nil, err
+	}
+
+	// Optionally bring the schema up to date before serving requests
+	if os.Getenv("DB_AUTO_MIGRATE") == "true" {
+		if err := migrateDatabase(db); err != nil {
+			db.Close()
+			return repositories{}, nil, err
+		}
+	}
+
+	repo := sqlconnect.NewRepository(db)
+	repos := repositories{
+		Repositories: handlers.Repositories{
+			Students:    repo.Students,
+			Teachers:    repo.Teachers,
+			Classes:     repo.Classes,
+			Subjects:    repo.Subjects,
+			Enrollments: repo.Enrollments,
+			Assessments: repo.Assessments,
+			Attendance:  repo.Attendance,
+			Executives:  repo.Executives,
+			Accounts:    repo.Accounts,
+			Tokens:      repo.Tokens,
+			TwoFactor:   repo.TwoFactor,
+			APIKeys:     repo.APIKeys,
+			Audit:       repo.Audit,
+		},
+		logins: repo.Logins,
+	}
+	return repos, func() { db.Close() }, nil
+}
+
+// migrateDatabase applies every pending schema migration.
+func migrateDatabase(db *sql.DB) error {
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %06d_%s\n", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// seedMemoryStore loads teachers_list.json, students_list.json and execsdata.json
+// from dir into the store, skipping files that do not exist. Teachers are
+// loaded first so they become the homeroom teachers of the classes they name
+// and create the subjects they teach.
+func seedMemoryStore(store *memory.Store, dir string) error {
+	if dir == "" {
+		dir = "."
+	}
+	seeder := seed.NewSeeder(store.Students, store.Teachers, store.Classes, store.Subjects, store.Executives)
+	seeder.Log = os.Stderr
+
+	var teachers []seed.TeacherRow
+	if err := readSeedFile(filepath.Join(dir, "teachers_list.json"), &teachers); err != nil {
+		return err
+	}
+	fmt.Println(seeder.SeedTeachers(teachers))
+
+	var students []seed.StudentRow
+	if err := readSeedFile(filepath.Join(dir, "students_list.json"), &students); err != nil {
+		return err
+	}
+	fmt.Println(seeder.SeedStudents(students))
+
+	var executives []models.Executive
+	if err := readSeedFile(filepath.Join(dir, "execsdata.json"), &executives); err != nil {
+		return err
+	}
+	fmt.Println(seeder.SeedExecutives(executives))
+	return nil
+}
+
+// readSeedFile decodes a JSON array from path into v. A missing file is not an error.
+func readSeedFile(path string, v any) error {
+	err := seed.ReadFile(path, v)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"school_management_api/internal/api/handlers"
 	mw "school_management_api/internal/api/middlewares"
 	"school_management_api/internal/api/router"
-	"school_management_api/internal/repository/sqlconnect"
+	"school_management_api/internal/grading"
+	"school_management_api/internal/mailer"
 	"school_management_api/pkg/utils"
+	"syscall"
 
 	"github.com/joho/godotenv"
 )
@@ -21,10 +25,56 @@ func main() {
 		return
 	}
 
-	_, err := sqlconnect.ConnectDb()
+	// Create the repositories once at startup, backed by MariaDB or memory
+	repos, closeRepo, err := openRepositories(os.Getenv("DB_DRIVER"))
 	if err != nil {
-		utils.ErrorHandler(err, "")
+		utils.ErrorHandler(err, "Error initializing the repositories")
+		return
+	}
+	defer closeRepo()
+
+	// Emails go through the mailer selected with MAILER
+	mail, err := mailer.FromEnv()
+	if err != nil {
+		utils.ErrorHandler(err, "Error initializing the mailer")
+		return
+	}
+
+	// Load the keys signing and verifying tokens, see LoadKeyRingFromEnv
+	ring, err := utils.LoadKeyRingFromEnv()
+	if err != nil {
+		utils.ErrorHandler(err, "Error loading the JWT keys")
+		return
+	}
+	utils.SetKeyRing(ring)
+	go reloadKeysOnSIGHUP()
+
+	// Fail fast on bad password hashing parameters, see Argon2ParamsFromEnv
+	if _, err := utils.Argon2ParamsFromEnv(); err != nil {
+		utils.ErrorHandler(err, "Error reading the password hashing parameters")
+		return
+	}
+
+	// Failed logins lock usernames and IPs out, see LoginLimitsFromEnv
+	limits, err := handlers.LoginLimitsFromEnv()
+	if err != nil {
+		utils.ErrorHandler(err, "Error reading the login limits")
+		return
 	}
+	logins := handlers.NewLoginGuard(repos.logins, limits)
+
+	// Load the letter grades of report cards, see grading_scale.json
+	scaleFile := os.Getenv("GRADING_SCALE_FILE")
+	if scaleFile == "" {
+		scaleFile = "grading_scale.json"
+	}
+	scale, err := grading.LoadScale(scaleFile)
+	if err != nil {
+		utils.ErrorHandler(err, "Error loading the grading scale")
+		return
+	}
+
+	handler := handlers.NewHandler(repos.Repositories, logins, mail, scale)
 
 	port := os.Getenv("API_PORT")
 	cert := "cert.pem"
@@ -39,10 +89,29 @@ func main() {
 	}
 
 	// Initialize the router
-	router := router.MainRouter()
+	router := router.MainRouter(handler)
+
+	// Load the roles allowed on each route, see rbac_policy.json
+	policyFile := os.Getenv("RBAC_POLICY_FILE")
+	if policyFile == "" {
+		policyFile = "rbac_policy.json"
+	}
+	policy, err := mw.LoadPolicy(policyFile)
+	if err != nil {
+		utils.ErrorHandler(err, "Error loading the access policy")
+		return
+	}
+
+	// Authenticate the API key or token first, then check the role and scopes it carries
+	authenticate := func(next http.Handler) http.Handler {
+		jwt := mw.JwtMiddleware(repos.Executives, repos.Accounts, repos.Tokens)
+		return mw.APIKeyMiddleware(repos.APIKeys, repos.Executives, jwt)(mw.RBAC(policy)(next))
+	}
 
 	// exclude certain routes from JWT middleware
-	protectedRoutes := mw.MiddlewaresExcludePath(mw.JwtMiddleware, "/executives/login")
+	protectedRoutes := mw.MiddlewaresExcludePath(authenticate,
+		"/executives/login", "/executives/refresh", "/executives/forgotpassword", "/executives/resetpassword",
+		"/me/login", "/.well-known/jwks.json")
 
 	// rate limiting middleware can be added here
 	// rl := mw.NewRateLimiter(5, time.Minute)
@@ -76,6 +145,7 @@ func main() {
 		// mw.ResponseTime,    // 5. Response Time: Measure as much as possible
 		protectedRoutes,
 		mw.SecurityHeaders, // 4. Security Headers: Set headers for all responses
+		mw.RequestID,       // Request ID: Tag every request, including rejected ones
 		// rl.Middleware,      // 3. Rate Limiting: Block abusive clients early, before expensive work
 		// mw.Hpp(hppOptions), // 2. HPP: Sanitize query/body params before any logic uses them
 		// mw.Cors,            // 1. CORS: Handle cross-origin and preflight requests first
@@ -94,3 +164,25 @@ func main() {
 		utils.ErrorHandler(err, "Error starting the server")
 	}
 }
+
+// reloadKeysOnSIGHUP reloads the .env file and the JWT keys whenever the
+// process gets SIGHUP, so keys can be rotated by editing the key ring and
+// signalling the server.
+// A key ring that fails to load is reported and the current one is kept.
+func reloadKeysOnSIGHUP() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		// Pick up a changed JWT_KEYS_FILE or secret from the .env file too
+		if err := godotenv.Overload(); err != nil {
+			utils.ErrorHandler(err, "Error reloading the .env file")
+		}
+		ring, err := utils.LoadKeyRingFromEnv()
+		if err != nil {
+			utils.ErrorHandler(err, "Error reloading the JWT keys, keeping the current ones")
+			continue
+		}
+		utils.SetKeyRing(ring)
+		fmt.Println("Reloaded the JWT keys")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"school_management_api/internal/repository/migrations"
+	"school_management_api/internal/repository/sqlconnect"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `Usage: migrate <command> [argument]
+
+Commands:
+  up             apply all pending migrations
+  down [steps]   revert the last applied migration, or the last <steps> migrations
+  status         list the migrations and whether they are applied
+  to <version>   migrate up or down to the given version (0 reverts everything)`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// Load environment variables from .env file, if present
+	_ = godotenv.Load()
+
+	db, err := sqlconnect.ConnectDb()
+	if err != nil {
+		fmt.Println("Error connecting to database:", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		fmt.Println("Error loading migrations:", err)
+		os.Exit(1)
+	}
+
+	if err := run(context.Background(), migrator, os.Args[1], os.Args[2:]); err != nil {
+		fmt.Println("Error:", err)
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// run executes a single migrate command.
+func run(ctx context.Context, migrator *migrations.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("Applied", applied)
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid number of steps: %s", args[0])
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		printMigrations("Reverted", reverted)
+		return err
+
+	case "to":
+		if len(args) == 0 {
+			return fmt.Errorf("missing target version")
+		}
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[0])
+		}
+		changed, err := migrator.To(ctx, version)
+		printMigrations("Migrated", changed)
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt
+			}
+			fmt.Printf("%06d  %-30s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+
+	default:
+		fmt.Println(usage)
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+// printMigrations lists the migrations touched by a command.
+func printMigrations(action string, changed []migrations.Migration) {
+	if len(changed) == 0 {
+		fmt.Println("No migrations to run, database is up to date")
+		return
+	}
+	for _, migration := range changed {
+		fmt.Printf("%s %06d_%s\n", action, migration.Version, migration.Name)
+	}
+}
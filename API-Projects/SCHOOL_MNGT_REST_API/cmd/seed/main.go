@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository/sqlconnect"
+	"school_management_api/internal/seed"
+	"school_management_api/pkg/utils"
+
+	"github.com/joho/godotenv"
+)
+
+// seedTables lists the seeded tables, children first so they can be
+// truncated without violating foreign keys. The logins, tokens, API keys
+// and recovery codes of the seeded people go with them: TRUNCATE does not
+// cascade, and left behind they would belong to whoever gets the same ID
+// next. The audit log is kept on purpose, it is the history of the data
+// and not part of it.
+var seedTables = []string{
+	"accounts", "api_keys", "refresh_tokens", "revoked_tokens", "recovery_codes", "login_attempts",
+	"attendance", "grades", "assessments", "enrollments", "students", "subjects", "class_teachers", "classes", "teachers", "execs",
+}
+
+func main() {
+	teachersFile := flag.String("teachers", "teachers_list.json", "JSON file with teachers to seed, empty to skip")
+	studentsFile := flag.String("students", "students_list.json", "JSON file with students to seed, empty to skip")
+	execsFile := flag.String("execs", "execsdata.json", "JSON file with executives to seed, empty to skip")
+	truncate := flag.Bool("truncate", false, "delete every grade, assessment, enrollment, student, subject, class, teacher and executive, with their logins, before seeding")
+	academicYear := flag.String("year", "", "academic year of the classes named in the seed files, e.g. 2026-2027 (default the current one)")
+	flag.Parse()
+
+	// Load environment variables from .env file, if present
+	_ = godotenv.Load()
+
+	db, err := sqlconnect.ConnectDb()
+	if err != nil {
+		fmt.Println("Error connecting to database:", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if *truncate {
+		if err := truncateTables(context.Background(), db); err != nil {
+			fmt.Println("Error truncating tables:", err)
+			db.Close()
+			os.Exit(1)
+		}
+		fmt.Println("Truncated tables:", seedTables)
+	}
+
+	repo := sqlconnect.NewRepository(db)
+	seeder := seed.NewSeeder(repo.Students, repo.Teachers, repo.Classes, repo.Subjects, repo.Executives)
+	seeder.Log = os.Stderr
+	if *academicYear != "" {
+		if !utils.IsAcademicYear(*academicYear) {
+			fmt.Println("Invalid academic year:", *academicYear)
+			db.Close()
+			os.Exit(1)
+		}
+		seeder.AcademicYear = *academicYear
+	}
+
+	var summaries []seed.Summary
+	failed := false
+
+	// Teachers go first so they become the homeroom teachers of the classes they name
+	if *teachersFile != "" {
+		var teachers []seed.TeacherRow
+		if err := seed.ReadFile(*teachersFile, &teachers); err != nil {
+			fmt.Println("Error reading teachers:", err)
+			failed = true
+		} else {
+			summaries = append(summaries, seeder.SeedTeachers(teachers))
+		}
+	}
+
+	if *studentsFile != "" {
+		var students []seed.StudentRow
+		if err := seed.ReadFile(*studentsFile, &students); err != nil {
+			fmt.Println("Error reading students:", err)
+			failed = true
+		} else {
+			summaries = append(summaries, seeder.SeedStudents(students))
+		}
+	}
+
+	if *execsFile != "" {
+		var executives []models.Executive
+		if err := seed.ReadFile(*execsFile, &executives); err != nil {
+			fmt.Println("Error reading executives:", err)
+			failed = true
+		} else {
+			summaries = append(summaries, seeder.SeedExecutives(executives))
+		}
+	}
+
+	fmt.Println("Seed summary:")
+	for _, summary := range summaries {
+		fmt.Println(" ", summary)
+		if summary.Failed > 0 {
+			failed = true
+		}
+	}
+
+	if failed {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// truncateTables empties the seeded tables and resets their auto increment
+// counters. Foreign key checks are disabled on the connection while truncating.
+func truncateTables(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "SET FOREIGN_KEY_CHECKS = 1")
+
+	for _, table := range seedTables {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE "+table); err != nil {
+			return fmt.Errorf("truncate %s: %w", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	mw "school_management_api/internal/api/middlewares"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+// CreateTeacherAccountHandler gives a teacher a login.
+func (h *Handler) CreateTeacherAccountHandler(w http.ResponseWriter, r *http.Request) {
+	h.createAccount(w, r, models.SubjectTeacher)
+}
+
+// CreateStudentAccountHandler gives a student a login.
+func (h *Handler) CreateStudentAccountHandler(w http.ResponseWriter, r *http.Request) {
+	h.createAccount(w, r, models.SubjectStudent)
+}
+
+// DeleteTeacherAccountHandler removes the login of a teacher.
+func (h *Handler) DeleteTeacherAccountHandler(w http.ResponseWriter, r *http.Request) {
+	h.deleteAccount(w, r, models.SubjectTeacher)
+}
+
+// DeleteStudentAccountHandler removes the login of a student.
+func (h *Handler) DeleteStudentAccountHandler(w http.ResponseWriter, r *http.Request) {
+	h.deleteAccount(w, r, models.SubjectStudent)
+}
+
+// subjectID returns the teacher or student ID of the path.
+func subjectID(r *http.Request, subjectType string) (int, error) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, utils.BadRequestError(err, fmt.Sprintf("Invalid %s ID: %s", subjectType, idStr))
+	}
+	return id, nil
+}
+
+// createAccount stores the username and password hash of a new account for
+// the teacher or student of the path.
+func (h *Handler) createAccount(w http.ResponseWriter, r *http.Request, subjectType string) {
+	id, err := subjectID(r, subjectType)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username" validate:"required,alphanum,min=3,max=50"`
+		Password string `json:"password" validate:"required,strongpassword"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	account, err := h.accounts.CreateAccount(r.Context(), models.Account{
+		SubjectType: subjectType,
+		SubjectID:   id,
+		Username:    req.Username,
+		Password:    passwordHash,
+	})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(account)
+}
+
+// deleteAccount removes the account of the teacher or student of the path.
+// Tokens already issued stop working with it.
+func (h *Handler) deleteAccount(w http.ResponseWriter, r *http.Request, subjectType string) {
+	id, err := subjectID(r, subjectType)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	if err := h.accounts.DeleteAccount(r.Context(), subjectType, id); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}{
+		Status:  "success",
+		Message: fmt.Sprintf("Account of %s with ID %d deleted successfully", subjectType, id),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// AccountLoginHandler logs a teacher or student in. Failures are answered
+// and locked out like executive logins, and outdated password hashes are
+// upgraded the same way. The access token is returned in the
+// body only, to be sent in an "Authorization: Bearer" header; these logins
+// have no refresh token and end when the token expires.
+func (h *Handler) AccountLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Username == "" || req.Password == "" {
+		utils.WriteError(w, r, utils.ValidationError(nil, "Username and password are required"))
+		return
+	}
+
+	ip := mw.ClientIP(r)
+	now := time.Now()
+	wait, err := h.accountLogins.Check(req.Username, ip, now)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		utils.WriteError(w, r, utils.TooManyRequestsError(nil, "too many failed login attempts, try again later"))
+		return
+	}
+
+	loginFailed := func() {
+		if err := h.accountLogins.Failed(req.Username, ip, now); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		utils.WriteError(w, r, utils.UnauthorizedError(nil, "invalid username or password"))
+	}
+
+	account, err := h.accounts.GetAccountByUsername(req.Username)
+	if err != nil {
+		var appErr *utils.AppError
+		if !errors.As(err, &appErr) || appErr.Code != utils.CodeNotFound {
+			utils.WriteError(w, r, err)
+			return
+		}
+		utils.VerifyDummyPassword(req.Password)
+		loginFailed()
+		return
+	}
+
+	if err := utils.VerifyPassword(account.Password, req.Password); err != nil || account.InactiveStatus {
+		loginFailed()
+		return
+	}
+
+	if err := h.accountLogins.Succeeded(req.Username); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	h.upgradeAccountPasswordHash(r, account, req.Password)
+
+	token, err := utils.SignAccountToken(account.SubjectType, account.SubjectID, account.Username)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Token       string `json:"token"`
+		SubjectType string `json:"subject_type"`
+	}{
+		Token:       token,
+		SubjectType: account.SubjectType,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// upgradeAccountPasswordHash rehashes the password of a teacher or student
+// who just logged in when the stored hash is legacy or its parameters are
+// outdated. The write is audited as made by the account holder; a failure
+// is only logged, the login goes on with the old hash.
+func (h *Handler) upgradeAccountPasswordHash(r *http.Request, account models.Account, password string) {
+	newHash, ok := rehashPassword(account.Password, password)
+	if !ok {
+		return
+	}
+
+	actor := repository.Actor{Type: account.SubjectType, ID: account.SubjectID, Name: account.Username}
+	ctx := repository.WithActor(r.Context(), actor)
+	if err := h.accounts.ReplaceAccountPasswordHash(ctx, account.ID, account.Password, newHash); err != nil {
+		utils.ErrorHandler(err, "failed to upgrade password hash")
+	}
+}
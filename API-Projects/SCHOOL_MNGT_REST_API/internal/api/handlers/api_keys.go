@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// defaultAPIKeyLifetime is how long a key works when no expiry is asked for.
+const defaultAPIKeyLifetime = 90
+
+// apiKeyExecutiveID returns the executive ID of the path.
+func apiKeyExecutiveID(r *http.Request) (int, error) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, utils.BadRequestError(err, fmt.Sprintf("Invalid Executive ID: %s", idStr))
+	}
+	return id, nil
+}
+
+// CreateAPIKeyHandler creates an API key acting for the executive of the
+// path within the requested scopes. The key itself is returned only here;
+// only its hash is stored.
+func (h *Handler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := apiKeyExecutiveID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var req struct {
+		Name          string   `json:"name" validate:"required,max=100"`
+		Scopes        []string `json:"scopes" validate:"required,min=1,dive,apikeyscope"`
+		ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultAPIKeyLifetime
+	}
+
+	if _, err := h.executives.GetUserByID(id); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	plaintext, prefix, keyHash, err := utils.GenerateAPIKey()
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	key, err := h.apiKeys.CreateAPIKey(r.Context(), models.APIKey{
+		ExecutiveID: id,
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     keyHash,
+		Scopes:      slices.Compact(slices.Sorted(slices.Values(req.Scopes))),
+		CreatedAt:   now,
+		ExpiresAt:   now.AddDate(0, 0, req.ExpiresInDays),
+	})
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := struct {
+		models.APIKey
+		Key string `json:"key"`
+	}{
+		APIKey: key,
+		Key:    plaintext,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListAPIKeysHandler lists the API keys of the executive of the path,
+// revoked ones included.
+func (h *Handler) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := apiKeyExecutiveID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	keys, err := h.apiKeys.ListAPIKeys(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string          `json:"status"`
+		Count  int             `json:"count"`
+		Data   []models.APIKey `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(keys),
+		Data:   keys,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeAPIKeyHandler revokes an API key of the executive of the path.
+// Requests sending it fail from then on.
+func (h *Handler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := apiKeyExecutiveID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	keyIDStr := r.PathValue("keyid")
+	keyID, err := strconv.Atoi(keyIDStr)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Invalid API key ID: %s", keyIDStr)))
+		return
+	}
+
+	if err := h.apiKeys.RevokeAPIKey(r.Context(), id, keyID, time.Now()); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}{
+		Status:  "success",
+		Message: fmt.Sprintf("API key with ID %d revoked successfully", keyID),
+	}
+	json.NewEncoder(w).Encode(response)
+}
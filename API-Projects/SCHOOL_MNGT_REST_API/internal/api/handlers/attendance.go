@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	mw "school_management_api/internal/api/middlewares"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// parseDateRange reads the from and to query parameters, dates formatted
+// as 2006-01-02 bounding a range that includes both. Either may be left out.
+func parseDateRange(values url.Values) (from string, to string, err error) {
+	from, to = values.Get("from"), values.Get("to")
+	for _, param := range []string{"from", "to"} {
+		value := values.Get(param)
+		if _, err := time.Parse(time.DateOnly, value); value != "" && err != nil {
+			return "", "", fmt.Errorf("invalid %s: %s, must be a YYYY-MM-DD date", param, value)
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return "", "", fmt.Errorf("from must not be after to")
+	}
+	return from, to, nil
+}
+
+// parseAttendanceFilter reads the filters of the attendance reports:
+// class_id and the from and to dates. Other parameters, besides those in
+// extra, are rejected.
+func parseAttendanceFilter(values url.Values, extra ...string) (repository.AttendanceFilter, error) {
+	var filter repository.AttendanceFilter
+	var err error
+
+	for param := range values {
+		if param != "class_id" && param != "from" && param != "to" && !slices.Contains(extra, param) {
+			return filter, fmt.Errorf("unknown filter field: %s", param)
+		}
+	}
+
+	if value := values.Get("class_id"); value != "" {
+		if filter.ClassID, err = strconv.Atoi(value); err != nil || filter.ClassID < 1 {
+			return filter, fmt.Errorf("invalid class_id: %s", value)
+		}
+	}
+	filter.From, filter.To, err = parseDateRange(values)
+	return filter, err
+}
+
+// authorizeAttendance returns a Forbidden error when the caller is a
+// teacher who neither is the homeroom teacher of the class nor is assigned
+// to it. Staff may take the roll of every class.
+func (h *Handler) authorizeAttendance(r *http.Request, classID int) error {
+	subjectType, teacherID, ok := mw.SubjectFromContext(r.Context())
+	if !ok || subjectType != models.SubjectTeacher {
+		return nil
+	}
+
+	class, err := h.classes.GetByID(classID)
+	if err != nil {
+		return err
+	}
+	if class.HomeroomTeacherID != nil && *class.HomeroomTeacherID == teacherID {
+		return nil
+	}
+	teachers, err := h.classes.GetTeachersByClassID(classID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(teachers, func(teacher models.Teacher) bool { return teacher.ID == teacherID }) {
+		return utils.ForbiddenError(nil, fmt.Sprintf("you do not teach class %d", classID))
+	}
+	return nil
+}
+
+// TakeRollHandler handles POST requests taking the attendance of the whole
+// class of the path on one date and period. Taking the roll again replaces
+// the statuses of the earlier one.
+func (h *Handler) TakeRollHandler(w http.ResponseWriter, r *http.Request) {
+	classID, _, err := classPathIDs(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var roll models.Roll
+	if err := json.NewDecoder(r.Body).Decode(&roll); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(roll); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if err := h.authorizeAttendance(r, classID); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	records, err := h.attendance.TakeRoll(r.Context(), classID, roll)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string              `json:"status"`
+		Count  int                 `json:"count"`
+		Data   []models.Attendance `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(records),
+		Data:   records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetClassRegisterHandler handles GET requests for the attendance register
+// of the class of the path between the from and to dates, as JSON or, with
+// format=csv, as a CSV file with the names of the students.
+func (h *Handler) GetClassRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	classID, _, err := classPathIDs(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	from, to, err := parseDateRange(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+	format := cmp.Or(r.URL.Query().Get("format"), "json")
+	if format != "json" && format != "csv" {
+		utils.WriteError(w, r, utils.BadRequestError(nil, fmt.Sprintf("Invalid format: %s, must be json or csv", format)))
+		return
+	}
+	if err := h.authorizeAttendance(r, classID); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	records, err := h.attendance.GetRegister(classID, from, to)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	if format == "csv" {
+		h.writeRegisterCSV(w, r, classID, records)
+		return
+	}
+
+	response := struct {
+		Status string              `json:"status"`
+		Count  int                 `json:"count"`
+		Data   []models.Attendance `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(records),
+		Data:   records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeRegisterCSV writes the register of a class as a CSV attachment, one
+// row per record. Students who have since left the class keep their rows.
+func (h *Handler) writeRegisterCSV(w http.ResponseWriter, r *http.Request, classID int, records []models.Attendance) {
+	students, err := h.classes.GetStudentsByClassID(classID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	names := make(map[int]models.Student, len(students))
+	for _, student := range students {
+		names[student.ID] = student
+	}
+	for _, record := range records {
+		if _, ok := names[record.StudentID]; ok {
+			continue
+		}
+		student, err := h.students.GetByID(record.StudentID)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		names[student.ID] = student
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="class-%d-attendance.csv"`, classID))
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"date", "period", "student_id", "first_name", "last_name", "status", "note"})
+	for _, record := range records {
+		student := names[record.StudentID]
+		writer.Write([]string{record.Date, strconv.Itoa(record.Period), strconv.Itoa(record.StudentID),
+			student.FirstName, student.LastName, record.Status, record.Note})
+	}
+	writer.Flush()
+}
+
+// writeSummaries writes attendance summaries as the response of a report.
+func writeSummaries(w http.ResponseWriter, summaries []models.AttendanceSummary) {
+	response := struct {
+		Status string                     `json:"status"`
+		Count  int                        `json:"count"`
+		Data   []models.AttendanceSummary `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(summaries),
+		Data:   summaries,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// StudentAttendanceReportHandler handles GET requests for the attendance
+// rate of every student, optionally of one class_id, between the from and
+// to dates.
+func (h *Handler) StudentAttendanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAttendanceFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+
+	summaries, err := h.attendance.SummarizeByStudent(filter)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	writeSummaries(w, summaries)
+}
+
+// ClassAttendanceReportHandler handles GET requests for the attendance rate
+// of every class, or of one class_id, between the from and to dates.
+func (h *Handler) ClassAttendanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAttendanceFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+
+	summaries, err := h.attendance.SummarizeByClass(filter)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	writeSummaries(w, summaries)
+}
+
+// AbsenteesReportHandler handles GET requests listing the students absent
+// at least min_absences times between the from and to dates, optionally of
+// one class_id, most absences first. Excused absences do not count.
+func (h *Handler) AbsenteesReportHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAttendanceFilter(r.URL.Query(), "min_absences")
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+	minStr := r.URL.Query().Get("min_absences")
+	if minStr == "" {
+		utils.WriteError(w, r, utils.BadRequestError(nil, "min_absences is required"))
+		return
+	}
+	minAbsences, err := strconv.Atoi(minStr)
+	if err != nil || minAbsences < 1 {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Invalid min_absences: %s, must be a positive number", minStr)))
+		return
+	}
+
+	summaries, err := h.attendance.SummarizeByStudent(filter)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	absentees := slices.DeleteFunc(summaries, func(summary models.AttendanceSummary) bool {
+		return summary.Absent < minAbsences
+	})
+	slices.SortStableFunc(absentees, func(a, b models.AttendanceSummary) int { return cmp.Compare(b.Absent, a.Absent) })
+	writeSummaries(w, absentees)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditParams are the query parameters of GET /audit besides the filters
+// of parseAuditFilter.
+var auditParams = []string{"page", "limit", "after"}
+
+// auditEntities are the entities recorded in the audit log.
+var auditEntities = []string{repository.Students.Name, repository.Teachers.Name, repository.Classes.Name,
+	repository.Subjects.Name, repository.Enrollments.Name,
+	repository.Assessments.Name, repository.Grades.Name, repository.Attendance.Name, repository.Executives.Name,
+	repository.AuditAccount, repository.AuditAPIKey}
+
+// auditActions are the actions recorded in the audit log.
+var auditActions = []string{models.AuditCreate, models.AuditUpdate, models.AuditDelete}
+
+// auditActorTypes are the kinds of actors recorded in the audit log.
+var auditActorTypes = []string{models.SubjectExecutive, models.SubjectTeacher, models.SubjectStudent, models.SubjectAPIKey, models.SubjectSystem}
+
+// parseAuditFilter reads the filters of GET /audit: entity, entity_id,
+// action, actor_type, actor_id, actor (the username) and the from and to
+// bounds of the time range, as RFC 3339 times or dates. A date as to
+// includes the whole day.
+func parseAuditFilter(values url.Values) (repository.AuditFilter, error) {
+	var filter repository.AuditFilter
+	var err error
+
+	for param := range values {
+		switch param {
+		case "entity", "entity_id", "action", "actor_type", "actor_id", "actor", "from", "to":
+		default:
+			if !slices.Contains(auditParams, param) {
+				return filter, fmt.Errorf("unknown filter field: %s", param)
+			}
+		}
+	}
+
+	oneOf := func(param string, allowed []string) (string, error) {
+		value := values.Get(param)
+		if value != "" && !slices.Contains(allowed, value) {
+			return "", fmt.Errorf("invalid %s: %s, must be one of: %s", param, value, strings.Join(allowed, ", "))
+		}
+		return value, nil
+	}
+	if filter.Entity, err = oneOf("entity", auditEntities); err != nil {
+		return filter, err
+	}
+	if filter.Action, err = oneOf("action", auditActions); err != nil {
+		return filter, err
+	}
+	if filter.ActorType, err = oneOf("actor_type", auditActorTypes); err != nil {
+		return filter, err
+	}
+	filter.ActorName = values.Get("actor")
+
+	positive := func(param string) (int, error) {
+		value := values.Get(param)
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid %s: %s", param, value)
+		}
+		return n, nil
+	}
+	if filter.EntityID, err = positive("entity_id"); err != nil {
+		return filter, err
+	}
+	if filter.ActorID, err = positive("actor_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.From, err = parseAuditTime(values.Get("from"), false); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseAuditTime(values.Get("to"), true); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+	return filter, nil
+}
+
+// parseAuditTime parses an RFC 3339 time or a UTC date. With endOfDay a
+// date stands for the start of the next day, so the range includes it.
+func parseAuditTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is not an RFC 3339 time or a YYYY-MM-DD date", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// AuditHandler lists the audit log, newest first, filtered by entity,
+// actor and time range.
+func (h *Handler) AuditHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+
+	page, err := utils.ParsePagination(r.URL.Query(), repository.AuditSort)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+
+	entries, total, err := h.audit.ListAudit(filter, page)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	entries, meta := utils.Paginate(entries, total, page, r.URL)
+
+	response := struct {
+		Status string              `json:"status"`
+		Count  int                 `json:"count"`
+		Meta   utils.PageMeta      `json:"meta"`
+		Data   []models.AuditEntry `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(entries),
+		Meta:   meta,
+		Data:   entries,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"strconv"
+)
+
+// classPathIDs returns the class ID of the path and, when the path has one,
+// the teacher ID.
+func classPathIDs(r *http.Request) (classID int, teacherID int, err error) {
+	idStr := r.PathValue("id")
+	if classID, err = strconv.Atoi(idStr); err != nil {
+		return 0, 0, utils.BadRequestError(err, fmt.Sprintf("Invalid Class ID: %s", idStr))
+	}
+	if teacherIDStr := r.PathValue("teacherid"); teacherIDStr != "" {
+		if teacherID, err = strconv.Atoi(teacherIDStr); err != nil {
+			return 0, 0, utils.BadRequestError(err, fmt.Sprintf("Invalid Teacher ID: %s", teacherIDStr))
+		}
+	}
+	return classID, teacherID, nil
+}
+
+// GetStudentsByClassIDHandler handles GET requests to list the students of a class
+func (h *Handler) GetStudentsByClassIDHandler(w http.ResponseWriter, r *http.Request) {
+	classID, _, err := classPathIDs(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	students, err := h.classes.GetStudentsByClassID(classID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string           `json:"status"`
+		Count  int              `json:"count"`
+		Data   []models.Student `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(students),
+		Data:   students,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetTeachersByClassIDHandler handles GET requests to list the teachers assigned to a class
+func (h *Handler) GetTeachersByClassIDHandler(w http.ResponseWriter, r *http.Request) {
+	classID, _, err := classPathIDs(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	teachers, err := h.classes.GetTeachersByClassID(classID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string           `json:"status"`
+		Count  int              `json:"count"`
+		Data   []models.Teacher `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(teachers),
+		Data:   teachers,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// AssignClassTeacherHandler handles PUT requests assigning the teacher of
+// the path to the class of the path. Assigning a teacher again succeeds
+// without changes.
+func (h *Handler) AssignClassTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	classID, teacherID, err := classPathIDs(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	if err := h.classes.AssignTeacher(r.Context(), classID, teacherID); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}{
+		Status:  "success",
+		Message: fmt.Sprintf("Teacher with ID %d assigned to class %d", teacherID, classID),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UnassignClassTeacherHandler handles DELETE requests removing the teacher
+// of the path from the class of the path.
+func (h *Handler) UnassignClassTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	classID, teacherID, err := classPathIDs(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	if err := h.classes.UnassignTeacher(r.Context(), classID, teacherID); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}{
+		Status:  "success",
+		Message: fmt.Sprintf("Teacher with ID %d removed from class %d", teacherID, classID),
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -1,306 +1,658 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
+	"os"
+	mw "school_management_api/internal/api/middlewares"
+	"school_management_api/internal/mailer"
 	"school_management_api/internal/models"
-	"school_management_api/internal/repository/sqlconnect"
+	"school_management_api/internal/repository"
 	"school_management_api/pkg/utils"
 	"strconv"
 	"time"
 )
 
-func GetExecutivesHandler(w http.ResponseWriter, r *http.Request) {
-	var executives []models.Executive
-	executives, err := sqlconnect.GetExecutivesInDb(executives, r)
+// UpdatePasswordHandler handles executive password update requests.
+// Executives change their own password after confirming the current one,
+// which is guessed no more freely than at login. Every token issued before the change stops working, so the caller gets a
+// new one to stay logged in.
+func (h *Handler) UpdatePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Invalid Executive ID: %s", idStr)))
 		return
 	}
 
-	response := struct {
-		Status string             `json:"status"`
-		Count  int                `json:"count"`
-		Data   []models.Executive `json:"data"`
-	}{
-		Status: "success",
-		Count:  len(executives),
-		Data:   executives,
+	if uid, ok := mw.UserIDFromContext(r.Context()); !ok || uid != id {
+		utils.WriteError(w, r, utils.ForbiddenError(nil, "executives can only change their own password"))
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
+	var req struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required,strongpassword,nefield=CurrentPassword"`
+		ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	userExec, err := h.executives.GetUserByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
 
-func CreateExecutivesHandler(w http.ResponseWriter, r *http.Request) {
-	// Variable validations
-	var newExecutives []models.Executive
-	var rawExecutives []map[string]any
+	if !checkCurrentPassword(w, r, h.logins, userExec.Username, userExec.Password, req.CurrentPassword) {
+		return
+	}
 
-	body, err := io.ReadAll(r.Body)
+	passwordHash, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
-	defer r.Body.Close()
 
-	err = json.Unmarshal(body, &rawExecutives)
+	if err := h.executives.UpdatePassword(r.Context(), id, passwordHash, time.Now()); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	tokens, err := h.startSession(w, userExec)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	// Validate the fields in the incoming request.
-	validFields := GetFieldNames(models.Executive{})
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Message string `json:"message"`
+		tokenPair
+	}{
+		Message:   "Password updated successfully",
+		tokenPair: tokens,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkCurrentPassword verifies the current password confirming a password
+// change. A wrong password counts as a failed login of the username, and
+// while the username or client IP is locked out no password is checked. It
+// reports whether the change may go on, and has answered the request if not.
+func checkCurrentPassword(w http.ResponseWriter, r *http.Request, guard *LoginGuard, username, passwordHash, password string) bool {
+	ip := mw.ClientIP(r)
+	now := time.Now()
+	wait, err := guard.Check(username, ip, now)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return false
+	}
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		utils.WriteError(w, r, utils.TooManyRequestsError(nil, "too many failed login attempts, try again later"))
+		return false
+	}
 
-	// Validate each executive object in the incoming request
-	for _, executive := range rawExecutives {
-		for key := range executive {
-			if _, ok := validFields[key]; !ok {
-				http.Error(w, fmt.Sprintf("Unacceptable field: %s, found in request.", key), http.StatusBadRequest)
-				return
-			}
+	if err := utils.VerifyPassword(passwordHash, password); err != nil {
+		if err := guard.Failed(username, ip, now); err != nil {
+			utils.WriteError(w, r, err)
+			return false
 		}
+		utils.WriteError(w, r, utils.UnauthorizedError(nil, "current password is incorrect"))
+		return false
+	}
+
+	if err := guard.Succeeded(username); err != nil {
+		utils.WriteError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// tokenPair is the access token and refresh token sent to a client.
+type tokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// refreshCookieName is the cookie holding the refresh token. It is only sent
+// to the executive routes, which include refresh and logout.
+const refreshCookieName = "RefreshToken"
+
+// newRefreshToken generates a refresh token and the record stored in its place.
+func newRefreshToken(now time.Time) (string, models.RefreshToken, error) {
+	ttl, err := utils.RefreshTokenTTL()
+	if err != nil {
+		return "", models.RefreshToken{}, err
+	}
+	token, tokenHash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return "", models.RefreshToken{}, err
 	}
+	return token, models.RefreshToken{TokenHash: tokenHash, CreatedAt: now, ExpiresAt: now.Add(ttl)}, nil
+}
 
-	// Decode the request body into a slice of Executive structs
-	err = json.Unmarshal(body, &newExecutives)
+// issueTokens signs an access token for the executive and sends it along
+// with the refresh token as HTTP only cookies, plus the CSRF token that
+// cookie requests must echo.
+func issueTokens(w http.ResponseWriter, userExec models.Executive, refreshToken string, refreshExpires time.Time) (tokenPair, error) {
+	token, err := utils.SignToken(userExec.ID, userExec.Username, userExec.Role)
+	if err != nil {
+		return tokenPair{}, utils.ErrorHandler(err, "failed to generate token")
+	}
+	ttl, err := utils.AccessTokenTTL()
 	if err != nil {
-		log.Println(err)
-		http.Error(w, "Invalid Request Body", http.StatusBadRequest)
+		return tokenPair{}, err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "Bearer", //"exec_auth_token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		Expires:  time.Now().Add(ttl),
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    refreshToken,
+		Path:     "/executives",
+		HttpOnly: true,
+		Secure:   true,
+		Expires:  refreshExpires,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	// Scripts read the CSRF token and echo it in a header, so it is not HTTP only
+	csrfToken, err := utils.GenerateCSRFToken()
+	if err != nil {
+		return tokenPair{}, err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     mw.CSRFCookieName,
+		Value:    csrfToken,
+		Path:     "/",
+		Secure:   true,
+		Expires:  refreshExpires,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return tokenPair{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// startSession stores the first refresh token of a new login and issues
+// the tokens for it.
+func (h *Handler) startSession(w http.ResponseWriter, userExec models.Executive) (tokenPair, error) {
+	family, err := utils.NewTokenFamily()
+	if err != nil {
+		return tokenPair{}, err
+	}
+	token, record, err := newRefreshToken(time.Now())
+	if err != nil {
+		return tokenPair{}, err
+	}
+	record.FamilyID = family
+	record.ExecutiveID = userExec.ID
+
+	if err := h.tokens.CreateRefreshToken(record); err != nil {
+		return tokenPair{}, err
+	}
+	return issueTokens(w, userExec, token, record.ExpiresAt)
+}
+
+// clearTokenCookies removes the token cookies by setting their expiration to a past time.
+func clearTokenCookies(w http.ResponseWriter) {
+	cookies := []struct {
+		name, path string
+		httpOnly   bool
+	}{
+		{"Bearer", "/", true},
+		{refreshCookieName, "/executives", true},
+		{mw.CSRFCookieName, "/", false},
+	}
+	for _, cookie := range cookies {
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookie.name,
+			Value:    "",
+			Path:     cookie.path,
+			HttpOnly: cookie.httpOnly,
+			Secure:   true,
+			Expires:  time.Unix(0, 0),
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
+
+// refreshTokenFromRequest returns the refresh token from its cookie or,
+// for clients that do not keep cookies, the refresh_token field of the
+// body, and whether it came from the cookie.
+func refreshTokenFromRequest(r *http.Request) (string, bool) {
+	if cookie, err := r.Cookie(refreshCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+	return req.RefreshToken, false
+}
+
+// LoginHandler handles executive login requests. Unknown usernames, wrong
+// passwords and inactive accounts all get the same answer, and repeated
+// failures lock the username and the client IP out for a while. Executives
+// using two-factor authentication get a challenge token instead of a session.
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.Executive
+
+	// data validation
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if req.Username == "" || req.Password == "" {
+		utils.WriteError(w, r, utils.ValidationError(nil, "Username and password are required"))
 		return
 	}
 
-	// Validate the newExecutives fields
-	for _, executive := range newExecutives {
-		err = CheckBlankFields(executive)
-		if err != nil {
-			log.Println(err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+	// refuse locked usernames and IPs before checking any password
+	ip := mw.ClientIP(r)
+	now := time.Now()
+	wait, err := h.logins.Check(req.Username, ip, now)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		utils.WriteError(w, r, utils.TooManyRequestsError(nil, "too many failed login attempts, try again later"))
+		return
+	}
+
+	loginFailed := func() {
+		if err := h.logins.Failed(req.Username, ip, now); err != nil {
+			utils.WriteError(w, r, err)
 			return
 		}
+		utils.WriteError(w, r, utils.UnauthorizedError(nil, "invalid username or password"))
 	}
 
-	addedExecutives, err := sqlconnect.CreateExecutives(newExecutives)
+	// get user by username
+	userExec, err := h.executives.GetUserByUsername(req.Username)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		var appErr *utils.AppError
+		if !errors.As(err, &appErr) || appErr.Code != utils.CodeNotFound {
+			utils.WriteError(w, r, err)
+			return
+		}
+		// An unknown username costs as much time as a wrong password
+		utils.VerifyDummyPassword(req.Password)
+		loginFailed()
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-
-	// Response structure with status, count, and data
-	response := struct {
-		Status string             `json:"status"`
-		Count  int                `json:"count"`
-		Data   []models.Executive `json:"data"`
-	}{
-		Status: "success",
-		Count:  len(addedExecutives),
-		Data:   addedExecutives,
+	// verify password, and only then whether the user is active
+	if err := utils.VerifyPassword(userExec.Password, req.Password); err != nil || userExec.InactiveStatus {
+		loginFailed()
+		return
 	}
 
-	json.NewEncoder(w).Encode(response)
-}
+	h.upgradePasswordHash(loginContext(r, userExec), userExec, req.Password)
 
-func PatchExecutivesHandler(w http.ResponseWriter, r *http.Request) {
-	var updatedFields []map[string]interface{}
-	err := json.NewDecoder(r.Body).Decode(&updatedFields)
+	// With two-factor authentication the password only earns a challenge,
+	// the login is complete at POST /executives/login/2fa
+	required, err := h.twoFactorRequired(userExec.Role)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to decode request body: %v", err), http.StatusBadRequest)
+		utils.WriteError(w, r, err)
+		return
+	}
+	if userExec.TOTPEnabled || required {
+		sendChallenge(w, r, userExec, !userExec.TOTPEnabled)
 		return
 	}
 
-	executivesFromDB, err := sqlconnect.PatchExecutivesInDb(updatedFields)
+	if err := h.logins.Succeeded(req.Username); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	// generate the access and refresh tokens, sent as response and as cookies
+	tokens, err := h.startSession(w, userExec)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
+	// Return success response
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(executivesFromDB)
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// loginContext returns the context of a login request with the executive
+// logging in as the actor of its writes, since the request is not
+// authenticated yet.
+func loginContext(r *http.Request, userExec models.Executive) context.Context {
+	actor := repository.Actor{Type: models.SubjectExecutive, ID: userExec.ID, Name: userExec.Username}
+	return repository.WithActor(r.Context(), actor)
+}
+
+// upgradePasswordHash rehashes the password of an executive who just logged
+// in when the stored hash is legacy or its parameters are outdated. A
+// failure is only logged, the login goes on with the old hash.
+func (h *Handler) upgradePasswordHash(ctx context.Context, userExec models.Executive, password string) {
+	newHash, ok := rehashPassword(userExec.Password, password)
+	if !ok {
+		return
+	}
+	if err := h.executives.ReplacePasswordHash(ctx, userExec.ID, userExec.Password, newHash); err != nil {
+		utils.ErrorHandler(err, "failed to upgrade password hash")
+	}
 }
 
-func GetOneExecutiveHandler(w http.ResponseWriter, r *http.Request) {
-	// Handle Path parameters for specific executive
-	executiveIDStr := r.PathValue("id")
-	id, err := strconv.Atoi(executiveIDStr)
+// rehashPassword returns a new hash of password when storedHash, which it
+// was just verified against, is legacy or its parameters are outdated.
+func rehashPassword(storedHash string, password string) (string, bool) {
+	outdated, err := utils.PasswordNeedsRehash(storedHash)
+	if err != nil || !outdated {
+		return "", false
+	}
+
+	newHash, err := utils.HashPassword(password)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid Executive ID: %s", executiveIDStr), http.StatusBadRequest)
+		return "", false
+	}
+	return newHash, true
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and a new
+// refresh token. Each refresh token works once: presenting it again revokes
+// every token of its login, since either the client or a thief is replaying it.
+func (h *Handler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	presented, fromCookie := refreshTokenFromRequest(r)
+	if presented == "" {
+		utils.WriteError(w, r, utils.UnauthorizedError(nil, "Refresh token missing"))
 		return
 	}
+	if fromCookie {
+		if err := mw.CheckCSRF(r); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+	}
 
-	executive, err := sqlconnect.GetExecutiveByID(id)
+	now := time.Now()
+	token, next, err := newRefreshToken(now)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(executive)
-}
+	rotated, err := h.tokens.RotateRefreshToken(utils.HashRefreshToken(presented), next, now)
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			err = utils.UnauthorizedError(nil, "refresh token is invalid or has expired")
+		}
+		utils.WriteError(w, r, err)
+		return
+	}
 
-func PatchOneExecutiveHandler(w http.ResponseWriter, r *http.Request) {
-	/// Get the executive id from the path
-	idStr := r.PathValue("id")
-	id, err := strconv.Atoi(idStr)
+	userExec, err := h.executives.GetUserByID(rotated.ExecutiveID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid Executive ID: %s", idStr), http.StatusBadRequest)
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			err = utils.UnauthorizedError(nil, "refresh token is invalid or has expired")
+		}
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	// Decode fields to update from request body
-	var updatedFields map[string]interface{}
-	err = json.NewDecoder(r.Body).Decode(&updatedFields)
+	// End the login when the account was deactivated or its password changed since
+	if userExec.InactiveStatus {
+		h.endSession(w, r, next.TokenHash, userExec.ID, now, utils.ForbiddenError(nil, "executive account is inactive"))
+		return
+	}
+	changed, err := utils.PasswordChangedAfter(userExec.PasswordChangedAt, rotated.CreatedAt)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to decode request body: %v", err), http.StatusBadRequest)
+		utils.WriteError(w, r, err)
+		return
+	}
+	if changed {
+		h.endSession(w, r, next.TokenHash, userExec.ID, now,
+			utils.UnauthorizedError(nil, "Refresh token was issued before the latest password change, please log in again"))
 		return
 	}
 
-	executiveToUpdate, err := sqlconnect.PatchExecutiveByID(id, updatedFields)
+	tokens, err := issueTokens(w, userExec, token, next.ExpiresAt)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	// Return the updated executive
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(executiveToUpdate)
+	json.NewEncoder(w).Encode(tokens)
 }
 
-func DeleteOneExecutiveHandler(w http.ResponseWriter, r *http.Request) {
-	// Get the executives Id from the path
+// endSession revokes the refresh token family of a login that may not
+// continue and responds with reason.
+func (h *Handler) endSession(w http.ResponseWriter, r *http.Request, tokenHash string, execID int, now time.Time, reason error) {
+	if err := h.tokens.RevokeRefreshToken(tokenHash, execID, now); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	clearTokenCookies(w)
+	utils.WriteError(w, r, reason)
+}
+
+// LogoutHandler ends the login of the caller. The refresh token family is
+// revoked and the access token is deny-listed until it expires, so neither
+// keeps working if it was copied. A refresh token of another executive is
+// rejected, so logging out cannot end someone else's login.
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	if presented, _ := refreshTokenFromRequest(r); presented != "" {
+		callerID, ok := mw.UserIDFromContext(r.Context())
+		if !ok {
+			utils.WriteError(w, r, utils.ForbiddenError(nil, "refresh token belongs to another executive"))
+			return
+		}
+		if err := h.tokens.RevokeRefreshToken(utils.HashRefreshToken(presented), callerID, now); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+	}
+
+	if jti, expiresAt, ok := mw.TokenIDFromContext(r.Context()); ok {
+		if err := h.tokens.RevokeAccessToken(jti, expiresAt); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+	}
+
+	clearTokenCookies(w)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message":"Logged out successfully"}`))
+}
+
+// UnlockHandler lifts the login lockout of an executive and forgets their
+// failed attempts. The policy leaves it to admins.
+func (h *Handler) UnlockHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
-
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid Executive ID: %s", idStr), http.StatusBadRequest)
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Invalid Executive ID: %s", idStr)))
 		return
 	}
 
-	// Connect to database
-	err = sqlconnect.DeleteExecutiveByID(id)
+	userExec, err := h.executives.GetUserByID(id)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	// ----- Alternate approach -----
-	// w.WriteHeader(http.StatusNoContent)
+	if err := h.logins.Unlock(userExec.Username); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
 
-	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	response := struct {
-		Status  string `json:"status"`
 		Message string `json:"message"`
 	}{
-		Status:  "success",
-		Message: fmt.Sprintf("Executive with ID %d deleted successfully", id),
+		Message: fmt.Sprintf("Executive with ID %d unlocked successfully", id),
 	}
-
 	json.NewEncoder(w).Encode(response)
 }
 
-// UpdatePasswordHandler handles executive password update requests.
-func UpdatePasswordHandler(w http.ResponseWriter, r *http.Request) {
-	// TODO:
-}
-
-// LoginHandler handles executive login requests.
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
-	var req models.Executive
+// forgotPasswordResponse is sent whether or not the email belongs to an
+// account, so the endpoint cannot be used to discover executives.
+const forgotPasswordResponse = "If an account with that email exists, a password reset link has been sent"
 
-	// data validation
+// ForgotPasswordHandler handles requests for a password reset link.
+// A random token is mailed to the executive and only its hash is stored.
+func (h *Handler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email" validate:"required,email"`
+	}
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to decode request body: %v", err), http.StatusBadRequest)
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
 		return
 	}
 	defer r.Body.Close()
 
-	if req.Username == "" || req.Password == "" {
-		http.Error(w, "Username and password are required", http.StatusBadRequest)
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	// get user by username
-	userExec, err := sqlconnect.GetUserByUsername(req.Username)
+	// Failures are only logged, the answer must not depend on the account
+	if err := h.sendResetLink(r.Context(), req.Email); err != nil {
+		utils.ErrorHandler(err, "failed to send password reset link")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Message string `json:"message"`
+	}{
+		Message: forgotPasswordResponse,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// sendResetLink stores a new reset token for the active executive with the
+// given email and mails the link. Unknown emails are silently ignored.
+func (h *Handler) sendResetLink(ctx context.Context, email string) error {
+	spec := utils.QuerySpec{Filters: []utils.Filter{utils.EqFilter("email", email)}}
+	executives, _, err := h.executives.List(spec, utils.Pagination{Page: 1, Limit: 1})
 	if err != nil {
-		http.Error(w, "failed to get executive user", http.StatusInternalServerError)
-		return
+		return err
+	}
+	if len(executives) == 0 || executives[0].InactiveStatus {
+		return nil
 	}
+	executive := executives[0]
 
-	// is user active
-	if userExec.InactiveStatus {
-		http.Error(w, "executive account is inactive", http.StatusForbidden)
+	ttl, err := utils.ResetTokenTTL()
+	if err != nil {
+		return err
+	}
+	token, tokenHash, err := utils.GenerateResetToken()
+	if err != nil {
+		return err
+	}
+	if err := h.executives.SetPasswordResetToken(ctx, executive.ID, tokenHash, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	msg := mailer.Message{
+		To:      executive.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to reset your password. It expires in %s.\n\n%s%s\n\n"+
+			"If you did not ask for a password reset, you can ignore this email.\n",
+			executive.FirstName, ttl, resetPasswordURL(), token),
+	}
+
+	// Send in the background so the response time does not reveal the account
+	go func() {
+		if err := h.mailer.Send(msg); err != nil {
+			utils.ErrorHandler(err, "failed to send password reset email")
+		}
+	}()
+	return nil
+}
+
+// resetPasswordURL returns the base of the reset links, to which the token is
+// appended, set with RESET_PASSWORD_URL.
+func resetPasswordURL() string {
+	if url := os.Getenv("RESET_PASSWORD_URL"); url != "" {
+		return url
+	}
+	return "https://localhost" + os.Getenv("API_PORT") + "/executives/resetpassword/reset/"
+}
+
+// ResetPasswordHandler sets a new password for the executive holding the
+// reset code from the path. Each code works once and only until it expires.
+func (h *Handler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	resetCode := r.PathValue("resetcode")
+
+	var req struct {
+		NewPassword     string `json:"new_password" validate:"required,strongpassword"`
+		ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
 		return
 	}
+	defer r.Body.Close()
 
-	// verify password
-	if err := utils.VerifyPassword(userExec, req.Password); err != nil {
-		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	// generate token
-	token, err := utils.SignToken(userExec.ID, userExec.Username, userExec.Role)
+	passwordHash, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
-		http.Error(w, "failed to generate token", http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	// send token as response or as a cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "Bearer", //"exec_auth_token",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		Expires:  time.Now().Add(24 * time.Hour),
-		SameSite: http.SameSiteStrictMode,
-	})
+	err = h.executives.ResetPassword(r.Context(), utils.HashResetToken(resetCode), passwordHash, time.Now())
+	if err != nil {
+		// An unknown, used or expired code is a bad request, not a missing resource
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			err = utils.BadRequestError(nil, appErr.Message)
+		}
+		utils.WriteError(w, r, err)
+		return
+	}
 
-	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	response := struct {
-		Token string `json:"token"`
+		Message string `json:"message"`
 	}{
-		Token: token,
+		Message: "Password reset successfully",
 	}
 	json.NewEncoder(w).Encode(response)
 }
-
-func LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	// remove the jwt cookie by setting its expiration to a past time
-	http.SetCookie(w, &http.Cookie{
-		Name:     "Bearer", //"exec_auth_token",
-		Value:    "",
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-		Expires:  time.Unix(0, 0),
-		SameSite: http.SameSiteStrictMode,
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"message":"Logged out successfully"}`))
-}
-
-func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
-	// Implementation for forgot password functionality
-}
-
-func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
-	// Implementation for resetting password functionality
-}
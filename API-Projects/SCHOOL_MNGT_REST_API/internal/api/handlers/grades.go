@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	mw "school_management_api/internal/api/middlewares"
+	"school_management_api/internal/grading"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"strconv"
+	"time"
+)
+
+// gradeRequest is one score of a bulk grade entry. Score is a pointer so a
+// missing score is not taken for 0.
+type gradeRequest struct {
+	StudentID int      `json:"student_id" validate:"required,min=1"`
+	Score     *float64 `json:"score" validate:"required,min=0"`
+}
+
+// pathID parses the {id} of the path as the ID of the named entity.
+func pathID(r *http.Request, name string) (int, error) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, utils.BadRequestError(err, fmt.Sprintf("Invalid %s ID: %s", name, idStr))
+	}
+	return id, nil
+}
+
+// termParams returns the academic_year and term query parameters. An empty
+// academic year or a zero term means the parameter was not given.
+func termParams(r *http.Request) (string, int, error) {
+	academicYear := r.URL.Query().Get("academic_year")
+	if academicYear != "" && !utils.IsAcademicYear(academicYear) {
+		return "", 0, utils.BadRequestError(nil, fmt.Sprintf("Invalid academic_year: %s, e.g. 2026-2027", academicYear))
+	}
+
+	var term int
+	if termStr := r.URL.Query().Get("term"); termStr != "" {
+		var err error
+		if term, err = strconv.Atoi(termStr); err != nil || term < 1 || term > 3 {
+			return "", 0, utils.BadRequestError(err, fmt.Sprintf("Invalid term: %s, must be 1, 2 or 3", termStr))
+		}
+	}
+	return academicYear, term, nil
+}
+
+// authorizeGrading returns the IDs of the students the caller may grade in
+// an assessment: the students of its class enrolled in its subject in its
+// term and, when the caller is a teacher, enrolled with that teacher. It
+// returns a Forbidden error when a teacher has no such student, i.e. does
+// not teach the subject to the class of the assessment.
+func (h *Handler) authorizeGrading(r *http.Request, assessment models.Assessment) (map[int]bool, error) {
+	filters := []utils.Filter{
+		utils.EqFilter("subject_id", assessment.SubjectID),
+		utils.EqFilter("academic_year", assessment.AcademicYear),
+		utils.EqFilter("term", assessment.Term),
+	}
+	subjectType, teacherID, ok := mw.SubjectFromContext(r.Context())
+	isTeacher := ok && subjectType == models.SubjectTeacher
+	if isTeacher {
+		filters = append(filters, utils.EqFilter("teacher_id", teacherID))
+	}
+
+	enrollments, _, err := h.enrollments.List(utils.QuerySpec{Filters: filters}, utils.Pagination{Page: 1})
+	if err != nil {
+		return nil, err
+	}
+	students, err := h.classes.GetStudentsByClassID(assessment.ClassID)
+	if err != nil {
+		return nil, err
+	}
+
+	inClass := make(map[int]bool, len(students))
+	for _, student := range students {
+		inClass[student.ID] = true
+	}
+	gradable := make(map[int]bool)
+	for _, enrollment := range enrollments {
+		if inClass[enrollment.StudentID] {
+			gradable[enrollment.StudentID] = true
+		}
+	}
+
+	if isTeacher && len(gradable) == 0 {
+		return nil, utils.ForbiddenError(nil, fmt.Sprintf("you do not teach subject %d to class %d in term %d of %s",
+			assessment.SubjectID, assessment.ClassID, assessment.Term, assessment.AcademicYear))
+	}
+	return gradable, nil
+}
+
+// GetAssessmentGradesHandler handles GET requests to list the grades of an assessment
+func (h *Handler) GetAssessmentGradesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "Assessment")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	assessment, err := h.assessments.GetByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if _, err := h.authorizeGrading(r, assessment); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	grades, err := h.assessments.GetGradesByAssessmentID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string         `json:"status"`
+		Count  int            `json:"count"`
+		Data   []models.Grade `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(grades),
+		Data:   grades,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SetAssessmentGradesHandler handles PUT requests entering the scores of
+// students of the class in an assessment. Only students enrolled in the
+// subject, with the teacher when the caller is one, may be graded. Scores
+// already entered for a student are replaced; the scores of students not
+// listed are kept.
+func (h *Handler) SetAssessmentGradesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "Assessment")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var requests []gradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if len(requests) == 0 {
+		utils.WriteError(w, r, utils.BadRequestError(nil, "No grades provided"))
+		return
+	}
+	if err := utils.ValidateItems(requests); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	assessment, err := h.assessments.GetByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	gradable, err := h.authorizeGrading(r, assessment)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	enrolledWith := ""
+	if subjectType, _, ok := mw.SubjectFromContext(r.Context()); ok && subjectType == models.SubjectTeacher {
+		enrolledWith = " with you"
+	}
+	var fieldErrors []utils.FieldError
+	for i, request := range requests {
+		if !gradable[request.StudentID] {
+			fieldErrors = append(fieldErrors, utils.FieldError{Index: &i, Field: "student_id", Rule: "enrolled",
+				Message: fmt.Sprintf("student %d is not enrolled%s in subject %d of class %d in term %d of %s", request.StudentID,
+					enrolledWith, assessment.SubjectID, assessment.ClassID, assessment.Term, assessment.AcademicYear)})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		utils.WriteError(w, r, utils.ValidationError(nil, "validation failed").WithDetails(fieldErrors))
+		return
+	}
+
+	grades := make([]models.Grade, len(requests))
+	for i, request := range requests {
+		grades[i] = models.Grade{StudentID: request.StudentID, Score: *request.Score}
+	}
+	stored, err := h.assessments.SetGrades(r.Context(), id, grades)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string         `json:"status"`
+		Count  int            `json:"count"`
+		Data   []models.Grade `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(stored),
+		Data:   stored,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetStudentGradesHandler handles GET requests to list the grades of a
+// student, optionally of one academic_year and term
+func (h *Handler) GetStudentGradesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "Student")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	academicYear, term, err := termParams(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	grades, err := h.assessments.GetGradesByStudentID(id, academicYear, term)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string                `json:"status"`
+		Count  int                   `json:"count"`
+		Data   []models.StudentGrade `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(grades),
+		Data:   grades,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetReportCardHandler handles GET requests for the report card of a
+// student in a term of their current class. The term is required; the
+// academic year defaults to the current one.
+func (h *Handler) GetReportCardHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "Student")
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	academicYear, term, err := termParams(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if term == 0 {
+		utils.WriteError(w, r, utils.BadRequestError(nil, "term is required"))
+		return
+	}
+	if academicYear == "" {
+		academicYear = utils.CurrentAcademicYear(time.Now())
+	}
+
+	student, err := h.students.GetByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	assessments, grades, err := h.assessments.GetClassGrades(student.ClassID, academicYear, term)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	card := grading.Compute(h.gradingScale, student.ID, assessments, grades)
+	card.ClassID, card.AcademicYear, card.Term = student.ClassID, academicYear, term
+	for i, result := range card.Subjects {
+		subject, err := h.subjects.GetByID(result.SubjectID)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		card.Subjects[i].SubjectName = subject.Name
+	}
+
+	response := struct {
+		Status string             `json:"status"`
+		Data   grading.ReportCard `json:"data"`
+	}{
+		Status: "success",
+		Data:   card,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
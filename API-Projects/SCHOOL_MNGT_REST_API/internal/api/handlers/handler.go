@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"school_management_api/internal/grading"
+	"school_management_api/internal/mailer"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+)
+
+// Handler groups the HTTP handlers together with the repositories they use.
+// The CRUD endpoints of each entity are served by its Resource.
+type Handler struct {
+	Students    *Resource[models.Student]
+	Teachers    *Resource[models.Teacher]
+	Classes     *Resource[models.Class]
+	Subjects    *Resource[models.Subject]
+	Enrollments *Resource[models.Enrollment]
+	Assessments *Resource[models.Assessment]
+	Attendance  *Resource[models.Attendance]
+	Executives  *Resource[models.Executive]
+
+	students      repository.StudentRepository
+	teachers      repository.TeacherRepository
+	classes       repository.ClassRepository
+	subjects      repository.SubjectRepository
+	enrollments   repository.EnrollmentRepository
+	assessments   repository.AssessmentRepository
+	attendance    repository.AttendanceRepository
+	executives    repository.ExecutiveRepository
+	accounts      repository.AccountRepository
+	tokens        repository.TokenRepository
+	twoFactor     repository.TwoFactorRepository
+	apiKeys       repository.APIKeyRepository
+	audit         repository.AuditRepository
+	logins        *LoginGuard
+	accountLogins *LoginGuard
+	mailer        mailer.Mailer
+	gradingScale  grading.Scale
+}
+
+// Repositories are the data stores a Handler serves requests from.
+type Repositories struct {
+	Students    repository.StudentRepository
+	Teachers    repository.TeacherRepository
+	Classes     repository.ClassRepository
+	Subjects    repository.SubjectRepository
+	Enrollments repository.EnrollmentRepository
+	Assessments repository.AssessmentRepository
+	Attendance  repository.AttendanceRepository
+	Executives  repository.ExecutiveRepository
+	Accounts    repository.AccountRepository
+	Tokens      repository.TokenRepository
+	TwoFactor   repository.TwoFactorRepository
+	APIKeys     repository.APIKeyRepository
+	Audit       repository.AuditRepository
+}
+
+// NewHandler creates a Handler that serves requests using repos, guards
+// logins with logins, sends emails, e.g. password reset links, through mail
+// and grades report cards on scale.
+func NewHandler(repos Repositories, logins *LoginGuard, mail mailer.Mailer, scale grading.Scale) *Handler {
+	return &Handler{
+		Students:      NewResource(repository.Students, repos.Students),
+		Teachers:      NewResource(repository.Teachers, repos.Teachers),
+		Classes:       NewResource(repository.Classes, repos.Classes),
+		Subjects:      NewResource(repository.Subjects, repos.Subjects),
+		Enrollments:   NewResource(repository.Enrollments, repos.Enrollments),
+		Assessments:   NewResource(repository.Assessments, repos.Assessments),
+		Attendance:    NewResource(repository.Attendance, repos.Attendance),
+		Executives:    NewResource(repository.Executives, repos.Executives),
+		students:      repos.Students,
+		teachers:      repos.Teachers,
+		classes:       repos.Classes,
+		subjects:      repos.Subjects,
+		enrollments:   repos.Enrollments,
+		assessments:   repos.Assessments,
+		attendance:    repos.Attendance,
+		executives:    repos.Executives,
+		accounts:      repos.Accounts,
+		tokens:        repos.Tokens,
+		twoFactor:     repos.TwoFactor,
+		apiKeys:       repos.APIKeys,
+		audit:         repos.Audit,
+		logins:        logins,
+		accountLogins: logins.forAccounts(),
+		mailer:        mail,
+		gradingScale:  scale,
+	}
+}
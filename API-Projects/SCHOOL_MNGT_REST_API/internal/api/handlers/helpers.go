@@ -1,9 +1,7 @@
 package handlers
 
 import (
-	"errors"
 	"reflect"
-	"school_management_api/pkg/utils"
 	"strings"
 )
 
@@ -20,14 +18,3 @@ func GetFieldNames(model interface{}) map[string]struct{} {
 	}
 	return validFields
 }
-
-func CheckBlankFields(value interface{}) error {
-	val := reflect.ValueOf(value)
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		if field.Kind() == reflect.String && field.Len() == 0 {
-			return utils.ErrorHandler(errors.New("all fields are required"), "all fields are required")
-		}
-	}
-	return nil
-}
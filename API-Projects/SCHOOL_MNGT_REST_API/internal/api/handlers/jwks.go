@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"school_management_api/pkg/utils"
+)
+
+// JWKSHandler publishes the public keys that verify our tokens, so other
+// services can check them without sharing a secret.
+func (h *Handler) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := utils.JWKS()
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Keys change only on rotation, verifiers may cache them for a while
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	response := struct {
+		Keys []utils.JWK `json:"keys"`
+	}{
+		Keys: keys,
+	}
+	json.NewEncoder(w).Encode(response)
+}
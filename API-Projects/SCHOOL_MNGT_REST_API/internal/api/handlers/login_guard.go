@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"school_management_api/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoginLimits configures the lockout after failed logins. Once a username
+// or client IP reaches its number of failures, its logins are blocked for
+// Lockout, doubled with every further failure up to MaxLockout.
+type LoginLimits struct {
+	AccountAttempts int           // failures of one username before it is locked
+	IPAttempts      int           // failures from one client IP before it is locked
+	Lockout         time.Duration // first lockout
+	MaxLockout      time.Duration // longest lockout
+	ResetAfter      time.Duration // quiet time after which failures are forgotten
+}
+
+// DefaultLoginLimits are used for the settings not given in the environment.
+var DefaultLoginLimits = LoginLimits{
+	AccountAttempts: 5,
+	IPAttempts:      20,
+	Lockout:         time.Minute,
+	MaxLockout:      time.Hour,
+	ResetAfter:      24 * time.Hour,
+}
+
+// LoginLimitsFromEnv reads the login limits from the environment:
+//   - LOGIN_MAX_ATTEMPTS: failures of one username before it is locked (default 5)
+//   - LOGIN_MAX_ATTEMPTS_PER_IP: failures from one client IP before it is locked (default 20)
+//   - LOGIN_LOCKOUT: first lockout, e.g. "1m" (default 1m)
+//   - LOGIN_MAX_LOCKOUT: longest lockout, e.g. "1h" (default 1h)
+//   - LOGIN_ATTEMPTS_RESET_AFTER: quiet time after which failures are forgotten (default 24h)
+func LoginLimitsFromEnv() (LoginLimits, error) {
+	limits := DefaultLoginLimits
+
+	for key, value := range map[string]*int{
+		"LOGIN_MAX_ATTEMPTS":        &limits.AccountAttempts,
+		"LOGIN_MAX_ATTEMPTS_PER_IP": &limits.IPAttempts,
+	} {
+		if env := os.Getenv(key); env != "" {
+			n, err := strconv.Atoi(env)
+			if err != nil || n < 1 {
+				return limits, fmt.Errorf("invalid value for %s: %q", key, env)
+			}
+			*value = n
+		}
+	}
+
+	for key, value := range map[string]*time.Duration{
+		"LOGIN_LOCKOUT":              &limits.Lockout,
+		"LOGIN_MAX_LOCKOUT":          &limits.MaxLockout,
+		"LOGIN_ATTEMPTS_RESET_AFTER": &limits.ResetAfter,
+	} {
+		if env := os.Getenv(key); env != "" {
+			d, err := time.ParseDuration(env)
+			if err != nil || d <= 0 {
+				return limits, fmt.Errorf("invalid value for %s: %q", key, env)
+			}
+			*value = d
+		}
+	}
+	return limits, nil
+}
+
+// LoginGuard counts failed logins per username and per client IP and locks
+// them out with exponential backoff. Usernames are counted whether or not
+// they exist, so a lockout does not reveal which accounts do.
+type LoginGuard struct {
+	store  repository.LoginAttemptRepository
+	limits LoginLimits
+	// prefix keeps the usernames of executives and of accounts apart
+	prefix string
+}
+
+// NewLoginGuard creates a LoginGuard keeping its counters in store.
+func NewLoginGuard(store repository.LoginAttemptRepository, limits LoginLimits) *LoginGuard {
+	return &LoginGuard{store: store, limits: limits, prefix: "user:"}
+}
+
+// forAccounts returns a guard counting the usernames of teacher and student
+// accounts, which may equal those of executives. Client IPs share their
+// counters with g.
+func (g *LoginGuard) forAccounts() *LoginGuard {
+	return &LoginGuard{store: g.store, limits: g.limits, prefix: "account:"}
+}
+
+// accountKey and ipKey name the counters in the store. Usernames are
+// compared case-insensitively like the database collation.
+func (g *LoginGuard) accountKey(username string) string { return g.prefix + strings.ToLower(username) }
+func ipKey(ip string) string                            { return "ip:" + ip }
+
+// Check returns how long logins of the username or from the IP are still
+// locked, zero when neither is.
+func (g *LoginGuard) Check(username, ip string, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for _, key := range []string{g.accountKey(username), ipKey(ip)} {
+		attempts, err := g.store.GetLoginAttempts(key)
+		if err != nil {
+			return 0, err
+		}
+		wait = max(wait, attempts.LockedUntil.Sub(now))
+	}
+	return wait, nil
+}
+
+// Failed counts a failed login and locks the username or IP once it has
+// reached its limit.
+func (g *LoginGuard) Failed(username, ip string, now time.Time) error {
+	counters := []struct {
+		key   string
+		limit int
+	}{
+		{g.accountKey(username), g.limits.AccountAttempts},
+		{ipKey(ip), g.limits.IPAttempts},
+	}
+
+	for _, counter := range counters {
+		attempts, err := g.store.RecordLoginFailure(counter.key, now, now.Add(-g.limits.ResetAfter))
+		if err != nil {
+			return err
+		}
+		if attempts.Failures < counter.limit {
+			continue
+		}
+		if err := g.store.LockLogin(counter.key, now.Add(g.lockout(attempts.Failures-counter.limit))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockout returns the lockout after the given number of failures past the limit.
+func (g *LoginGuard) lockout(extraFailures int) time.Duration {
+	lockout := g.limits.Lockout
+	for range extraFailures {
+		lockout *= 2
+		if lockout >= g.limits.MaxLockout {
+			return g.limits.MaxLockout
+		}
+	}
+	return min(lockout, g.limits.MaxLockout)
+}
+
+// Succeeded forgets the failures of the username. The IP keeps its count,
+// otherwise logging in to one account would allow guessing others.
+func (g *LoginGuard) Succeeded(username string) error {
+	return g.store.ResetLoginAttempts(g.accountKey(username))
+}
+
+// Unlock lifts the lockout of a username and forgets its failures.
+func (g *LoginGuard) Unlock(username string) error {
+	return g.store.ResetLoginAttempts(g.accountKey(username))
+}
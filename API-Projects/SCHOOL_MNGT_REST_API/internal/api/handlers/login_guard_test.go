@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"school_management_api/internal/repository/memory"
+	"slices"
+	"testing"
+	"time"
+)
+
+// loginEvent is a login outcome fed to a LoginGuard, at an offset from the
+// start of a test.
+type loginEvent struct {
+	op       string // "fail", "succeed" or "unlock"
+	username string
+	ip       string
+	at       time.Duration
+}
+
+// failures returns n failed logins of username from ip at offset at.
+func failures(n int, username, ip string, at time.Duration) []loginEvent {
+	events := make([]loginEvent, n)
+	for i := range events {
+		events[i] = loginEvent{op: "fail", username: username, ip: ip, at: at}
+	}
+	return events
+}
+
+func TestLoginGuardLockout(t *testing.T) {
+	limits := LoginLimits{
+		AccountAttempts: 3,
+		IPAttempts:      5,
+		Lockout:         time.Minute,
+		MaxLockout:      4 * time.Minute,
+		ResetAfter:      time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		events   []loginEvent
+		username string
+		ip       string
+		at       time.Duration
+		want     time.Duration
+	}{
+		{
+			name:     "below the limit",
+			events:   failures(2, "alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.1",
+			want: 0,
+		},
+		{
+			name:     "locked at the limit",
+			events:   failures(3, "alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.1",
+			want: time.Minute,
+		},
+		{
+			name:     "lockout runs out",
+			events:   failures(3, "alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.1", at: 40 * time.Second,
+			want: 20 * time.Second,
+		},
+		{
+			name:     "unlocked after the lockout",
+			events:   failures(3, "alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.1", at: time.Minute,
+			want: 0,
+		},
+		{
+			name:     "lockout doubles with further failures",
+			events:   failures(4, "alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.2",
+			want: 2 * time.Minute,
+		},
+		{
+			name:     "lockout is capped",
+			events:   failures(7, "alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.2",
+			want: 4 * time.Minute,
+		},
+		{
+			name:     "username locked from every IP",
+			events:   failures(3, "alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.2",
+			want: time.Minute,
+		},
+		{
+			name:     "usernames ignore case",
+			events:   failures(3, "Alice", "10.0.0.1", 0),
+			username: "alice", ip: "10.0.0.2",
+			want: time.Minute,
+		},
+		{
+			name:     "other usernames open below the IP limit",
+			events:   failures(3, "alice", "10.0.0.1", 0),
+			username: "bob", ip: "10.0.0.1",
+			want: 0,
+		},
+		{
+			name: "IP locked at its limit",
+			events: slices.Concat(
+				failures(2, "alice", "10.0.0.1", 0),
+				failures(2, "bob", "10.0.0.1", 0),
+				failures(1, "carol", "10.0.0.1", 0),
+			),
+			username: "dave", ip: "10.0.0.1",
+			want: time.Minute,
+		},
+		{
+			name: "failures forgotten after the quiet time",
+			events: slices.Concat(
+				failures(2, "alice", "10.0.0.1", 0),
+				failures(1, "alice", "10.0.0.1", 2*time.Hour),
+			),
+			username: "alice", ip: "10.0.0.1", at: 2 * time.Hour,
+			want: 0,
+		},
+		{
+			name: "success forgets the failures of the username",
+			events: slices.Concat(
+				failures(2, "alice", "10.0.0.1", 0),
+				[]loginEvent{{op: "succeed", username: "alice"}},
+				failures(1, "alice", "10.0.0.1", 0),
+			),
+			username: "alice", ip: "10.0.0.2",
+			want: 0,
+		},
+		{
+			name: "success keeps the count of the IP",
+			events: slices.Concat(
+				failures(2, "alice", "10.0.0.1", 0),
+				[]loginEvent{{op: "succeed", username: "alice"}},
+				failures(2, "bob", "10.0.0.1", 0),
+				failures(1, "carol", "10.0.0.1", 0),
+			),
+			username: "alice", ip: "10.0.0.1",
+			want: time.Minute,
+		},
+		{
+			name: "unlock lifts the lockout",
+			events: slices.Concat(
+				failures(3, "alice", "10.0.0.1", 0),
+				[]loginEvent{{op: "unlock", username: "alice"}},
+			),
+			username: "alice", ip: "10.0.0.2",
+			want: 0,
+		},
+	}
+
+	start := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := NewLoginGuard(memory.NewStore().Logins, limits)
+			for _, event := range tt.events {
+				var err error
+				switch event.op {
+				case "fail":
+					err = guard.Failed(event.username, event.ip, start.Add(event.at))
+				case "succeed":
+					err = guard.Succeeded(event.username)
+				case "unlock":
+					err = guard.Unlock(event.username)
+				}
+				if err != nil {
+					t.Fatalf("%s %s: %v", event.op, event.username, err)
+				}
+			}
+
+			wait, err := guard.Check(tt.username, tt.ip, start.Add(tt.at))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wait != tt.want {
+				t.Errorf("Check(%q, %q) = %v, want %v", tt.username, tt.ip, wait, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginGuardForAccounts(t *testing.T) {
+	limits := LoginLimits{AccountAttempts: 2, IPAttempts: 3, Lockout: time.Minute, MaxLockout: time.Hour, ResetAfter: time.Hour}
+	executives := NewLoginGuard(memory.NewStore().Logins, limits)
+	accounts := executives.forAccounts()
+	now := time.Now()
+
+	for range 2 {
+		if err := accounts.Failed("alice", "10.0.0.1", now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The usernames are counted apart, the client IP together
+	tests := []struct {
+		name  string
+		guard *LoginGuard
+		ip    string
+		want  time.Duration
+	}{
+		{name: "account locked", guard: accounts, ip: "10.0.0.2", want: time.Minute},
+		{name: "executive of the same name open", guard: executives, ip: "10.0.0.2", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, err := tt.guard.Check("alice", tt.ip, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wait != tt.want {
+				t.Errorf("Check() = %v, want %v", wait, tt.want)
+			}
+		})
+	}
+
+	if err := executives.Failed("bob", "10.0.0.1", now); err != nil {
+		t.Fatal(err)
+	}
+	if wait, _ := executives.Check("carol", "10.0.0.1", now); wait != time.Minute {
+		t.Errorf("IP after failures of both kinds: Check() = %v, want %v", wait, time.Minute)
+	}
+}
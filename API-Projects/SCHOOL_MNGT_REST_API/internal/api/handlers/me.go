@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	mw "school_management_api/internal/api/middlewares"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// selfEditableFields are the fields teachers and students may change on
+// their own record with PATCH /me. Classes and subjects stay with the staff.
+var selfEditableFields = map[string][]string{
+	models.SubjectTeacher: {"first_name", "last_name", "email"},
+	models.SubjectStudent: {"email"},
+}
+
+// subjectFromRequest returns the subject type and ID of the caller.
+func subjectFromRequest(r *http.Request) (string, int, error) {
+	subjectType, id, ok := mw.SubjectFromContext(r.Context())
+	if !ok {
+		return "", 0, utils.UnauthorizedError(nil, "Invalid Login Token")
+	}
+	return subjectType, id, nil
+}
+
+// MeHandler returns the record of the caller: their executive, teacher or
+// student row.
+func (h *Handler) MeHandler(w http.ResponseWriter, r *http.Request) {
+	subjectType, id, err := subjectFromRequest(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var profile any
+	switch subjectType {
+	case models.SubjectTeacher:
+		profile, err = h.teachers.GetByID(id)
+	case models.SubjectStudent:
+		profile, err = h.students.GetByID(id)
+	default:
+		profile, err = h.executives.GetByID(id)
+	}
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		SubjectType string `json:"subject_type"`
+		Profile     any    `json:"profile"`
+	}{
+		SubjectType: subjectType,
+		Profile:     profile,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// MyStudentsHandler lists the students enrolled with the calling teacher.
+func (h *Handler) MyStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	subjectType, id, err := subjectFromRequest(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if subjectType != models.SubjectTeacher {
+		utils.WriteError(w, r, utils.ForbiddenError(nil, "only teachers have students"))
+		return
+	}
+
+	students, err := h.teachers.GetStudentsByTeacherID(strconv.Itoa(id))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	response := struct {
+		Status string           `json:"status"`
+		Count  int              `json:"count"`
+		Data   []models.Student `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(students),
+		Data:   students,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateMeHandler lets a teacher or student change the fields of their own
+// record listed in selfEditableFields.
+func (h *Handler) UpdateMeHandler(w http.ResponseWriter, r *http.Request) {
+	subjectType, id, err := subjectFromRequest(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	editable, ok := selfEditableFields[subjectType]
+	if !ok {
+		utils.WriteError(w, r, utils.ForbiddenError(nil, "executives are updated through /executives"))
+		return
+	}
+
+	var updatedFields map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&updatedFields); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	var fieldErrors []utils.FieldError
+	for key := range updatedFields {
+		if !slices.Contains(editable, key) {
+			fieldErrors = append(fieldErrors, utils.FieldError{
+				Field:   key,
+				Rule:    "readonly",
+				Message: fmt.Sprintf("%s cannot be changed, editable fields are: %s", key, strings.Join(editable, ", ")),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		utils.WriteError(w, r, utils.ValidationError(nil, "validation failed").WithDetails(fieldErrors))
+		return
+	}
+
+	var updated any
+	switch subjectType {
+	case models.SubjectTeacher:
+		if err = utils.ValidatePartial(models.Teacher{}, updatedFields); err == nil {
+			updated, err = h.teachers.Patch(r.Context(), id, updatedFields)
+		}
+	case models.SubjectStudent:
+		if err = utils.ValidatePartial(models.Student{}, updatedFields); err == nil {
+			updated, err = h.students.Patch(r.Context(), id, updatedFields)
+		}
+	}
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// UpdateMyPasswordHandler lets a teacher or student change their password
+// after confirming the current one, counting wrong ones like failed logins. Tokens issued before stop working, so
+// the caller gets a new one.
+func (h *Handler) UpdateMyPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	subjectType, id, err := subjectFromRequest(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required,strongpassword,nefield=CurrentPassword"`
+		ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	account, err := h.accounts.GetAccountBySubject(subjectType, id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if !checkCurrentPassword(w, r, h.accountLogins, account.Username, account.Password, req.CurrentPassword) {
+		return
+	}
+
+	passwordHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if err := h.accounts.UpdateAccountPassword(r.Context(), account.ID, passwordHash, time.Now()); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	token, err := utils.SignAccountToken(account.SubjectType, account.SubjectID, account.Username)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Message string `json:"message"`
+		Token   string `json:"token"`
+	}{
+		Message: "Password updated successfully",
+		Token:   token,
+	}
+	json.NewEncoder(w).Encode(response)
+}
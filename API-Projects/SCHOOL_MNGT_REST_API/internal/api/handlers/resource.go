@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strconv"
+)
+
+// Operation is one of the CRUD endpoints a Resource can register.
+type Operation int
+
+const (
+	OpList       Operation = iota // GET /path
+	OpCreate                      // POST /path
+	OpPatchMany                   // PATCH /path
+	OpDeleteMany                  // DELETE /path
+	OpGet                         // GET /path/{id}
+	OpUpdate                      // PUT /path/{id}
+	OpPatch                       // PATCH /path/{id}
+	OpDelete                      // DELETE /path/{id}
+)
+
+// AllOperations registers every CRUD endpoint.
+var AllOperations = []Operation{OpList, OpCreate, OpPatchMany, OpDeleteMany, OpGet, OpUpdate, OpPatch, OpDelete}
+
+// Resource serves the CRUD endpoints of one entity. Request fields and
+// validation come from the json and validate tags of T; storage is left to
+// the repository.
+type Resource[T any] struct {
+	entity repository.Entity[T]
+	repo   repository.Resource[T]
+}
+
+// NewResource creates the handlers of an entity backed by repo.
+func NewResource[T any](entity repository.Entity[T], repo repository.Resource[T]) *Resource[T] {
+	return &Resource[T]{entity: entity, repo: repo}
+}
+
+// Register adds the given operations to mux under path, e.g. "/students".
+func (res *Resource[T]) Register(mux *http.ServeMux, path string, ops ...Operation) {
+	routes := map[Operation]struct {
+		pattern string
+		handler http.HandlerFunc
+	}{
+		OpList:       {"GET " + path, res.List},
+		OpCreate:     {"POST " + path, res.Create},
+		OpPatchMany:  {"PATCH " + path, res.PatchMany},
+		OpDeleteMany: {"DELETE " + path, res.DeleteMany},
+		OpGet:        {"GET " + path + "/{id}", res.Get},
+		OpUpdate:     {"PUT " + path + "/{id}", res.Update},
+		OpPatch:      {"PATCH " + path + "/{id}", res.Patch},
+		OpDelete:     {"DELETE " + path + "/{id}", res.Delete},
+	}
+	for _, op := range ops {
+		route := routes[op]
+		mux.HandleFunc(route.pattern, route.handler)
+	}
+}
+
+// pathID parses the id path parameter.
+func (res *Resource[T]) pathID(r *http.Request) (int, error) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, utils.BadRequestError(err, fmt.Sprintf("Invalid %s ID: %s", res.entity.Title(), idStr))
+	}
+	return id, nil
+}
+
+// List handles GET requests to fetch a filtered, sorted page of items
+func (res *Resource[T]) List(w http.ResponseWriter, r *http.Request) {
+	var model T
+
+	// Parse the filters and sortby against the model's columns
+	spec, err := utils.ParseQuerySpec(model, r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+
+	// Parse page, limit and after (cursor) query parameters
+	page, err := utils.ParsePagination(r.URL.Query(), spec.Sort)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, err.Error()))
+		return
+	}
+
+	items, total, err := res.repo.List(spec, page)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	items, meta := utils.Paginate(items, total, page, r.URL)
+
+	response := struct {
+		Status string         `json:"status"`
+		Count  int            `json:"count"`
+		Meta   utils.PageMeta `json:"meta"`
+		Data   []T            `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(items),
+		Meta:   meta,
+		Data:   items,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Get handles GET requests to fetch a specific item
+func (res *Resource[T]) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := res.pathID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	item, err := res.repo.GetByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// Create handles POST requests to create new items
+func (res *Resource[T]) Create(w http.ResponseWriter, r *http.Request) {
+	var model T
+	var newItems []T
+	var rawItems []map[string]any
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.WriteError(w, r, utils.ErrorHandler(err, "Error reading request body"))
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(body, &rawItems)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, "Invalid Request Body"))
+		return
+	}
+
+	// Validate each object in the incoming request against the model's fields
+	validFields := GetFieldNames(model)
+	for _, column := range res.entity.ProtectedColumns() {
+		delete(validFields, column.JSONName)
+	}
+	for _, item := range rawItems {
+		for key := range item {
+			if _, ok := validFields[key]; !ok {
+				utils.WriteError(w, r, utils.BadRequestError(nil, fmt.Sprintf("Unacceptable field: %s, found in request.", key)))
+				return
+			}
+		}
+	}
+
+	err = json.Unmarshal(body, &newItems)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, "Invalid Request Body"))
+		return
+	}
+
+	// Validate every new item and report all failed fields at once
+	if err := utils.ValidateItems(newItems); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	addedItems, err := res.repo.Create(r.Context(), newItems)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	response := struct {
+		Status string `json:"status"`
+		Count  int    `json:"count"`
+		Data   []T    `json:"data"`
+	}{
+		Status: "success",
+		Count:  len(addedItems),
+		Data:   addedItems,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// Update handles PUT requests to replace an existing item
+func (res *Resource[T]) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := res.pathID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var updatedItem T
+	err = json.NewDecoder(r.Body).Decode(&updatedItem)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, "Invalid Request Body"))
+		return
+	}
+
+	if err := utils.ValidateItem(updatedItem); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	result, err := res.repo.Update(r.Context(), id, updatedItem)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Status string `json:"status"`
+		Data   T      `json:"data"`
+	}{
+		Status: "success",
+		Data:   result,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// PatchMany handles PATCH requests to partially update several items,
+// each identified by the id in its body
+func (res *Resource[T]) PatchMany(w http.ResponseWriter, r *http.Request) {
+	var model T
+
+	var updatedFields []map[string]interface{}
+	err := json.NewDecoder(r.Body).Decode(&updatedFields)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+
+	// Validate only the fields being changed
+	if err := utils.ValidatePartialItems(model, updatedFields); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	itemsFromDB, err := res.repo.PatchMany(r.Context(), updatedFields)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(itemsFromDB)
+}
+
+// Patch handles PATCH requests to partially update a specific item
+func (res *Resource[T]) Patch(w http.ResponseWriter, r *http.Request) {
+	var model T
+
+	id, err := res.pathID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var updatedFields map[string]interface{}
+	err = json.NewDecoder(r.Body).Decode(&updatedFields)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+
+	// Validate only the fields being changed
+	if err := utils.ValidatePartial(model, updatedFields); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	itemToUpdate, err := res.repo.Patch(r.Context(), id, updatedFields)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(itemToUpdate)
+}
+
+// Delete handles DELETE requests to remove a specific item
+func (res *Resource[T]) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := res.pathID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	err = res.repo.Delete(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}{
+		Status:  "success",
+		Message: fmt.Sprintf("%s with ID %d deleted successfully", res.entity.Title(), id),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteMany handles DELETE requests to remove the items listed by ID in the body
+func (res *Resource[T]) DeleteMany(w http.ResponseWriter, r *http.Request) {
+	var IDs []int
+	err := json.NewDecoder(r.Body).Decode(&IDs)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+
+	deletedIDs, err := res.repo.DeleteMany(r.Context(), IDs)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Status     string `json:"status"`
+		DeletedIDs []int  `json:"deleted_ids"`
+	}{
+		Status:     fmt.Sprintf("%s successfully deleted", res.entity.PluralTitle()),
+		DeletedIDs: deletedIDs,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
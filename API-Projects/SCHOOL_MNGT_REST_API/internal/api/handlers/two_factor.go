@@ -0,0 +1,501 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	mw "school_management_api/internal/api/middlewares"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// twoFactorSetup is the secret of a new TOTP setup, as text and as the
+// otpauth URI that authenticator apps read from a QR code.
+type twoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// secondFactor is the current TOTP code or, when the authenticator is lost,
+// one of the recovery codes.
+type secondFactor struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// twoFactorChallenge answers a login whose password is correct but which
+// still needs the second factor, sent with the challenge token to
+// POST /executives/login/2fa. SetupRequired is set when the role requires
+// two-factor authentication and the executive has not set it up yet.
+type twoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	SetupRequired     bool   `json:"setup_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+// invalidSecondFactor is the answer to a wrong or unknown code.
+const invalidSecondFactor = "invalid two-factor code"
+
+// twoFactorRequired reports whether executives with the role must use
+// two-factor authentication.
+func (h *Handler) twoFactorRequired(role string) (bool, error) {
+	roles, err := h.twoFactor.RequiredRoles()
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(roles, role), nil
+}
+
+// beginTOTPSetup stores a new TOTP secret for the executive, not enabled
+// until confirmed with a first code.
+func (h *Handler) beginTOTPSetup(ctx context.Context, userExec models.Executive) (twoFactorSetup, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return twoFactorSetup{}, err
+	}
+	if err := h.executives.SetTOTP(ctx, userExec.ID, sql.NullString{String: secret, Valid: true}, false); err != nil {
+		return twoFactorSetup{}, err
+	}
+	return twoFactorSetup{Secret: secret, URI: utils.TOTPURI(userExec.Username, secret)}, nil
+}
+
+// confirmTOTP enables two-factor authentication once the first code of the
+// pending setup is correct, and returns the new recovery codes.
+func (h *Handler) confirmTOTP(ctx context.Context, userExec models.Executive, code string, now time.Time) ([]string, error) {
+	if !userExec.TOTPSecret.Valid {
+		return nil, utils.ValidationError(nil, "start the two-factor setup first")
+	}
+	step, ok := utils.ValidateTOTP(userExec.TOTPSecret.String, strings.TrimSpace(code), now)
+	if !ok {
+		return nil, utils.UnauthorizedError(nil, invalidSecondFactor)
+	}
+
+	codes, codeHashes, err := utils.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.twoFactor.ReplaceRecoveryCodes(userExec.ID, codeHashes); err != nil {
+		return nil, err
+	}
+	if err := h.executives.SetTOTP(ctx, userExec.ID, userExec.TOTPSecret, true); err != nil {
+		return nil, err
+	}
+	// The confirming code cannot log in afterwards
+	if _, err := h.executives.UseTOTPStep(ctx, userExec.ID, step); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkSecondFactor verifies a TOTP code or uses up a recovery code of the
+// executive. Every code works only once.
+func (h *Handler) checkSecondFactor(ctx context.Context, userExec models.Executive, factor secondFactor, now time.Time) error {
+	switch {
+	case factor.Code != "":
+		step, ok := utils.ValidateTOTP(userExec.TOTPSecret.String, strings.TrimSpace(factor.Code), now)
+		if !ok {
+			return utils.UnauthorizedError(nil, invalidSecondFactor)
+		}
+		used, err := h.executives.UseTOTPStep(ctx, userExec.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return utils.UnauthorizedError(nil, "two-factor code was already used, wait for the next one")
+		}
+		return nil
+
+	case factor.RecoveryCode != "":
+		err := h.twoFactor.UseRecoveryCode(userExec.ID, utils.HashRecoveryCode(factor.RecoveryCode), now)
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			return utils.UnauthorizedError(nil, invalidSecondFactor)
+		}
+		return err
+	}
+	return utils.ValidationError(nil, "code or recovery_code is required")
+}
+
+// sendChallenge answers a correct password with a challenge token instead
+// of a session.
+func sendChallenge(w http.ResponseWriter, r *http.Request, userExec models.Executive, setupRequired bool) {
+	token, err := utils.SignChallengeToken(userExec.ID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(twoFactorChallenge{
+		TwoFactorRequired: true,
+		SetupRequired:     setupRequired,
+		ChallengeToken:    token,
+		ExpiresIn:         int(utils.ChallengeTokenTTL.Seconds()),
+	})
+}
+
+// userFromChallenge returns the executive of a valid challenge token, with
+// the token ID (jti) and expiry used to revoke it.
+func (h *Handler) userFromChallenge(token string) (models.Executive, string, time.Time, error) {
+	invalid := utils.UnauthorizedError(nil, "two-factor challenge is invalid or has expired, please log in again")
+
+	id, jti, expiresAt, err := utils.ParseChallengeToken(token)
+	if err != nil {
+		return models.Executive{}, "", time.Time{}, err
+	}
+	revoked, err := h.tokens.IsAccessTokenRevoked(jti, time.Now())
+	if err != nil {
+		return models.Executive{}, "", time.Time{}, err
+	}
+	if revoked {
+		return models.Executive{}, "", time.Time{}, invalid
+	}
+
+	userExec, err := h.executives.GetUserByID(id)
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			err = invalid
+		}
+		return models.Executive{}, "", time.Time{}, err
+	}
+	if userExec.InactiveStatus {
+		return models.Executive{}, "", time.Time{}, invalid
+	}
+	return userExec, jti, expiresAt, nil
+}
+
+// ownExecutiveID returns the executive ID of the path, which must be the
+// caller's own.
+func ownExecutiveID(r *http.Request) (int, error) {
+	idStr := r.PathValue("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, utils.BadRequestError(err, fmt.Sprintf("Invalid Executive ID: %s", idStr))
+	}
+	if uid, ok := mw.UserIDFromContext(r.Context()); !ok || uid != id {
+		return 0, utils.ForbiddenError(nil, "executives can only manage their own two-factor authentication")
+	}
+	return id, nil
+}
+
+// TwoFactorSetupHandler starts the two-factor setup of the caller with a
+// new TOTP secret. Until it is confirmed, logins keep asking only for the
+// password.
+func (h *Handler) TwoFactorSetupHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := ownExecutiveID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	userExec, err := h.executives.GetUserByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if userExec.TOTPEnabled {
+		utils.WriteError(w, r, utils.ConflictError(nil, "two-factor authentication is already enabled"))
+		return
+	}
+
+	setup, err := h.beginTOTPSetup(r.Context(), userExec)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setup)
+}
+
+// TwoFactorConfirmHandler enables two-factor authentication of the caller
+// with the first code of the authenticator app. The recovery codes are
+// returned only here, so the executive must keep them.
+func (h *Handler) TwoFactorConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := ownExecutiveID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" validate:"required"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	userExec, err := h.executives.GetUserByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if userExec.TOTPEnabled {
+		utils.WriteError(w, r, utils.ConflictError(nil, "two-factor authentication is already enabled"))
+		return
+	}
+
+	codes, err := h.confirmTOTP(r.Context(), userExec, req.Code, time.Now())
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		Message       string   `json:"message"`
+		RecoveryCodes []string `json:"recovery_codes"`
+	}{
+		Message:       "Two-factor authentication enabled, keep the recovery codes in a safe place",
+		RecoveryCodes: codes,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// TwoFactorDisableHandler turns off two-factor authentication of the caller
+// after confirming the password and a code. Executives whose role requires
+// two-factor authentication cannot turn it off.
+func (h *Handler) TwoFactorDisableHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := ownExecutiveID(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var req struct {
+		Password string `json:"password" validate:"required"`
+		secondFactor
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	userExec, err := h.executives.GetUserByID(id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if !userExec.TOTPEnabled {
+		utils.WriteError(w, r, utils.ConflictError(nil, "two-factor authentication is not enabled"))
+		return
+	}
+
+	required, err := h.twoFactorRequired(userExec.Role)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if required {
+		utils.WriteError(w, r, utils.ForbiddenError(nil,
+			fmt.Sprintf("two-factor authentication is required for the %s role", userExec.Role)))
+		return
+	}
+
+	if !checkCurrentPassword(w, r, h.logins, userExec.Username, userExec.Password, req.Password) {
+		return
+	}
+	if err := h.checkSecondFactor(r.Context(), userExec, req.secondFactor, time.Now()); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	if err := h.executives.SetTOTP(r.Context(), id, sql.NullString{}, false); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if err := h.twoFactor.ReplaceRecoveryCodes(id, nil); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message":"Two-factor authentication disabled"}`))
+}
+
+// LoginTwoFactorSetupHandler starts the two-factor setup during the login
+// of an executive whose role requires it, authenticated by the challenge
+// token. The first code is then sent to POST /executives/login/2fa.
+func (h *Handler) LoginTwoFactorSetupHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" validate:"required"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	userExec, _, _, err := h.userFromChallenge(req.ChallengeToken)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if userExec.TOTPEnabled {
+		utils.WriteError(w, r, utils.ConflictError(nil, "two-factor authentication is already enabled"))
+		return
+	}
+
+	setup, err := h.beginTOTPSetup(loginContext(r, userExec), userExec)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setup)
+}
+
+// LoginTwoFactorHandler completes a login with the challenge token and the
+// second factor, and starts the session like LoginHandler does without
+// two-factor authentication. For an executive finishing the setup required
+// by their role, the code confirms the setup and the recovery codes are
+// returned along with the tokens. Wrong codes count as failed logins.
+func (h *Handler) LoginTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" validate:"required"`
+		secondFactor
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	userExec, jti, expiresAt, err := h.userFromChallenge(req.ChallengeToken)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	ip := mw.ClientIP(r)
+	now := time.Now()
+	wait, err := h.logins.Check(userExec.Username, ip, now)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		utils.WriteError(w, r, utils.TooManyRequestsError(nil, "too many failed login attempts, try again later"))
+		return
+	}
+
+	ctx := loginContext(r, userExec)
+	var recoveryCodes []string
+	if userExec.TOTPEnabled {
+		err = h.checkSecondFactor(ctx, userExec, req.secondFactor, now)
+	} else {
+		recoveryCodes, err = h.confirmTOTP(ctx, userExec, req.Code, now)
+	}
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeUnauthorized {
+			if failErr := h.logins.Failed(userExec.Username, ip, now); failErr != nil {
+				err = failErr
+			}
+		}
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	// The challenge completes one login only
+	if err := h.tokens.RevokeAccessToken(jti, expiresAt); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if err := h.logins.Succeeded(userExec.Username); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	tokens, err := h.startSession(w, userExec)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		tokenPair
+		RecoveryCodes []string `json:"recovery_codes,omitempty"`
+	}{
+		tokenPair:     tokens,
+		RecoveryCodes: recoveryCodes,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// TwoFactorPolicyHandler returns the roles that must use two-factor authentication.
+func (h *Handler) TwoFactorPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.twoFactor.RequiredRoles()
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := struct {
+		RequiredRoles []string `json:"required_roles"`
+	}{
+		RequiredRoles: roles,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateTwoFactorPolicyHandler replaces the roles that must use two-factor
+// authentication. Executives of a newly required role who have not set it
+// up are taken through the setup at their next login.
+func (h *Handler) UpdateTwoFactorPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RequiredRoles []string `json:"required_roles" validate:"required,dive,role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, r, utils.BadRequestError(err, fmt.Sprintf("Failed to decode request body: %v", err)))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := utils.ValidateItem(req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	if err := h.twoFactor.SetRequiredRoles(req.RequiredRoles); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	h.TwoFactorPolicyHandler(w, r)
+}
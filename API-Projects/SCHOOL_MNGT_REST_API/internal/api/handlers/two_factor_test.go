@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository/memory"
+	"school_management_api/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckSecondFactorStepReuse(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	h := &Handler{executives: store.Executives, twoFactor: store.TwoFactor}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, err := store.Executives.Create(ctx, []models.Executive{{
+		FirstName: "Jane", LastName: "Doe", Email: "jane@example.com",
+		Username: "janedoe", Password: "Str0ng!Passw0rd", Role: "admin",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := added[0].ID
+	if err := store.Executives.SetTOTP(ctx, id, sql.NullString{String: secret, Valid: true}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Unix(30*1_000_000, 0)
+	step := utils.TOTPStep(start)
+	code := func(s int64) string {
+		c, err := utils.TOTPCode(secret, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	// The attempts run in order against the same executive
+	tests := []struct {
+		name    string
+		code    string
+		at      time.Duration
+		wantErr string
+	}{
+		{name: "current code", code: code(step)},
+		{name: "same code again", code: code(step), wantErr: "already used"},
+		{name: "earlier code within the skew", code: code(step - 1), wantErr: "already used"},
+		{name: "next code within the skew", code: code(step + 1)},
+		{name: "next code in its own step", code: code(step + 1), at: 30 * time.Second, wantErr: "already used"},
+		{name: "code outside the skew", code: code(step + 3), wantErr: invalidSecondFactor},
+		{name: "malformed code", code: "12ab56", wantErr: invalidSecondFactor},
+		{name: "later code", code: code(step + 2), at: time.Minute},
+	}
+
+	for _, tt := range tests {
+		// Read the executive like the login does, the last step comes from the store
+		userExec, err := store.Executives.GetUserByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.checkSecondFactor(ctx, userExec, secondFactor{Code: tt.code}, start.Add(tt.at))
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"time"
+)
+
+// apiKeyTouchInterval limits how often the last use of a key is written.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyMiddleware authenticates requests sending an X-API-Key header and
+// passes the others to jwt. A key acts with the role of the executive who
+// created it, limited to its scopes by RBAC, and stops working once it is
+// revoked, expires or its executive is deactivated or deleted.
+func APIKeyMiddleware(keys repository.APIKeyRepository, executives repository.ExecutiveRepository,
+	jwt func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withToken := jwt(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("X-API-Key")
+			if header == "" {
+				withToken.ServeHTTP(w, r)
+				return
+			}
+			if r.Header.Get("Authorization") != "" {
+				utils.WriteError(w, r, utils.UnauthorizedError(nil, "send either an API key or a login token, not both"))
+				return
+			}
+
+			now := time.Now()
+			key, userExec, err := authenticateAPIKey(keys, executives, header, now)
+			if err != nil {
+				utils.WriteError(w, r, err)
+				return
+			}
+
+			if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+				if err := keys.TouchAPIKey(key.ID, now); err != nil {
+					utils.WriteError(w, r, err)
+					return
+				}
+			}
+
+			// userid is a float64 like the uid claim of a token
+			ctx := context.WithValue(r.Context(), ContextKey("role"), userExec.Role)
+			ctx = context.WithValue(ctx, ContextKey("subject_type"), models.SubjectAPIKey)
+			ctx = context.WithValue(ctx, ContextKey("userid"), float64(userExec.ID))
+			ctx = context.WithValue(ctx, ContextKey("username"), userExec.Username)
+			ctx = context.WithValue(ctx, ContextKey("api_key_id"), key.ID)
+			ctx = context.WithValue(ctx, ContextKey("scopes"), key.Scopes)
+			ctx = repository.WithActor(ctx, repository.Actor{Type: models.SubjectAPIKey, ID: userExec.ID, Name: userExec.Username})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authenticateAPIKey returns a usable key and its executive, or an
+// Unauthorized error for an unknown, revoked or expired key and a Forbidden
+// error when the executive is inactive.
+func authenticateAPIKey(keys repository.APIKeyRepository, executives repository.ExecutiveRepository,
+	plaintext string, now time.Time) (models.APIKey, models.Executive, error) {
+	key, err := keys.GetAPIKeyByHash(utils.HashAPIKey(plaintext))
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			return models.APIKey{}, models.Executive{}, utils.UnauthorizedError(nil, "Invalid API key")
+		}
+		return models.APIKey{}, models.Executive{}, err
+	}
+	if key.RevokedAt != nil {
+		return models.APIKey{}, models.Executive{}, utils.UnauthorizedError(nil, "API key has been revoked")
+	}
+	if !key.ExpiresAt.After(now) {
+		return models.APIKey{}, models.Executive{}, utils.UnauthorizedError(nil, "API key has expired")
+	}
+
+	userExec, err := executives.GetUserByID(key.ExecutiveID)
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			return models.APIKey{}, models.Executive{}, utils.UnauthorizedError(nil, "Invalid API key")
+		}
+		return models.APIKey{}, models.Executive{}, err
+	}
+	if userExec.InactiveStatus {
+		return models.APIKey{}, models.Executive{}, utils.ForbiddenError(nil, "executive account is inactive")
+	}
+	return key, userExec, nil
+}
+
+// APIKeyScopesFromContext returns the scopes of the API key authenticated
+// by APIKeyMiddleware. It is false for requests authenticated with a token.
+func APIKeyScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(ContextKey("scopes")).([]string)
+	return scopes, ok
+}
@@ -3,6 +3,7 @@ package middlewares
 import (
 	"fmt"
 	"net/http"
+	"school_management_api/pkg/utils"
 )
 
 // Allow multiple origins
@@ -27,13 +28,13 @@ func Cors(next http.Handler) http.Handler {
 		fmt.Println(origin)
 
 		if !isOriginAllowed(origin) {
-			http.Error(w, "Not allowed by CORS", http.StatusForbidden)
+			utils.WriteError(w, r, utils.ForbiddenError(nil, "Not allowed by CORS"))
 			return
 		}
 
 		h := w.Header()
 		h.Set("Access-Control-Allow-Origin", origin)
-		h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+CSRFHeaderName)
 		h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
 		h.Set("Access-Control-Allow-Credentials", "true")
 		h.Set("Access-Control-Expose-Headers", "Authorization")
@@ -2,69 +2,222 @@ package middlewares
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"fmt"
-	"log"
 	"net/http"
-	"os"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
 	"school_management_api/pkg/utils"
+	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type ContextKey string
 
-// JwtMiddleware validates JWT tokens in incoming requests
-func JwtMiddleware(next http.Handler) http.Handler {
-	fmt.Println("JWT Middleware executed")
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Println("********* Inside JWT Middleware *********")
+// JwtMiddleware validates JWT tokens in incoming requests, sent in an
+// Authorization header or the Bearer cookie; cookie requests that change
+// state must also pass CheckCSRF. Tokens issued before the user's latest
+// password change are rejected, so changing a password ends every other
+// session, and so are tokens revoked at logout. Executives, teachers and
+// students log in, told apart by the subject_type claim.
+func JwtMiddleware(executives repository.ExecutiveRepository, accounts repository.AccountRepository,
+	tokens repository.TokenRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, fromCookie, err := tokenFromRequest(r)
+			if err != nil {
+				utils.WriteError(w, r, err)
+				return
+			}
 
-		token, err := r.Cookie("Bearer")
-		if err != nil {
-			http.Error(w, "Authorization Header Missing", http.StatusUnauthorized)
-			return
-		}
+			// Browsers send cookies on cross-site requests, headers are set by the client
+			if fromCookie {
+				if err := CheckCSRF(r); err != nil {
+					utils.WriteError(w, r, err)
+					return
+				}
+			}
 
-		jwtSecret := os.Getenv("JWT_SECRET")
+			// Parse and validate the token with the key named in its kid header
+			parsedToken, err := utils.ParseToken(token)
+			if err != nil {
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					utils.WriteError(w, r, utils.UnauthorizedError(nil, "Token has expired"))
+					return
+				}
+				utils.WriteError(w, r, utils.UnauthorizedError(err, "Invalid Login Token"))
+				return
+			}
 
-		// Parse and validate the token
-		parsedToken, err := jwt.Parse(token.Value, func(token *jwt.Token) (any, error) {
-			// Validate the signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			// Check if the token is valid
+			if !parsedToken.Valid {
+				utils.WriteError(w, r, utils.UnauthorizedError(nil, "Invalid Login Token"))
+				return
 			}
 
-			return []byte(jwtSecret), nil
-		})
-		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				http.Error(w, "Token has expired", http.StatusUnauthorized)
+			claims, ok := parsedToken.Claims.(jwt.MapClaims)
+			if !ok {
+				utils.WriteError(w, r, utils.UnauthorizedError(nil, "Invalid Login Token"))
 				return
 			}
-			utils.ErrorHandler(err, "")
-			http.Error(w, err.Error(), http.StatusUnauthorized)
-			return
-		}
 
-		// Check if the token is valid
-		if parsedToken.Valid {
-			log.Println("Valid JWT Token")
-		} else {
-			http.Error(w, "Invalid Login Token", http.StatusUnauthorized)
-			log.Println("Invalid Login Token:", token.Value)
+			// Challenge tokens of a login waiting for its second factor do not authenticate
+			if typ, ok := claims["typ"]; ok && typ != utils.AccessTokenType {
+				utils.WriteError(w, r, utils.UnauthorizedError(nil, "Invalid Login Token"))
+				return
+			}
+
+			if err := checkRevoked(tokens, claims); err != nil {
+				utils.WriteError(w, r, err)
+				return
+			}
+
+			subjectType, err := checkPasswordChange(executives, accounts, claims)
+			if err != nil {
+				utils.WriteError(w, r, err)
+				return
+			}
+
+			// Teacher and student tokens always carry the role of their subject type
+			role := claims["role"]
+			if subjectType != models.SubjectExecutive {
+				role = subjectType
+			}
+
+			ctx := context.WithValue(r.Context(), ContextKey("role"), role)
+			ctx = context.WithValue(ctx, ContextKey("subject_type"), subjectType)
+			ctx = context.WithValue(ctx, ContextKey("userid"), claims["uid"])
+			ctx = context.WithValue(ctx, ContextKey("expiresAt"), claims["exp"])
+			ctx = context.WithValue(ctx, ContextKey("username"), claims["user"])
+			ctx = context.WithValue(ctx, ContextKey("jti"), claims["jti"])
+
+			// Writes of the request are audited as made by the subject of the token
+			uid, _ := claims["uid"].(float64)
+			username, _ := claims["user"].(string)
+			ctx = repository.WithActor(ctx, repository.Actor{Type: subjectType, ID: int(uid), Name: username})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// tokenFromRequest returns the token of an "Authorization: Bearer <jwt>"
+// header or, without the header, of the Bearer cookie, and whether it came
+// from the cookie.
+func tokenFromRequest(r *http.Request) (string, bool, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return "", false, utils.UnauthorizedError(nil, "Invalid Authorization Header")
 		}
+		return strings.TrimSpace(token), false, nil
+	}
+
+	cookie, err := r.Cookie("Bearer")
+	if err != nil {
+		return "", false, utils.UnauthorizedError(nil, "Authorization Header Missing")
+	}
+	return cookie.Value, true, nil
+}
+
+// checkPasswordChange returns the subject type of the token, or an
+// Unauthorized error when the token was issued before the password of its
+// subject was last changed, or the subject or its account is gone.
+func checkPasswordChange(executives repository.ExecutiveRepository, accounts repository.AccountRepository, claims jwt.MapClaims) (string, error) {
+	uid, ok := claims["uid"].(float64)
+	if !ok {
+		return "", utils.UnauthorizedError(nil, "Invalid Login Token")
+	}
 
-		claims, ok := parsedToken.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "Invalid Login Token", http.StatusUnauthorized)
+	// Tokens issued before the claim existed are executive tokens
+	subjectType, _ := claims["subject_type"].(string)
+	if subjectType == "" {
+		subjectType = models.SubjectExecutive
+	}
+
+	var changedAt sql.NullString
+	var err error
+	switch subjectType {
+	case models.SubjectExecutive:
+		var user models.Executive
+		user, err = executives.GetUserByID(int(uid))
+		changedAt = user.PasswordChangedAt
+	case models.SubjectTeacher, models.SubjectStudent:
+		var account models.Account
+		account, err = accounts.GetAccountBySubject(subjectType, int(uid))
+		changedAt = account.PasswordChangedAt
+	default:
+		return "", utils.UnauthorizedError(nil, "Invalid Login Token")
+	}
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) && appErr.Code == utils.CodeNotFound {
+			return "", utils.UnauthorizedError(nil, "Invalid Login Token")
 		}
+		return "", err
+	}
+	if !changedAt.Valid {
+		return subjectType, nil
+	}
+
+	// Tokens without iat predate the claim and are treated as issued before any change
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return "", utils.UnauthorizedError(nil, "Token was issued before the latest password change, please log in again")
+	}
+	changed, err := utils.PasswordChangedAfter(changedAt, issuedAt.Time)
+	if err != nil {
+		return "", err
+	}
+	if changed {
+		return "", utils.UnauthorizedError(nil, "Token was issued before the latest password change, please log in again")
+	}
+	return subjectType, nil
+}
 
-		ctx := context.WithValue(r.Context(), ContextKey("role"), claims["role"])
-		ctx = context.WithValue(ctx, ContextKey("userid"), claims["uid"])
-		ctx = context.WithValue(ctx, ContextKey("expiresAt"), claims["exp"])
-		ctx = context.WithValue(ctx, ContextKey("username"), claims["user"])
+// checkRevoked returns an Unauthorized error when the token has no ID or
+// its ID was revoked, e.g. at logout.
+func checkRevoked(tokens repository.TokenRepository, claims jwt.MapClaims) error {
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return utils.UnauthorizedError(nil, "Invalid Login Token")
+	}
+
+	revoked, err := tokens.IsAccessTokenRevoked(jti, time.Now())
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return utils.UnauthorizedError(nil, "Token has been revoked, please log in again")
+	}
+	return nil
+}
+
+// UserIDFromContext returns the ID of the executive authenticated by
+// JwtMiddleware. It is false for teacher and student tokens.
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	subjectType, id, ok := SubjectFromContext(ctx)
+	return id, ok && subjectType == models.SubjectExecutive
+}
+
+// SubjectFromContext returns the subject type and ID of the executive,
+// teacher or student authenticated by JwtMiddleware. For API keys it is
+// models.SubjectAPIKey and the ID of the executive owning the key.
+func SubjectFromContext(ctx context.Context) (string, int, bool) {
+	subjectType, typeOK := ctx.Value(ContextKey("subject_type")).(string)
+	uid, ok := ctx.Value(ContextKey("userid")).(float64)
+	return subjectType, int(uid), typeOK && ok
+}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// TokenIDFromContext returns the ID (jti) and expiry of the access token
+// authenticated by JwtMiddleware.
+func TokenIDFromContext(ctx context.Context) (string, time.Time, bool) {
+	jti, ok := ctx.Value(ContextKey("jti")).(string)
+	exp, expOK := ctx.Value(ContextKey("expiresAt")).(float64)
+	if !ok || !expOK {
+		return "", time.Time{}, false
+	}
+	return jti, time.Unix(int64(exp), 0), true
 }
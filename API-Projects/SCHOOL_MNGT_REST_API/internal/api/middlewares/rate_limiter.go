@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"school_management_api/pkg/utils"
 	"sync"
 	"time"
 )
@@ -39,7 +40,8 @@ func (rl *rateLimiter) resetVisitorCount() {
 	}
 }
 
-func clientIP(r *http.Request) string {
+// ClientIP returns the IP address of the client that sent the request.
+func ClientIP(r *http.Request) string {
 	// minimal: strip port from RemoteAddr
 	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
 		return host
@@ -51,7 +53,7 @@ func clientIP(r *http.Request) string {
 func (rl *rateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Identify the user/IP (for simplicity, using RemoteAddr here)
-		user := clientIP(r)
+		user := ClientIP(r)
 
 		rl.mu.Lock()
 		rl.visitors[user]++
@@ -60,7 +62,7 @@ func (rl *rateLimiter) Middleware(next http.Handler) http.Handler {
 		rl.mu.Unlock()
 
 		if exceeded {
-			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			utils.WriteError(w, r, utils.TooManyRequestsError(nil, "Too Many Requests"))
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -0,0 +1,142 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strconv"
+)
+
+// AccessRule allows the listed roles to call one route. Owner also allows an
+// executive to call a route about themselves, i.e. one whose {id} is their
+// own ID, whatever their role. Teacher and student tokens have the role
+// "teacher" or "student", and are only allowed on the rules listing it.
+// API keys need both the role of their executive and one of the Scopes of
+// the rule; routes without scopes cannot be called with a key.
+type AccessRule struct {
+	Method  string   `json:"method"`
+	Pattern string   `json:"pattern"`
+	Roles   []string `json:"roles"`
+	Owner   bool     `json:"owner,omitempty"`
+	Scopes  []string `json:"scopes,omitempty"`
+}
+
+// AccessPolicy maps the routes of the API to the roles allowed to call them.
+// Routes without a rule are denied.
+type AccessPolicy struct {
+	Rules []AccessRule `json:"rules"`
+}
+
+// AccessDenied is the details of the 403 response for a denied request.
+type AccessDenied struct {
+	Route         string   `json:"route"`
+	Role          string   `json:"role"`
+	AllowedRoles  []string `json:"allowed_roles"`
+	OwnerAllowed  bool     `json:"owner_allowed,omitempty"`
+	AllowedScopes []string `json:"allowed_scopes,omitempty"`
+}
+
+// LoadPolicy reads and checks an access policy from a JSON file, e.g.
+// rbac_policy.json.
+func LoadPolicy(path string) (AccessPolicy, error) {
+	var policy AccessPolicy
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy, err
+	}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return policy, fmt.Errorf("invalid access policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid access policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Validate checks that every rule has a valid, unique route pattern and only
+// names known roles and API key scopes.
+func (p AccessPolicy) Validate() (err error) {
+	// ServeMux panics on invalid or duplicate patterns
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	for _, rule := range p.Rules {
+		if rule.Method == "" || rule.Pattern == "" {
+			return fmt.Errorf("rule %q %q: method and pattern are required", rule.Method, rule.Pattern)
+		}
+		for _, role := range rule.Roles {
+			if !slices.Contains(models.ExecutiveRoles, role) && !slices.Contains(models.AccountRoles, role) {
+				return fmt.Errorf("rule %s %s: unknown role %q", rule.Method, rule.Pattern, role)
+			}
+		}
+		for _, scope := range rule.Scopes {
+			if !slices.Contains(models.APIKeyScopes, scope) {
+				return fmt.Errorf("rule %s %s: unknown API key scope %q", rule.Method, rule.Pattern, scope)
+			}
+		}
+		mux.HandleFunc(rule.Method+" "+rule.Pattern, func(http.ResponseWriter, *http.Request) {})
+	}
+	return nil
+}
+
+// RBAC enforces an access policy using the role, user ID and API key scopes
+// that JwtMiddleware or APIKeyMiddleware put in the request context, so it
+// must run after them.
+func RBAC(policy AccessPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		// Route the request like the API router does to find its rule
+		mux := http.NewServeMux()
+		for _, rule := range policy.Rules {
+			mux.HandleFunc(rule.Method+" "+rule.Pattern, func(w http.ResponseWriter, r *http.Request) {
+				if !rule.allows(r) {
+					role, _ := r.Context().Value(ContextKey("role")).(string)
+					utils.WriteError(w, r, utils.ForbiddenError(nil, "you are not allowed to access this resource").
+						WithDetails(AccessDenied{
+							Route:         r.Pattern,
+							Role:          role,
+							AllowedRoles:  rule.Roles,
+							OwnerAllowed:  rule.Owner,
+							AllowedScopes: rule.Scopes,
+						}))
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		}
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			utils.WriteError(w, r, utils.ForbiddenError(nil, "no access policy allows this route"))
+		})
+		return mux
+	}
+}
+
+// allows reports whether the authenticated caller may call the route.
+func (rule AccessRule) allows(r *http.Request) bool {
+	role, _ := r.Context().Value(ContextKey("role")).(string)
+
+	// Keys act for their executive but are never the owner of a route
+	if scopes, ok := APIKeyScopesFromContext(r.Context()); ok {
+		return slices.Contains(rule.Roles, role) &&
+			slices.ContainsFunc(scopes, func(scope string) bool { return slices.Contains(rule.Scopes, scope) })
+	}
+
+	if slices.Contains(rule.Roles, role) {
+		return true
+	}
+	if !rule.Owner {
+		return false
+	}
+
+	uid, ok := UserIDFromContext(r.Context())
+	id, err := strconv.Atoi(r.PathValue("id"))
+	return ok && err == nil && uid == id
+}
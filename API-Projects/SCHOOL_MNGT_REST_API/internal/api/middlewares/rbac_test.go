@@ -0,0 +1,127 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"school_management_api/internal/models"
+	"testing"
+)
+
+// caller is who a test request is authenticated as. Scopes are set for API keys only.
+type caller struct {
+	role        string
+	subjectType string
+	id          int
+	scopes      []string
+}
+
+// withCaller returns r carrying the context values JwtMiddleware or
+// APIKeyMiddleware would set for c.
+func withCaller(r *http.Request, c caller) *http.Request {
+	if c.role == "" {
+		return r
+	}
+	ctx := context.WithValue(r.Context(), ContextKey("role"), c.role)
+	ctx = context.WithValue(ctx, ContextKey("subject_type"), c.subjectType)
+	ctx = context.WithValue(ctx, ContextKey("userid"), float64(c.id))
+	if c.scopes != nil {
+		ctx = context.WithValue(ctx, ContextKey("scopes"), c.scopes)
+	}
+	return r.WithContext(ctx)
+}
+
+func TestRBAC(t *testing.T) {
+	policy := AccessPolicy{Rules: []AccessRule{
+		{Method: "GET", Pattern: "/students", Roles: []string{"admin", "manager"}, Scopes: []string{"students:read"}},
+		{Method: "DELETE", Pattern: "/students/{id}", Roles: []string{"admin"}, Scopes: []string{"students:write"}},
+		{Method: "GET", Pattern: "/assessments", Roles: []string{"admin", "teacher"}, Scopes: []string{"grades:read"}},
+		{Method: "POST", Pattern: "/executives/{id}/updatepassword", Roles: []string{"admin"}, Owner: true},
+		{Method: "GET", Pattern: "/me", Roles: []string{"teacher", "student"}},
+	}}
+
+	admin := caller{role: "admin", subjectType: models.SubjectExecutive, id: 1}
+	manager := caller{role: "manager", subjectType: models.SubjectExecutive, id: 7}
+	assistant := caller{role: "office assistant", subjectType: models.SubjectExecutive, id: 9}
+	teacher := caller{role: models.SubjectTeacher, subjectType: models.SubjectTeacher, id: 7}
+	key := func(role string, scopes ...string) caller {
+		return caller{role: role, subjectType: models.SubjectAPIKey, id: 7, scopes: append([]string{}, scopes...)}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		caller caller
+		want   int
+	}{
+		{name: "listed role", method: "GET", path: "/students", caller: manager, want: http.StatusOK},
+		{name: "unlisted role", method: "GET", path: "/students", caller: assistant, want: http.StatusForbidden},
+		{name: "no role", method: "GET", path: "/students", want: http.StatusForbidden},
+		{name: "path with ID", method: "DELETE", path: "/students/3", caller: admin, want: http.StatusOK},
+		{name: "role of another method", method: "DELETE", path: "/students/3", caller: manager, want: http.StatusForbidden},
+		{name: "method without rule", method: "PUT", path: "/students", caller: admin, want: http.StatusForbidden},
+		{name: "route without rule", method: "GET", path: "/teachers", caller: admin, want: http.StatusForbidden},
+		{name: "owner", method: "POST", path: "/executives/7/updatepassword", caller: manager, want: http.StatusOK},
+		{name: "owner of another ID", method: "POST", path: "/executives/8/updatepassword", caller: manager, want: http.StatusForbidden},
+		{name: "role on an owner route", method: "POST", path: "/executives/8/updatepassword", caller: admin, want: http.StatusOK},
+		{name: "teacher is no owner", method: "POST", path: "/executives/7/updatepassword", caller: teacher, want: http.StatusForbidden},
+		{name: "teacher role", method: "GET", path: "/assessments", caller: teacher, want: http.StatusOK},
+		{name: "account route", method: "GET", path: "/me", caller: teacher, want: http.StatusOK},
+		{name: "executive on an account route", method: "GET", path: "/me", caller: admin, want: http.StatusForbidden},
+		{name: "key with scope", method: "GET", path: "/students", caller: key("manager", "students:read"), want: http.StatusOK},
+		{name: "key with other scope", method: "GET", path: "/students", caller: key("manager", "students:write"), want: http.StatusForbidden},
+		{name: "key without scopes", method: "GET", path: "/students", caller: key("admin"), want: http.StatusForbidden},
+		{name: "key scope without role", method: "DELETE", path: "/students/3", caller: key("manager", "students:write"), want: http.StatusForbidden},
+		{name: "key on route without scopes", method: "POST", path: "/executives/8/updatepassword", caller: key("admin", "students:write"), want: http.StatusForbidden},
+		{name: "key is no owner", method: "POST", path: "/executives/7/updatepassword", caller: key("manager", "students:read"), want: http.StatusForbidden},
+	}
+
+	handler := RBAC(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := withCaller(httptest.NewRequest(tt.method, tt.path, nil), tt.caller)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("%s %s as %+v: status %d, want %d", tt.method, tt.path, tt.caller, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []AccessRule
+		wantErr bool
+	}{
+		{name: "valid", rules: []AccessRule{
+			{Method: "GET", Pattern: "/students", Roles: []string{"admin", "teacher"}, Scopes: []string{"students:read"}},
+			{Method: "POST", Pattern: "/students", Roles: []string{"admin"}},
+		}},
+		{name: "missing method", rules: []AccessRule{{Pattern: "/students", Roles: []string{"admin"}}}, wantErr: true},
+		{name: "unknown role", rules: []AccessRule{{Method: "GET", Pattern: "/students", Roles: []string{"janitor"}}}, wantErr: true},
+		{name: "unknown scope", rules: []AccessRule{{Method: "GET", Pattern: "/students", Roles: []string{"admin"}, Scopes: []string{"students:all"}}}, wantErr: true},
+		{name: "invalid pattern", rules: []AccessRule{{Method: "GET", Pattern: "/students/{id", Roles: []string{"admin"}}}, wantErr: true},
+		{name: "duplicate route", rules: []AccessRule{
+			{Method: "GET", Pattern: "/students", Roles: []string{"admin"}},
+			{Method: "GET", Pattern: "/students", Roles: []string{"manager"}},
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AccessPolicy{Rules: tt.rules}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	if _, err := LoadPolicy("../../../rbac_policy.json"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"net/http"
+	"school_management_api/internal/api/handlers"
+)
+
+func assessmentsRouter(h *handlers.Handler) *http.ServeMux {
+	// Define the router for assessment-related routes
+	mux := http.NewServeMux()
+
+	h.Assessments.Register(mux, "/assessments", handlers.AllOperations...)
+
+	// Scores are entered in bulk for the students of the class
+	mux.HandleFunc("GET /assessments/{id}/grades", h.GetAssessmentGradesHandler)
+	mux.HandleFunc("PUT /assessments/{id}/grades", h.SetAssessmentGradesHandler)
+
+	return mux
+}
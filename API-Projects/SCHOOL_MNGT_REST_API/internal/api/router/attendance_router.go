@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+	"school_management_api/internal/api/handlers"
+)
+
+func attendanceRouter(h *handlers.Handler) *http.ServeMux {
+	// Define the router for attendance-related routes
+	mux := http.NewServeMux()
+
+	// Records are created by taking the roll of a class, see classesRouter,
+	// so single records are only read, corrected and deleted here
+	h.Attendance.Register(mux, "/attendance",
+		handlers.OpList, handlers.OpGet, handlers.OpPatch, handlers.OpDelete)
+
+	mux.HandleFunc("GET /attendance/reports/students", h.StudentAttendanceReportHandler)
+	mux.HandleFunc("GET /attendance/reports/classes", h.ClassAttendanceReportHandler)
+	mux.HandleFunc("GET /attendance/reports/absentees", h.AbsenteesReportHandler)
+
+	return mux
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"net/http"
+	"school_management_api/internal/api/handlers"
+)
+
+func auditRouter(h *handlers.Handler) *http.ServeMux {
+	// Define the router for the audit log of the writes
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /audit", h.AuditHandler)
+
+	return mux
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"net/http"
+	"school_management_api/internal/api/handlers"
+)
+
+func classesRouter(h *handlers.Handler) *http.ServeMux {
+	// Define the router for class-related routes
+	mux := http.NewServeMux()
+
+	h.Classes.Register(mux, "/classes", handlers.AllOperations...)
+
+	mux.HandleFunc("GET /classes/{id}/students", h.GetStudentsByClassIDHandler)
+	mux.HandleFunc("GET /classes/{id}/teachers", h.GetTeachersByClassIDHandler)
+	mux.HandleFunc("PUT /classes/{id}/teachers/{teacherid}", h.AssignClassTeacherHandler)
+	mux.HandleFunc("DELETE /classes/{id}/teachers/{teacherid}", h.UnassignClassTeacherHandler)
+
+	// The roll of the whole class is taken in one call
+	mux.HandleFunc("POST /classes/{id}/attendance", h.TakeRollHandler)
+	mux.HandleFunc("GET /classes/{id}/attendance", h.GetClassRegisterHandler)
+
+	return mux
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"net/http"
+	"school_management_api/internal/api/handlers"
+)
+
+func enrollmentsRouter(h *handlers.Handler) *http.ServeMux {
+	// Define the router for enrollment-related routes
+	mux := http.NewServeMux()
+
+	// Enrollments are made and withdrawn in bulk, students move to another
+	// section by unenrolling and enrolling again, so there is no PUT or PATCH
+	h.Enrollments.Register(mux, "/enrollments",
+		handlers.OpList, handlers.OpCreate, handlers.OpDeleteMany,
+		handlers.OpGet, handlers.OpDelete)
+
+	return mux
+}
@@ -5,25 +5,39 @@ import (
 	"school_management_api/internal/api/handlers"
 )
 
-func execsRouter() *http.ServeMux {
+func execsRouter(h *handlers.Handler) *http.ServeMux {
 	// Define the router for executive-related routes
 	mux := http.NewServeMux()
 
-	// Executives route
-	mux.HandleFunc("GET /executives", handlers.GetExecutivesHandler)
-	mux.HandleFunc("POST /executives", handlers.CreateExecutivesHandler)
-	mux.HandleFunc("PATCH /executives", handlers.PatchExecutivesHandler)
-
-	// Executives route with ID
-	mux.HandleFunc("GET /executives/{id}", handlers.GetOneExecutiveHandler)
-	mux.HandleFunc("PATCH /executives/{id}", handlers.PatchOneExecutiveHandler)
-	mux.HandleFunc("DELETE /executives/{id}", handlers.DeleteOneExecutiveHandler)
-	mux.HandleFunc("POST /executives/{id}/updatepassword", handlers.UpdatePasswordHandler)
-
-	mux.HandleFunc("POST /executives/login", handlers.LoginHandler)
-	mux.HandleFunc("POST /executives/logout", handlers.LogoutHandler)
-	mux.HandleFunc("POST /executives/forgotpassword", handlers.ForgotPasswordHandler)
-	mux.HandleFunc("POST /executives/resetpassword/reset/{resetcode}", handlers.ResetPasswordHandler)
+	// Executives have no PUT or bulk delete
+	h.Executives.Register(mux, "/executives",
+		handlers.OpList, handlers.OpCreate, handlers.OpPatchMany,
+		handlers.OpGet, handlers.OpPatch, handlers.OpDelete)
+	mux.HandleFunc("POST /executives/{id}/updatepassword", h.UpdatePasswordHandler)
+	mux.HandleFunc("POST /executives/{id}/unlock", h.UnlockHandler)
+
+	// Two-factor authentication of the caller, and the roles that require it
+	mux.HandleFunc("POST /executives/{id}/2fa/setup", h.TwoFactorSetupHandler)
+	mux.HandleFunc("POST /executives/{id}/2fa/confirm", h.TwoFactorConfirmHandler)
+	mux.HandleFunc("POST /executives/{id}/2fa/disable", h.TwoFactorDisableHandler)
+	mux.HandleFunc("GET /executives/2fa/policy", h.TwoFactorPolicyHandler)
+	mux.HandleFunc("PUT /executives/2fa/policy", h.UpdateTwoFactorPolicyHandler)
+
+	// API keys of programs calling the API for an executive
+	mux.HandleFunc("GET /executives/{id}/apikeys", h.ListAPIKeysHandler)
+	mux.HandleFunc("POST /executives/{id}/apikeys", h.CreateAPIKeyHandler)
+	mux.HandleFunc("DELETE /executives/{id}/apikeys/{keyid}", h.RevokeAPIKeyHandler)
+
+	mux.HandleFunc("POST /executives/login", h.LoginHandler)
+	mux.HandleFunc("POST /executives/login/2fa", h.LoginTwoFactorHandler)
+	mux.HandleFunc("POST /executives/login/2fa/setup", h.LoginTwoFactorSetupHandler)
+	mux.HandleFunc("POST /executives/refresh", h.RefreshHandler)
+	mux.HandleFunc("POST /executives/logout", h.LogoutHandler)
+	mux.HandleFunc("POST /executives/forgotpassword", h.ForgotPasswordHandler)
+	mux.HandleFunc("POST /executives/resetpassword/reset/{resetcode}", h.ResetPasswordHandler)
+
+	// Public keys verifying the login tokens
+	mux.HandleFunc("GET /.well-known/jwks.json", h.JWKSHandler)
 
 	return mux
 }
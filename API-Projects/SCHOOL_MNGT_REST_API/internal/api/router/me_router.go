@@ -0,0 +1,21 @@
+package router
+
+import (
+	"net/http"
+	"school_management_api/internal/api/handlers"
+)
+
+func meRouter(h *handlers.Handler) *http.ServeMux {
+	// Define the router for the caller's own record, mostly for teachers and students
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /me", h.MeHandler)
+	mux.HandleFunc("PATCH /me", h.UpdateMeHandler)
+	mux.HandleFunc("GET /me/students", h.MyStudentsHandler)
+	mux.HandleFunc("POST /me/password", h.UpdateMyPasswordHandler)
+
+	mux.HandleFunc("POST /me/login", h.AccountLoginHandler)
+	mux.HandleFunc("POST /me/logout", h.LogoutHandler)
+
+	return mux
+}
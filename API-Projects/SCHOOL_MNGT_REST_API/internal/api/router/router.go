@@ -2,16 +2,31 @@ package router
 
 import (
 	"net/http"
+	"school_management_api/internal/api/handlers"
 )
 
 // MainRouter combines all sub-routers into a single main router
-func MainRouter() *http.ServeMux {
+func MainRouter(h *handlers.Handler) *http.ServeMux {
 
-	tRouter := teachersRouter()
-	sRouter := studentsRouter()
-	exRouter := execsRouter()
+	tRouter := teachersRouter(h)
+	sRouter := studentsRouter(h)
+	cRouter := classesRouter(h)
+	suRouter := subjectsRouter(h)
+	enRouter := enrollmentsRouter(h)
+	asRouter := assessmentsRouter(h)
+	atRouter := attendanceRouter(h)
+	exRouter := execsRouter(h)
+	meRouter := meRouter(h)
+	aRouter := auditRouter(h)
 
-	sRouter.Handle("/", exRouter)
+	meRouter.Handle("/", aRouter)
+	exRouter.Handle("/", meRouter)
+	atRouter.Handle("/", exRouter)
+	asRouter.Handle("/", atRouter)
+	enRouter.Handle("/", asRouter)
+	suRouter.Handle("/", enRouter)
+	cRouter.Handle("/", suRouter)
+	sRouter.Handle("/", cRouter)
 	tRouter.Handle("/", sRouter)
 
 	return tRouter
@@ -5,21 +5,17 @@ import (
 	"school_management_api/internal/api/handlers"
 )
 
-func studentsRouter() *http.ServeMux {
+func studentsRouter(h *handlers.Handler) *http.ServeMux {
 	// Define the router for student-related routes
 	mux := http.NewServeMux()
 
-	// Students route
-	mux.HandleFunc("GET /students", handlers.GetStudentsHandler)
-	mux.HandleFunc("POST /students", handlers.CreateStudentsHandler)
-	mux.HandleFunc("PATCH /students", handlers.PatchStudentsHandler)
-	mux.HandleFunc("DELETE /students", handlers.DeleteStudentsHandler)
+	h.Students.Register(mux, "/students", handlers.AllOperations...)
 
-	// Students route with ID
-	mux.HandleFunc("GET /students/{id}", handlers.GetOneStudentHandler)
-	mux.HandleFunc("PUT /students/{id}", handlers.UpdateStudentsHandler)
-	mux.HandleFunc("PATCH /students/{id}", handlers.PatchOneStudentHandler)
-	mux.HandleFunc("DELETE /students/{id}", handlers.DeleteOneStudentHandler)
+	mux.HandleFunc("GET /students/{id}/grades", h.GetStudentGradesHandler)
+	mux.HandleFunc("GET /students/{id}/reportcard", h.GetReportCardHandler)
+
+	mux.HandleFunc("POST /students/{id}/account", h.CreateStudentAccountHandler)
+	mux.HandleFunc("DELETE /students/{id}/account", h.DeleteStudentAccountHandler)
 
 	return mux
 }
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"net/http"
+	"school_management_api/internal/api/handlers"
+)
+
+func subjectsRouter(h *handlers.Handler) *http.ServeMux {
+	// Define the router for subject-related routes
+	mux := http.NewServeMux()
+
+	h.Subjects.Register(mux, "/subjects", handlers.AllOperations...)
+
+	return mux
+}
@@ -5,24 +5,17 @@ import (
 	"school_management_api/internal/api/handlers"
 )
 
-func teachersRouter() *http.ServeMux {
+func teachersRouter(h *handlers.Handler) *http.ServeMux {
 	// Define the router for teacher-related routes
 	mux := http.NewServeMux()
 
-	// Teachers route
-	mux.HandleFunc("GET /teachers", handlers.GetTeachersHandler)
-	mux.HandleFunc("POST /teachers", handlers.CreateTeachersHandler)
-	mux.HandleFunc("PATCH /teachers", handlers.PatchTeachersHandler)
-	mux.HandleFunc("DELETE /teachers", handlers.DeleteTeachersHandler)
+	h.Teachers.Register(mux, "/teachers", handlers.AllOperations...)
 
-	// Teachers route with ID
-	mux.HandleFunc("GET /teachers/{id}", handlers.GetOneTeacherHandler)
-	mux.HandleFunc("PUT /teachers/{id}", handlers.UpdateTeachersHandler)
-	mux.HandleFunc("PATCH /teachers/{id}", handlers.PatchOneTeacherHandler)
-	mux.HandleFunc("DELETE /teachers/{id}", handlers.DeleteOneTeacherHandler)
+	mux.HandleFunc("GET /teachers/{id}/students", h.GetStudentsByTeacherIDHandler)
+	mux.HandleFunc("GET /teachers/{id}/studentcount", h.GetStudentCountByTeacherIDHandler)
 
-	mux.HandleFunc("GET /teachers/{id}/students", handlers.GetStudentsByTeacherIDHandler)
-	mux.HandleFunc("GET /teachers/{id}/studentcount", handlers.GetStudentCountByTeacherIDHandler)
+	mux.HandleFunc("POST /teachers/{id}/account", h.CreateTeacherAccountHandler)
+	mux.HandleFunc("DELETE /teachers/{id}/account", h.DeleteTeacherAccountHandler)
 
 	return mux
 }
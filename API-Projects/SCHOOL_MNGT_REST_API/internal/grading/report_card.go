@@ -0,0 +1,129 @@
+package grading
+
+import (
+	"maps"
+	"math"
+	"school_management_api/internal/models"
+	"slices"
+)
+
+// SubjectResult is the result of a student in one subject of a term.
+// Average and Letter are only set once an assessment of the subject is graded.
+type SubjectResult struct {
+	SubjectID   int      `json:"subject_id"`
+	SubjectName string   `json:"subject_name,omitempty"`
+	Assessments int      `json:"assessments"`
+	Graded      int      `json:"graded"`
+	Average     *float64 `json:"average"`
+	Letter      string   `json:"letter,omitempty"`
+	Points      float64  `json:"points"`
+}
+
+// ReportCard is the result of a student in a term: the weighted average and
+// letter grade of each subject, the overall average and GPA over the graded
+// subjects, and the rank of the student in the class.
+type ReportCard struct {
+	StudentID      int             `json:"student_id"`
+	ClassID        int             `json:"class_id"`
+	AcademicYear   string          `json:"academic_year"`
+	Term           int             `json:"term"`
+	Subjects       []SubjectResult `json:"subjects"`
+	Average        *float64        `json:"average"`
+	GPA            *float64        `json:"gpa"`
+	Rank           int             `json:"rank,omitempty"`
+	RankedStudents int             `json:"ranked_students"`
+}
+
+// weightedSum adds up the weighted scores of a student in a subject.
+type weightedSum struct {
+	score  float64
+	weight float64
+	graded int
+}
+
+// Compute returns the report card of a student from the assessments of a
+// class in a term and every grade given in them. A subject average weighs
+// the graded assessments only, so ungraded ones do not lower it. Students
+// are ranked on their overall average; equal averages share a rank and
+// students without grades are not ranked. The caller sets the class and term.
+func Compute(scale Scale, studentID int, assessments []models.Assessment, grades []models.Grade) ReportCard {
+	byID := make(map[int]models.Assessment, len(assessments))
+	counts := make(map[int]int)
+	for _, assessment := range assessments {
+		byID[assessment.ID] = assessment
+		counts[assessment.SubjectID]++
+	}
+
+	// Weighted scores by student, then subject
+	sums := make(map[int]map[int]*weightedSum)
+	for _, grade := range grades {
+		assessment, ok := byID[grade.AssessmentID]
+		if !ok {
+			continue
+		}
+		if sums[grade.StudentID] == nil {
+			sums[grade.StudentID] = make(map[int]*weightedSum)
+		}
+		sum := sums[grade.StudentID][assessment.SubjectID]
+		if sum == nil {
+			sum = &weightedSum{}
+			sums[grade.StudentID][assessment.SubjectID] = sum
+		}
+		sum.score += assessment.Weight * grade.Score / assessment.MaxScore * 100
+		sum.weight += assessment.Weight
+		sum.graded++
+	}
+
+	card := ReportCard{StudentID: studentID, Subjects: []SubjectResult{}}
+	for _, subjectID := range slices.Sorted(maps.Keys(counts)) {
+		result := SubjectResult{SubjectID: subjectID, Assessments: counts[subjectID]}
+		if sum := sums[studentID][subjectID]; sum != nil {
+			average := round(sum.score / sum.weight)
+			band := scale.Grade(average)
+			result.Graded = sum.graded
+			result.Average = &average
+			result.Letter = band.Letter
+			result.Points = band.Points
+		}
+		card.Subjects = append(card.Subjects, result)
+	}
+
+	var total, points float64
+	var graded int
+	for _, result := range card.Subjects {
+		if result.Average != nil {
+			total += *result.Average
+			points += result.Points
+			graded++
+		}
+	}
+	card.RankedStudents = len(sums)
+	if graded == 0 {
+		return card
+	}
+	average, gpa := round(total/float64(graded)), round(points/float64(graded))
+	card.Average, card.GPA = &average, &gpa
+
+	// Rank against the overall averages of the class
+	card.Rank = 1
+	for otherID, subjects := range sums {
+		if otherID != studentID && overallAverage(subjects) > average {
+			card.Rank++
+		}
+	}
+	return card
+}
+
+// overallAverage returns the mean of the subject averages of a student.
+func overallAverage(subjects map[int]*weightedSum) float64 {
+	var total float64
+	for _, sum := range subjects {
+		total += round(sum.score / sum.weight)
+	}
+	return round(total / float64(len(subjects)))
+}
+
+// round rounds to two decimals, as the averages are reported.
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
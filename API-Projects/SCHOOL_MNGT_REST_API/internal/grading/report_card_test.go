@@ -0,0 +1,106 @@
+package grading
+
+import (
+	"reflect"
+	"school_management_api/internal/models"
+	"testing"
+)
+
+var testScale = Scale{Bands: []Band{
+	{Min: 0, Letter: "F", Points: 0},
+	{Min: 50, Letter: "D", Points: 1},
+	{Min: 60, Letter: "C", Points: 2},
+	{Min: 70, Letter: "B", Points: 3},
+	{Min: 80, Letter: "A", Points: 4},
+}}
+
+func TestComputeRanking(t *testing.T) {
+	assessments := []models.Assessment{
+		{ID: 1, SubjectID: 1, Weight: 1, MaxScore: 100},
+		{ID: 2, SubjectID: 1, Weight: 3, MaxScore: 50},
+		{ID: 3, SubjectID: 2, Weight: 1, MaxScore: 20},
+	}
+	grades := []models.Grade{
+		// 87.5 and 75, overall 81.25
+		{AssessmentID: 1, StudentID: 10, Score: 80},
+		{AssessmentID: 2, StudentID: 10, Score: 45},
+		{AssessmentID: 3, StudentID: 10, Score: 15},
+		// 100 and 50, overall 75
+		{AssessmentID: 1, StudentID: 11, Score: 100},
+		{AssessmentID: 2, StudentID: 11, Score: 50},
+		{AssessmentID: 3, StudentID: 11, Score: 10},
+		// 72.5 and 90, overall 81.25 like student 10
+		{AssessmentID: 1, StudentID: 12, Score: 50},
+		{AssessmentID: 2, StudentID: 12, Score: 40},
+		{AssessmentID: 3, StudentID: 12, Score: 18},
+		// Only subject 2 is graded, overall 100
+		{AssessmentID: 3, StudentID: 14, Score: 20},
+		// Grades of other assessments are ignored
+		{AssessmentID: 99, StudentID: 11, Score: 0},
+		{AssessmentID: 99, StudentID: 15, Score: 0},
+	}
+
+	tests := []struct {
+		name        string
+		studentID   int
+		wantRank    int
+		wantAverage float64
+		wantGPA     float64
+		ungraded    bool
+	}{
+		{name: "best average", studentID: 14, wantRank: 1, wantAverage: 100, wantGPA: 4},
+		{name: "tie", studentID: 10, wantRank: 2, wantAverage: 81.25, wantGPA: 3.5},
+		{name: "other side of the tie", studentID: 12, wantRank: 2, wantAverage: 81.25, wantGPA: 3.5},
+		{name: "after the tie", studentID: 11, wantRank: 4, wantAverage: 75, wantGPA: 2.5},
+		{name: "no grades", studentID: 13, ungraded: true},
+		{name: "grades of other assessments only", studentID: 15, ungraded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := Compute(testScale, tt.studentID, assessments, grades)
+
+			if card.RankedStudents != 4 {
+				t.Errorf("RankedStudents = %d, want 4", card.RankedStudents)
+			}
+			if card.Rank != tt.wantRank {
+				t.Errorf("Rank = %d, want %d", card.Rank, tt.wantRank)
+			}
+			if tt.ungraded {
+				if card.Average != nil || card.GPA != nil {
+					t.Errorf("Average = %v, GPA = %v, want none", card.Average, card.GPA)
+				}
+				return
+			}
+			if card.Average == nil || *card.Average != tt.wantAverage {
+				t.Errorf("Average = %v, want %v", card.Average, tt.wantAverage)
+			}
+			if card.GPA == nil || *card.GPA != tt.wantGPA {
+				t.Errorf("GPA = %v, want %v", card.GPA, tt.wantGPA)
+			}
+		})
+	}
+}
+
+func TestComputeSubjects(t *testing.T) {
+	assessments := []models.Assessment{
+		{ID: 1, SubjectID: 2, Weight: 1, MaxScore: 100},
+		{ID: 2, SubjectID: 1, Weight: 1, MaxScore: 100},
+		{ID: 3, SubjectID: 1, Weight: 1, MaxScore: 100},
+	}
+	grades := []models.Grade{
+		{AssessmentID: 2, StudentID: 10, Score: 64.444},
+	}
+
+	card := Compute(testScale, 10, assessments, grades)
+
+	// An ungraded assessment does not lower the subject average
+	average := 64.44
+	want := []SubjectResult{
+		{SubjectID: 1, Assessments: 2, Graded: 1, Average: &average, Letter: "C", Points: 2},
+		{SubjectID: 2, Assessments: 1},
+	}
+	if !reflect.DeepEqual(card.Subjects, want) {
+		t.Errorf("Subjects = %+v, want %+v", card.Subjects, want)
+	}
+}
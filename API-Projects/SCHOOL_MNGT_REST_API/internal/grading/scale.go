@@ -0,0 +1,86 @@
+package grading
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Band is one step of a grading scale: averages of at least Min percent,
+// up to the next band, get Letter and count Points towards the GPA.
+type Band struct {
+	Min    float64 `json:"min"`
+	Letter string  `json:"letter"`
+	Points float64 `json:"points"`
+}
+
+// Scale maps subject averages to letter grades, e.g. grading_scale.json.
+type Scale struct {
+	Bands []Band `json:"bands"`
+}
+
+// LoadScale reads and checks a grading scale from a JSON file. The bands
+// may be listed in any order.
+func LoadScale(path string) (Scale, error) {
+	var scale Scale
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return scale, err
+	}
+	if err := json.Unmarshal(data, &scale); err != nil {
+		return scale, fmt.Errorf("invalid grading scale %s: %w", path, err)
+	}
+	if err := scale.Validate(); err != nil {
+		return scale, fmt.Errorf("invalid grading scale %s: %w", path, err)
+	}
+	return scale, nil
+}
+
+// Validate checks that every band has a letter and a minimum from 0 to 100,
+// that no two bands share a minimum or a letter, and that a band starts
+// at 0 so every average gets a letter.
+func (s Scale) Validate() error {
+	if len(s.Bands) == 0 {
+		return errors.New("at least one band is required")
+	}
+
+	minimums := make(map[float64]bool)
+	letters := make(map[string]bool)
+	for _, band := range s.Bands {
+		switch {
+		case band.Letter == "":
+			return fmt.Errorf("band from %g: letter is required", band.Min)
+		case band.Min < 0 || band.Min > 100:
+			return fmt.Errorf("band %s: min must be from 0 to 100", band.Letter)
+		case band.Points < 0:
+			return fmt.Errorf("band %s: points must not be negative", band.Letter)
+		case minimums[band.Min]:
+			return fmt.Errorf("band %s: another band starts at %g", band.Letter, band.Min)
+		case letters[band.Letter]:
+			return fmt.Errorf("band %s: letter is used twice", band.Letter)
+		}
+		minimums[band.Min] = true
+		letters[band.Letter] = true
+	}
+	if !minimums[0] {
+		return errors.New("a band must start at 0")
+	}
+	return nil
+}
+
+// Grade returns the band of an average, in percent.
+func (s Scale) Grade(average float64) Band {
+	bands := slices.SortedFunc(slices.Values(s.Bands), func(a, b Band) int {
+		return cmp.Compare(b.Min, a.Min)
+	})
+	for _, band := range bands {
+		if average >= band.Min {
+			return band
+		}
+	}
+	return bands[len(bands)-1]
+}
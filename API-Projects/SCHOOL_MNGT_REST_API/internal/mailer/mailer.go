@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails, e.g. password reset links.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// FromEnv creates the mailer selected with MAILER:
+//   - "smtp": sends through the server set by SMTP_HOST, SMTP_PORT (default 587),
+//     SMTP_USERNAME and SMTP_PASSWORD, from the address in MAIL_FROM
+//   - "log" (default): writes the emails to MAIL_LOG_FILE, or to stdout when
+//     it is not set, for local development
+func FromEnv() (Mailer, error) {
+	switch driver := os.Getenv("MAILER"); driver {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return NewSMTPMailer(os.Getenv("SMTP_HOST"), port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("MAIL_FROM"))
+	case "log", "":
+		path := os.Getenv("MAIL_LOG_FILE")
+		if path == "" {
+			return NewLogMailer(os.Stdout), nil
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open mail log file: %w", err)
+		}
+		return NewLogMailer(file), nil
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q", driver)
+	}
+}
+
+// checkHeaders rejects header values that would inject extra headers.
+func checkHeaders(values ...string) error {
+	for _, value := range values {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid mail header value %q", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import "database/sql"
+
+// Subject types of the tokens, in the subject_type claim. Executives log in
+// with their execs row; teachers and students with an Account.
+const (
+	SubjectExecutive = "executive"
+	SubjectTeacher   = "teacher"
+	SubjectStudent   = "student"
+)
+
+// AccountRoles are the roles of teacher and student tokens, named after the
+// subject type. Only routes that list them accept those tokens.
+var AccountRoles = []string{SubjectTeacher, SubjectStudent}
+
+// Account is the login of a teacher or a student. SubjectType says which
+// and SubjectID is the ID of their teachers or students row.
+type Account struct {
+	ID                int            `json:"id"`
+	SubjectType       string         `json:"subject_type"`
+	SubjectID         int            `json:"subject_id"`
+	Username          string         `json:"username"`
+	Password          string         `json:"-"`
+	PasswordChangedAt sql.NullString `json:"-"`
+	InactiveStatus    bool           `json:"inactive_status"`
+	CreatedAt         string         `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// SubjectAPIKey is the subject type of requests authenticated with an API key.
+const SubjectAPIKey = "api_key"
+
+// APIKeyScopes are the scopes an API key can be given. Each allows the
+// routes of the access policy that list it.
+var APIKeyScopes = []string{"students:read", "students:write", "teachers:read", "teachers:write", "classes:read", "classes:write",
+	"subjects:read", "subjects:write", "enrollments:read", "enrollments:write",
+	"grades:read", "grades:write", "attendance:read", "attendance:write"}
+
+// APIKey lets a program call the API on behalf of an executive, within the
+// scopes of the key and the role of the executive. Only the hash of the
+// key is stored; Prefix is its start, to tell keys apart.
+type APIKey struct {
+	ID          int        `json:"id"`
+	ExecutiveID int        `json:"executive_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Assessment is a graded piece of work of a class in a subject, e.g. a test,
+// in one term. Weight is its share of the subject average of the term,
+// relative to the weights of the other assessments in the subject.
+type Assessment struct {
+	ID           int     `json:"id,omitempty" db:"id"`
+	Name         string  `json:"name,omitempty" db:"name" validate:"required,max=100"`
+	SubjectID    int     `json:"subject_id,omitempty" db:"subject_id" validate:"required,min=1"`
+	ClassID      int     `json:"class_id,omitempty" db:"class_id" validate:"required,min=1"`
+	Weight       float64 `json:"weight,omitempty" db:"weight" validate:"required,gt=0,max=100"`
+	MaxScore     float64 `json:"max_score,omitempty" db:"max_score" validate:"required,gt=0,max=1000"`
+	Date         string  `json:"date,omitempty" db:"date" validate:"required,datetime=2006-01-02"`
+	AcademicYear string  `json:"academic_year,omitempty" db:"academic_year" validate:"required,academicyear"`
+	Term         int     `json:"term,omitempty" db:"term" validate:"required,min=1,max=3"`
+}
+
+// Grade is the score of a student in an assessment, from 0 to the max
+// score of the assessment. A student has one grade per assessment.
+type Grade struct {
+	ID           int     `json:"id,omitempty" db:"id"`
+	AssessmentID int     `json:"assessment_id,omitempty" db:"assessment_id"`
+	StudentID    int     `json:"student_id,omitempty" db:"student_id" validate:"required,min=1"`
+	Score        float64 `json:"score" db:"score" validate:"min=0"`
+}
+
+// StudentGrade is a grade of a student together with its assessment.
+type StudentGrade struct {
+	Assessment Assessment `json:"assessment"`
+	Score      float64    `json:"score"`
+}
@@ -0,0 +1,60 @@
+package models
+
+// Attendance statuses. Late students count as attending; excused absences
+// do not count against the attendance rate.
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceLate    = "late"
+	AttendanceExcused = "excused"
+)
+
+// AttendanceStatuses are the valid statuses of attendance records.
+var AttendanceStatuses = []string{AttendancePresent, AttendanceAbsent, AttendanceLate, AttendanceExcused}
+
+// Attendance is the attendance of a student in their class on one day,
+// either for a period of the timetable or, with period 0, for the whole
+// day. A student has one record per date and period.
+type Attendance struct {
+	ID        int    `json:"id,omitempty" db:"id"`
+	StudentID int    `json:"student_id,omitempty" db:"student_id" validate:"required,min=1"`
+	ClassID   int    `json:"class_id,omitempty" db:"class_id" validate:"required,min=1"`
+	Date      string `json:"date,omitempty" db:"date" validate:"required,datetime=2006-01-02"`
+	Period    int    `json:"period" db:"period" validate:"min=0,max=12"`
+	Status    string `json:"status,omitempty" db:"status" validate:"required,attendancestatus"`
+	Note      string `json:"note" db:"note" validate:"max=500"`
+}
+
+// Roll is the attendance of a whole class taken in one call. Students of
+// the class missing from Records get DefaultStatus, e.g. present, so only
+// the exceptions need to be listed; without a default every student of
+// the class must be listed.
+type Roll struct {
+	Date          string       `json:"date" validate:"required,datetime=2006-01-02"`
+	Period        int          `json:"period" validate:"min=0,max=12"`
+	DefaultStatus string       `json:"default_status" validate:"omitempty,attendancestatus"`
+	Records       []RollRecord `json:"records" validate:"dive"`
+}
+
+// RollRecord is the status of one student in a roll.
+type RollRecord struct {
+	StudentID int    `json:"student_id" validate:"required,min=1"`
+	Status    string `json:"status" validate:"required,attendancestatus"`
+	Note      string `json:"note" validate:"max=500"`
+}
+
+// AttendanceSummary counts the attendance records of a student, or of a
+// whole class, over a date range. Rate is the percentage of records, not
+// counting excused ones, in which the students attended; it is null when
+// there are none.
+type AttendanceSummary struct {
+	StudentID int      `json:"student_id,omitempty"`
+	ClassID   int      `json:"class_id"`
+	Students  int      `json:"students,omitempty"`
+	Present   int      `json:"present"`
+	Absent    int      `json:"absent"`
+	Late      int      `json:"late"`
+	Excused   int      `json:"excused"`
+	Total     int      `json:"total"`
+	Rate      *float64 `json:"attendance_rate"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Actions recorded in the audit log.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// SubjectSystem is the actor type of writes made outside a request, e.g.
+// by the seeder.
+const SubjectSystem = "system"
+
+// AuditRedacted replaces the values of sensitive fields, e.g. password
+// hashes, in the audit log.
+const AuditRedacted = "[REDACTED]"
+
+// AuditChange is the value of a field before and after a write. Before is
+// null for creates and After for deletes.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEntry records one create, update or delete of a record, e.g. a
+// student, an executive or an API key: who made it, in which request, and the fields it changed,
+// keyed by JSON name. ActorID is the executive owning the key for API
+// keys and 0 for the system.
+type AuditEntry struct {
+	ID        int                    `json:"id" db:"id"`
+	ActorType string                 `json:"actor_type" db:"actor_type"`
+	ActorID   int                    `json:"actor_id" db:"actor_id"`
+	ActorName string                 `json:"actor_name" db:"actor_name"`
+	Action    string                 `json:"action" db:"action"`
+	Entity    string                 `json:"entity" db:"entity"`
+	EntityID  int                    `json:"entity_id" db:"entity_id"`
+	Changes   map[string]AuditChange `json:"changes" db:"changes"`
+	RequestID string                 `json:"request_id,omitempty" db:"request_id"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
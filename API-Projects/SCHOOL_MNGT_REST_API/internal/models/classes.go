@@ -0,0 +1,13 @@
+package models
+
+// Class is a class of students in one academic year, e.g. 10A of 2026-2027.
+// Teachers are assigned to classes separately; the homeroom teacher is the
+// one responsible for the class and need not be assigned to it.
+type Class struct {
+	ID                int    `json:"id,omitempty" db:"id"`
+	Name              string `json:"name,omitempty" db:"name" validate:"required,classcode"`
+	GradeLevel        int    `json:"grade_level,omitempty" db:"grade_level" validate:"required,min=1,max=12"`
+	Capacity          int    `json:"capacity,omitempty" db:"capacity" validate:"required,min=1,max=500"`
+	HomeroomTeacherID *int   `json:"homeroom_teacher_id" db:"homeroom_teacher_id" validate:"omitempty,min=1"`
+	AcademicYear      string `json:"academic_year,omitempty" db:"academic_year" validate:"required,academicyear"`
+}
@@ -2,17 +2,26 @@ package models
 
 import "database/sql"
 
+// ExecutiveRoles are the roles an executive can be given.
+var ExecutiveRoles = []string{"admin", "manager", "office assistant"}
+
 type Executive struct {
 	ID                   int            `json:"id,omitempty" db:"id,omitempty"`
-	FirstName            string         `json:"first_name,omitempty" db:"first_name,omitempty"`
-	LastName             string         `json:"last_name,omitempty" db:"last_name,omitempty"`
-	Email                string         `json:"email,omitempty" db:"email,omitempty"`
-	Username             string         `json:"username,omitempty" db:"username,omitempty"`
-	Password             string         `json:"password,omitempty" db:"password,omitempty"`
-	PasswordChangedAt    sql.NullString `json:"password_changed_at,omitempty" db:"password_changed_at,omitempty"`
+	FirstName            string         `json:"first_name,omitempty" db:"first_name,omitempty" validate:"required,max=255"`
+	LastName             string         `json:"last_name,omitempty" db:"last_name,omitempty" validate:"required,max=255"`
+	Email                string         `json:"email,omitempty" db:"email,omitempty" validate:"required,email,max=255"`
+	Username             string         `json:"username,omitempty" db:"username,omitempty" validate:"required,alphanum,min=3,max=50"`
+	Password             string         `json:"password,omitempty" db:"password,omitempty" query:"-" validate:"required,strongpassword"`
+	PasswordChangedAt    sql.NullString `json:"password_changed_at,omitempty" db:"password_changed_at,omitempty" query:"-"`
 	UserCreatedAt        sql.NullString `json:"user_created_at,omitempty" db:"user_created_at,omitempty"`
-	PasswordResetToken   sql.NullString `json:"password_reset_token,omitempty" db:"password_reset_token,omitempty"`
-	PasswordTokenExpires sql.NullString `json:"password_token_expires,omitempty" db:"password_token_expires,omitempty"`
+	PasswordResetToken   sql.NullString `json:"password_reset_token,omitempty" db:"password_reset_token,omitempty" query:"-"`
+	PasswordTokenExpires sql.NullString `json:"password_token_expires,omitempty" db:"password_token_expires,omitempty" query:"-"`
 	InactiveStatus       bool           `json:"inactive_status,omitempty" db:"inactive_status,omitempty"`
-	Role                 string         `json:"role,omitempty" db:"role,omitempty"`
+	Role                 string         `json:"role,omitempty" db:"role,omitempty" validate:"required,role"`
+	// TOTPSecret is set from the start of a two-factor setup; TOTPEnabled
+	// once the setup is confirmed with a first code.
+	TOTPSecret  sql.NullString `json:"-" db:"totp_secret,omitempty" query:"-"`
+	TOTPEnabled bool           `json:"-" db:"totp_enabled,omitempty" query:"-"`
+	// TOTPLastStep is the time step of the last accepted code, so a code works only once.
+	TOTPLastStep sql.NullInt64 `json:"-" db:"totp_last_step,omitempty" query:"-"`
 }
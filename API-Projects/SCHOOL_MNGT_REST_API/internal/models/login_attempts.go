@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// LoginAttempts counts the failed logins of one key, a username or a client
+// IP, since the count was last reset.
+type LoginAttempts struct {
+	Key         string
+	Failures    int
+	LastFailure time.Time
+	// LockedUntil is zero, or in the past, unless logins of the key are blocked.
+	LockedUntil time.Time
+}
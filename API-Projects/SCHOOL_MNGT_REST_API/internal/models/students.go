@@ -2,8 +2,8 @@ package models
 
 type Student struct {
 	ID        int    `json:"id,omitempty" db:"id"`
-	FirstName string `json:"first_name,omitempty" db:"first_name"`
-	LastName  string `json:"last_name,omitempty" db:"last_name"`
-	Email     string `json:"email,omitempty" db:"email"`
-	Class     string `json:"class,omitempty" db:"class"`
+	FirstName string `json:"first_name,omitempty" db:"first_name" validate:"required,max=255"`
+	LastName  string `json:"last_name,omitempty" db:"last_name" validate:"required,max=255"`
+	Email     string `json:"email,omitempty" db:"email" validate:"required,email,max=255"`
+	ClassID   int    `json:"class_id,omitempty" db:"class_id" validate:"required,min=1"`
 }
@@ -0,0 +1,21 @@
+package models
+
+// Subject is a subject taught at the school. Capacity is the most students
+// one teacher takes in the subject in a term.
+type Subject struct {
+	ID          int    `json:"id,omitempty" db:"id"`
+	Name        string `json:"name,omitempty" db:"name" validate:"required,max=100"`
+	Description string `json:"description,omitempty" db:"description" validate:"max=500"`
+	Capacity    int    `json:"capacity,omitempty" db:"capacity" validate:"required,min=1,max=500"`
+}
+
+// Enrollment records that a student takes a subject with a teacher in one
+// term of an academic year. A student takes a subject once per term.
+type Enrollment struct {
+	ID           int    `json:"id,omitempty" db:"id"`
+	StudentID    int    `json:"student_id,omitempty" db:"student_id" validate:"required,min=1"`
+	SubjectID    int    `json:"subject_id,omitempty" db:"subject_id" validate:"required,min=1"`
+	TeacherID    int    `json:"teacher_id,omitempty" db:"teacher_id" validate:"required,min=1"`
+	AcademicYear string `json:"academic_year,omitempty" db:"academic_year" validate:"required,academicyear"`
+	Term         int    `json:"term,omitempty" db:"term" validate:"required,min=1,max=3"`
+}
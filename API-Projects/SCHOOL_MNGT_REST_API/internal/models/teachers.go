@@ -2,9 +2,8 @@ package models
 
 type Teacher struct {
 	ID        int    `json:"id,omitempty" db:"id, omitempty"`
-	FirstName string `json:"first_name,omitempty" db:"first_name, omitempty"`
-	LastName  string `json:"last_name,omitempty" db:"last_name, omitempty"`
-	Email     string `json:"email,omitempty" db:"email, omitempty"`
-	Class     string `json:"class,omitempty" db:"class, omitempty"`
-	Subject   string `json:"subject,omitempty" db:"subject, omitempty"`
+	FirstName string `json:"first_name,omitempty" db:"first_name, omitempty" validate:"required,max=255"`
+	LastName  string `json:"last_name,omitempty" db:"last_name, omitempty" validate:"required,max=255"`
+	Email     string `json:"email,omitempty" db:"email, omitempty" validate:"required,email,max=255"`
+	Subject   string `json:"subject,omitempty" db:"subject, omitempty" validate:"required,max=255"`
 }
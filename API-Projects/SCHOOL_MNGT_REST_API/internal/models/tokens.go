@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RefreshToken is a refresh token issued to an executive. Only the hash of
+// the token is stored. Every token rotated from the same login shares its
+// FamilyID, so reuse of a rotated token can revoke the whole login.
+type RefreshToken struct {
+	ID          int
+	TokenHash   string
+	FamilyID    string
+	ExecutiveID int
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	// RevokedAt is zero until the token is rotated or revoked.
+	RevokedAt time.Time
+}
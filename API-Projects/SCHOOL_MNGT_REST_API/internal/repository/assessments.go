@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+)
+
+// CheckGrades returns a Validation error listing the grades that cannot be
+// given in an assessment: students listed twice, students outside the class
+// of the assessment and scores above its max score. classOf returns the
+// class of a student, or false when there is no such student.
+func CheckGrades(assessment models.Assessment, grades []models.Grade, classOf func(studentID int) (int, bool, error)) error {
+	var fieldErrors []utils.FieldError
+	fail := func(i int, field, rule, message string) {
+		fieldErrors = append(fieldErrors, utils.FieldError{Index: &i, Field: field, Rule: rule, Message: message})
+	}
+
+	seen := make(map[int]bool)
+	for i, grade := range grades {
+		if grade.Score > assessment.MaxScore {
+			fail(i, "score", "max", fmt.Sprintf("score must be at most %g, the max score of the assessment", assessment.MaxScore))
+		}
+
+		if seen[grade.StudentID] {
+			fail(i, "student_id", "unique", fmt.Sprintf("student %d is listed more than once", grade.StudentID))
+			continue
+		}
+		seen[grade.StudentID] = true
+
+		classID, ok, err := classOf(grade.StudentID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !ok:
+			fail(i, "student_id", "exists", fmt.Sprintf("student %d does not exist", grade.StudentID))
+		case classID != assessment.ClassID:
+			fail(i, "student_id", "class", fmt.Sprintf("student %d is not in class %d of the assessment", grade.StudentID, assessment.ClassID))
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return utils.ValidationError(nil, "validation failed").WithDetails(fieldErrors)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"slices"
+)
+
+// AttendanceFilter selects the attendance records of a report: those of a
+// class, when ClassID is not 0, dated from From to To. Empty dates leave
+// the range open.
+type AttendanceFilter struct {
+	ClassID int
+	From    string
+	To      string
+}
+
+// Match reports whether a record is selected by the filter. Dates are
+// formatted as 2006-01-02, so they compare as strings.
+func (f AttendanceFilter) Match(record models.Attendance) bool {
+	return (f.ClassID == 0 || record.ClassID == f.ClassID) &&
+		(f.From == "" || record.Date >= f.From) &&
+		(f.To == "" || record.Date <= f.To)
+}
+
+// RollRecords returns the attendance records of a roll taken in a class
+// with the given students, ordered by student. It returns a Validation
+// error listing students listed twice, students outside the class and,
+// when the roll has no default status, students of the class left out.
+func RollRecords(classID int, roll models.Roll, studentIDs []int) ([]models.Attendance, error) {
+	var fieldErrors []utils.FieldError
+	fail := func(index *int, field, rule, message string) {
+		fieldErrors = append(fieldErrors, utils.FieldError{Index: index, Field: field, Rule: rule, Message: message})
+	}
+
+	records := make([]models.Attendance, 0, len(studentIDs))
+	listed := make(map[int]bool)
+	for i, record := range roll.Records {
+		switch {
+		case listed[record.StudentID]:
+			fail(&i, "student_id", "unique", fmt.Sprintf("student %d is listed more than once", record.StudentID))
+			continue
+		case !slices.Contains(studentIDs, record.StudentID):
+			fail(&i, "student_id", "class", fmt.Sprintf("student %d is not in class %d", record.StudentID, classID))
+		}
+		listed[record.StudentID] = true
+		records = append(records, models.Attendance{StudentID: record.StudentID, Status: record.Status, Note: record.Note})
+	}
+
+	for _, studentID := range studentIDs {
+		switch {
+		case listed[studentID]:
+		case roll.DefaultStatus == "":
+			fail(nil, "records", "required", fmt.Sprintf("student %d of the class is not listed and there is no default_status", studentID))
+		default:
+			records = append(records, models.Attendance{StudentID: studentID, Status: roll.DefaultStatus})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, utils.ValidationError(nil, "validation failed").WithDetails(fieldErrors)
+	}
+
+	for i := range records {
+		records[i].ClassID, records[i].Date, records[i].Period = classID, roll.Date, roll.Period
+	}
+	slices.SortFunc(records, func(a, b models.Attendance) int { return cmp.Compare(a.StudentID, b.StudentID) })
+	return records, nil
+}
+
+// CountAttendance adds a record with the given status to the summary.
+func CountAttendance(summary *models.AttendanceSummary, status string) {
+	switch status {
+	case models.AttendancePresent:
+		summary.Present++
+	case models.AttendanceAbsent:
+		summary.Absent++
+	case models.AttendanceLate:
+		summary.Late++
+	case models.AttendanceExcused:
+		summary.Excused++
+	}
+	summary.Total++
+}
+
+// SetAttendanceRate sets the rate of a summary from its counts: the
+// percentage of records, excused ones aside, in which the student was
+// present or late, rounded to 2 decimals.
+func SetAttendanceRate(summary *models.AttendanceSummary) {
+	summary.Rate = nil
+	if counted := summary.Total - summary.Excused; counted > 0 {
+		rate := math.Round(float64(summary.Present+summary.Late)/float64(counted)*10000) / 100
+		summary.Rate = &rate
+	}
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"testing"
+)
+
+// fieldErrorKey describes a field error by its index, field and rule,
+// e.g. "1 student_id unique", or "- records required" without index.
+func fieldErrorKey(fieldError utils.FieldError) string {
+	index := "-"
+	if fieldError.Index != nil {
+		index = fmt.Sprint(*fieldError.Index)
+	}
+	return fmt.Sprintf("%s %s %s", index, fieldError.Field, fieldError.Rule)
+}
+
+func TestRollRecords(t *testing.T) {
+	const classID = 2
+	students := []int{3, 5, 8}
+	record := func(studentID int, status, note string) models.Attendance {
+		return models.Attendance{StudentID: studentID, ClassID: classID, Date: "2025-03-03", Period: 1, Status: status, Note: note}
+	}
+
+	tests := []struct {
+		name          string
+		defaultStatus string
+		records       []models.RollRecord
+		students      []int
+		want          []models.Attendance
+		wantErrors    []string
+	}{
+		{
+			name:          "default status for the students left out",
+			defaultStatus: models.AttendancePresent,
+			records:       []models.RollRecord{{StudentID: 5, Status: models.AttendanceAbsent, Note: "sick"}},
+			students:      students,
+			want: []models.Attendance{
+				record(3, models.AttendancePresent, ""),
+				record(5, models.AttendanceAbsent, "sick"),
+				record(8, models.AttendancePresent, ""),
+			},
+		},
+		{
+			name: "every student listed, ordered by student",
+			records: []models.RollRecord{
+				{StudentID: 8, Status: models.AttendanceLate},
+				{StudentID: 3, Status: models.AttendancePresent},
+				{StudentID: 5, Status: models.AttendanceExcused},
+			},
+			students: students,
+			want: []models.Attendance{
+				record(3, models.AttendancePresent, ""),
+				record(5, models.AttendanceExcused, ""),
+				record(8, models.AttendanceLate, ""),
+			},
+		},
+		{
+			name:     "empty class",
+			students: nil,
+			want:     []models.Attendance{},
+		},
+		{
+			name:       "students left out without default status",
+			records:    []models.RollRecord{{StudentID: 3, Status: models.AttendancePresent}},
+			students:   students,
+			wantErrors: []string{"- records required", "- records required"},
+		},
+		{
+			name:          "student listed twice",
+			defaultStatus: models.AttendancePresent,
+			records: []models.RollRecord{
+				{StudentID: 3, Status: models.AttendancePresent},
+				{StudentID: 3, Status: models.AttendanceAbsent},
+			},
+			students:   students,
+			wantErrors: []string{"1 student_id unique"},
+		},
+		{
+			name:          "student outside the class",
+			defaultStatus: models.AttendancePresent,
+			records:       []models.RollRecord{{StudentID: 9, Status: models.AttendanceAbsent}},
+			students:      students,
+			wantErrors:    []string{"0 student_id class"},
+		},
+		{
+			name: "every problem at once",
+			records: []models.RollRecord{
+				{StudentID: 9, Status: models.AttendanceAbsent},
+				{StudentID: 9, Status: models.AttendanceAbsent},
+				{StudentID: 3, Status: models.AttendancePresent},
+			},
+			students:   students,
+			wantErrors: []string{"0 student_id class", "1 student_id unique", "- records required", "- records required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roll := models.Roll{Date: "2025-03-03", Period: 1, DefaultStatus: tt.defaultStatus, Records: tt.records}
+			got, err := RollRecords(classID, roll, tt.students)
+
+			if tt.wantErrors == nil {
+				if err != nil {
+					t.Fatalf("RollRecords() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("RollRecords() = %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var appErr *utils.AppError
+			if !errors.As(err, &appErr) || appErr.Code != utils.CodeValidation {
+				t.Fatalf("RollRecords() error = %v, want a validation error", err)
+			}
+			fieldErrors, _ := appErr.Details.([]utils.FieldError)
+			var keys []string
+			for _, fieldError := range fieldErrors {
+				keys = append(keys, fieldErrorKey(fieldError))
+			}
+			if !reflect.DeepEqual(keys, tt.wantErrors) {
+				t.Errorf("field errors = %q, want %q", keys, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Entities of the audit log without an Entity definition.
+const (
+	AuditAccount = "account"
+	AuditAPIKey  = "api_key"
+)
+
+// Actor is who makes a write, recorded in the audit log.
+type Actor struct {
+	// Type is the subject type of the caller, e.g. models.SubjectExecutive.
+	Type string
+	ID   int
+	Name string
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the actor of the writes made with it.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor, or the system
+// when there is none, e.g. while seeding.
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: models.SubjectSystem, Name: models.SubjectSystem}
+}
+
+// AuditFilter selects audit entries. Zero fields match every entry; From
+// and To bound the time of the write, To excluded.
+type AuditFilter struct {
+	Entity    string
+	EntityID  int
+	Action    string
+	ActorType string
+	ActorID   int
+	ActorName string
+	From      time.Time
+	To        time.Time
+}
+
+// AuditSort is the order of the audit log, newest first.
+var AuditSort = []utils.SortField{{Column: "id", Desc: true}}
+
+// AuditRepository reads the audit log. Entries are written by the other
+// repositories along with each change, including password, two-factor,
+// account and API key changes.
+type AuditRepository interface {
+	// ListAudit returns one page of the entries matching filter, newest
+	// first, along with the total number of matching entries.
+	ListAudit(filter AuditFilter, page utils.Pagination) ([]models.AuditEntry, int, error)
+}
+
+// Match reports whether entry is selected by the filter.
+func (f AuditFilter) Match(entry models.AuditEntry) bool {
+	switch {
+	case f.Entity != "" && entry.Entity != f.Entity,
+		f.EntityID != 0 && entry.EntityID != f.EntityID,
+		f.Action != "" && entry.Action != f.Action,
+		f.ActorType != "" && entry.ActorType != f.ActorType,
+		f.ActorID != 0 && entry.ActorID != f.ActorID,
+		f.ActorName != "" && entry.ActorName != f.ActorName,
+		!f.From.IsZero() && entry.CreatedAt.Before(f.From),
+		!f.To.IsZero() && !entry.CreatedAt.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// NewAuditEntry returns the audit entry of a write to the given entity by
+// the actor of ctx.
+func NewAuditEntry(ctx context.Context, entity string, action string, id int, changes map[string]models.AuditChange, now time.Time) models.AuditEntry {
+	actor := ActorFromContext(ctx)
+	return models.AuditEntry{
+		ActorType: actor.Type,
+		ActorID:   actor.ID,
+		ActorName: actor.Name,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  id,
+		Changes:   changes,
+		RequestID: utils.RequestIDFromContext(ctx),
+		CreatedAt: now.UTC().Truncate(time.Second),
+	}
+}
+
+// AuditEntry returns the audit entry of a write to the item with the given
+// ID by the actor of ctx. Before is nil for creates and after for deletes.
+func (e Entity[T]) AuditEntry(ctx context.Context, action string, id int, before, after *T, now time.Time) models.AuditEntry {
+	return NewAuditEntry(ctx, e.Name, action, id, e.AuditChanges(before, after), now)
+}
+
+// ClassTeachersAuditEntry returns the audit entry of a change to the
+// teachers assigned to a class, recorded as an update of its teacher_ids.
+func ClassTeachersAuditEntry(ctx context.Context, classID int, before, after []int, now time.Time) models.AuditEntry {
+	changes := map[string]models.AuditChange{"teacher_ids": {Before: before, After: after}}
+	return NewAuditEntry(ctx, Classes.Name, models.AuditUpdate, classID, changes, now)
+}
+
+// AccountAuditEntry returns the audit entry of a write to a teacher or
+// student account. Before is nil for creates and after for deletes.
+func AccountAuditEntry(ctx context.Context, action string, id int, before, after *models.Account, now time.Time) models.AuditEntry {
+	fields := func(account *models.Account) map[string]any {
+		if account == nil {
+			return nil
+		}
+		return map[string]any{
+			"subject_type":        account.SubjectType,
+			"subject_id":          account.SubjectID,
+			"username":            account.Username,
+			"password":            account.Password,
+			"password_changed_at": auditValue(reflect.ValueOf(account.PasswordChangedAt)),
+			"inactive_status":     account.InactiveStatus,
+		}
+	}
+	return NewAuditEntry(ctx, AuditAccount, action, id, auditChanges(fields(before), fields(after), "password"), now)
+}
+
+// APIKeyAuditEntry returns the audit entry of a write to an API key. Before
+// is nil for creates.
+func APIKeyAuditEntry(ctx context.Context, action string, id int, before, after *models.APIKey, now time.Time) models.AuditEntry {
+	fields := func(key *models.APIKey) map[string]any {
+		if key == nil {
+			return nil
+		}
+		var revokedAt any
+		if key.RevokedAt != nil {
+			revokedAt = key.RevokedAt.UTC().Format(time.DateTime)
+		}
+		return map[string]any{
+			"executive_id": key.ExecutiveID,
+			"name":         key.Name,
+			"prefix":       key.Prefix,
+			"key_hash":     key.KeyHash,
+			"scopes":       strings.Join(key.Scopes, " "),
+			"expires_at":   key.ExpiresAt.UTC().Format(time.DateTime),
+			"revoked_at":   revokedAt,
+		}
+	}
+	return NewAuditEntry(ctx, AuditAPIKey, action, id, auditChanges(fields(before), fields(after), "key_hash"), now)
+}
+
+// AuditChanges returns the fields that differ between before and after,
+// keyed by JSON name, or by column name for fields left out of the JSON.
+// Hidden columns hold secrets such as password hashes and TOTP secrets, so
+// their changes are recorded redacted.
+func (e Entity[T]) AuditChanges(before, after *T) map[string]models.AuditChange {
+	columns := slices.DeleteFunc(e.Columns(), func(column Column) bool { return column.Name == "id" })
+	keys := make([]string, len(columns))
+	var secret []string
+	for i, column := range columns {
+		keys[i] = column.JSONName
+		if keys[i] == "-" {
+			keys[i] = column.Name
+		}
+		if column.Hidden {
+			secret = append(secret, keys[i])
+		}
+	}
+
+	fields := func(item *T) map[string]any {
+		if item == nil {
+			return nil
+		}
+		values := make(map[string]any, len(columns))
+		for i, column := range columns {
+			values[keys[i]] = auditValue(reflect.ValueOf(item).Elem().Field(column.Index))
+		}
+		return values
+	}
+	return auditChanges(fields(before), fields(after), secret...)
+}
+
+// auditChanges returns the fields that differ between two sets of field
+// values, either of which is nil when the item does not exist. The values
+// of secret fields are recorded as redacted, or null when they are unset,
+// and left out when unset on both sides.
+func auditChanges(before, after map[string]any, secret ...string) map[string]models.AuditChange {
+	fields := after
+	if fields == nil {
+		fields = before
+	}
+
+	changes := make(map[string]models.AuditChange)
+	for key := range fields {
+		change := models.AuditChange{Before: before[key], After: after[key]}
+		if reflect.DeepEqual(change.Before, change.After) {
+			continue
+		}
+		if slices.Contains(secret, key) {
+			change.Before, change.After = redact(change.Before), redact(change.After)
+			if change.Before == nil && change.After == nil {
+				continue
+			}
+		}
+		changes[key] = change
+	}
+	return changes
+}
+
+// redact returns the value of a secret field as recorded in the audit log.
+func redact(value any) any {
+	if value == nil || reflect.ValueOf(value).IsZero() {
+		return nil
+	}
+	return models.AuditRedacted
+}
+
+// auditValue returns the value of a field as stored, so nullable fields
+// such as sql.NullString are recorded as null or their value.
+func auditValue(field reflect.Value) any {
+	value := field.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err == nil {
+			return v
+		}
+	}
+	return value
+}
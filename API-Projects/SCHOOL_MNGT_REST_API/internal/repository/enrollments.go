@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+)
+
+// Section is the group of students a teacher takes in a subject in one
+// term. The capacity of the subject limits its size.
+type Section struct {
+	SubjectID    int
+	TeacherID    int
+	AcademicYear string
+	Term         int
+}
+
+// SectionOf returns the section of an enrollment.
+func SectionOf(enrollment models.Enrollment) Section {
+	return Section{
+		SubjectID:    enrollment.SubjectID,
+		TeacherID:    enrollment.TeacherID,
+		AcademicYear: enrollment.AcademicYear,
+		Term:         enrollment.Term,
+	}
+}
+
+// EnrollmentFullError reports enrollments that would take a section over
+// the capacity of its subject.
+func EnrollmentFullError(section Section, capacity int) error {
+	return utils.ConflictError(nil, Enrollments.Message("inserting %s data into")).WithDetails(fmt.Sprintf(
+		"subject %d with teacher %d is full in term %d of %s, its capacity is %d students",
+		section.SubjectID, section.TeacherID, section.Term, section.AcademicYear, capacity))
+}
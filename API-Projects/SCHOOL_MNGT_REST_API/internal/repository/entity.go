@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"slices"
+	"strings"
+)
+
+// Column is a database column of an entity, mapped to a struct field by its db tag.
+type Column struct {
+	Name     string
+	JSONName string
+	Index    int
+	Hidden   bool
+}
+
+// Entity describes how a model is stored. The columns come from the db and
+// json tags of T; the hooks add per-entity behaviour to the generic
+// repositories and handlers.
+type Entity[T any] struct {
+	// Name is the singular, lower case name used in messages, e.g. "student".
+	Name string
+	// Table is the database table, e.g. "students".
+	Table string
+	// Hidden columns are never returned by reads nor written by updates,
+	// e.g. password hashes. Only Create stores them.
+	Hidden []string
+	// Settable are the hidden columns a new item may set, e.g. the password
+	// BeforeCreate hashes. The others, e.g. reset tokens, are only written
+	// by the repositories.
+	Settable []string
+	// BeforeCreate runs on every new item before it is stored, e.g. to hash a password.
+	BeforeCreate func(item *T) error
+}
+
+// Entities served by the API.
+var (
+	Students = Entity[models.Student]{Name: "student", Table: "students"}
+	Teachers = Entity[models.Teacher]{Name: "teacher", Table: "teachers"}
+	Classes  = Entity[models.Class]{Name: "class", Table: "classes"}
+	Subjects = Entity[models.Subject]{Name: "subject", Table: "subjects"}
+
+	Enrollments = Entity[models.Enrollment]{Name: "enrollment", Table: "enrollments"}
+	Assessments = Entity[models.Assessment]{Name: "assessment", Table: "assessments"}
+	Grades      = Entity[models.Grade]{Name: "grade", Table: "grades"}
+	Attendance  = Entity[models.Attendance]{Name: "attendance", Table: "attendance"}
+
+	Executives = Entity[models.Executive]{
+		Name:         "executive",
+		Table:        "execs",
+		Hidden:       []string{"password", "password_changed_at", "password_reset_token", "password_token_expires", "totp_secret", "totp_enabled", "totp_last_step"},
+		Settable:     []string{"password"},
+		BeforeCreate: hashExecutivePassword,
+	}
+)
+
+// hashExecutivePassword replaces the plain text password of a new executive with its hash.
+func hashExecutivePassword(executive *models.Executive) error {
+	if executive.Password == "" {
+		return utils.ValidationError(nil, "password is required")
+	}
+	encodedHash, err := utils.HashPassword(executive.Password)
+	if err != nil {
+		return utils.ErrorHandler(err, "Error inserting executive data into database")
+	}
+	executive.Password = encodedHash
+	return nil
+}
+
+// Title returns the capitalized name, e.g. "Student".
+func (e Entity[T]) Title() string {
+	return strings.ToUpper(e.Name[:1]) + e.Name[1:]
+}
+
+// PluralTitle returns the capitalized plural name, e.g. "Students".
+func (e Entity[T]) PluralTitle() string {
+	return strings.ToUpper(e.Name[:1]) + e.Plural()[1:]
+}
+
+// Plural returns the plural name, e.g. "students" or "classes".
+func (e Entity[T]) Plural() string {
+	if strings.HasSuffix(e.Name, "s") {
+		return e.Name + "es"
+	}
+	return e.Name + "s"
+}
+
+// Columns returns every column of the entity in field order, including the id.
+func (e Entity[T]) Columns() []Column {
+	modelType := reflect.TypeFor[T]()
+	var columns []Column
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		name := strings.TrimSpace(strings.Split(field.Tag.Get("db"), ",")[0])
+		if name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, Column{
+			Name:     name,
+			JSONName: strings.Split(field.Tag.Get("json"), ",")[0],
+			Index:    i,
+			Hidden:   slices.Contains(e.Hidden, name),
+		})
+	}
+	return columns
+}
+
+// VisibleColumns returns the columns returned by reads.
+func (e Entity[T]) VisibleColumns() []Column {
+	var columns []Column
+	for _, column := range e.Columns() {
+		if !column.Hidden {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// ColumnNames returns the names of columns joined for a SELECT or INSERT list.
+func ColumnNames(columns []Column) string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// ID returns the id of item.
+func (e Entity[T]) ID(item T) int {
+	id, _ := utils.FieldByColumn(reflect.ValueOf(item), "id")
+	return int(id.Int())
+}
+
+// SetID sets the id of item.
+func (e Entity[T]) SetID(item *T, id int) {
+	field, _ := utils.FieldByColumn(reflect.ValueOf(item), "id")
+	field.SetInt(int64(id))
+}
+
+// Hide clears the hidden columns of item before it is returned.
+func (e Entity[T]) Hide(item *T) {
+	itemValue := reflect.ValueOf(item).Elem()
+	for _, column := range e.Columns() {
+		if column.Hidden {
+			field := itemValue.Field(column.Index)
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
+
+// ProtectedColumns returns the hidden columns a new item may not set, those
+// not listed in Settable.
+func (e Entity[T]) ProtectedColumns() []Column {
+	var columns []Column
+	for _, column := range e.Columns() {
+		if column.Hidden && !slices.Contains(e.Settable, column.Name) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Protect clears the protected columns of a new item, so it cannot be
+// created with e.g. a reset token of its own.
+func (e Entity[T]) Protect(item *T) {
+	itemValue := reflect.ValueOf(item).Elem()
+	for _, column := range e.ProtectedColumns() {
+		field := itemValue.Field(column.Index)
+		field.Set(reflect.Zero(field.Type()))
+	}
+}
+
+// KeepHidden copies the hidden columns of src into item, so an update does
+// not overwrite values it cannot see.
+func (e Entity[T]) KeepHidden(item *T, src T) {
+	itemValue := reflect.ValueOf(item).Elem()
+	srcValue := reflect.ValueOf(src)
+	for _, column := range e.Columns() {
+		if column.Hidden {
+			itemValue.Field(column.Index).Set(srcValue.Field(column.Index))
+		}
+	}
+}
+
+// Values returns the values of the given columns of item, in order.
+func (e Entity[T]) Values(item T, columns []Column) []interface{} {
+	itemValue := reflect.ValueOf(item)
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = itemValue.Field(column.Index).Interface()
+	}
+	return values
+}
+
+// Pointers returns pointers to the fields of the given columns, for rows.Scan.
+func (e Entity[T]) Pointers(item *T, columns []Column) []interface{} {
+	itemValue := reflect.ValueOf(item).Elem()
+	pointers := make([]interface{}, len(columns))
+	for i, column := range columns {
+		pointers[i] = itemValue.Field(column.Index).Addr().Interface()
+	}
+	return pointers
+}
+
+// InsertColumns returns the columns written when item is created: every
+// column except the id, the protected columns and NULL values, so database
+// defaults apply.
+func (e Entity[T]) InsertColumns(item T) []Column {
+	itemValue := reflect.ValueOf(item)
+	var columns []Column
+	for _, column := range e.Columns() {
+		if column.Name == "id" || column.Hidden && !slices.Contains(e.Settable, column.Name) {
+			continue
+		}
+		if valuer, ok := itemValue.Field(column.Index).Interface().(driver.Valuer); ok {
+			if value, err := valuer.Value(); err == nil && value == nil {
+				continue
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// UpdateColumns returns the columns written by a full update.
+func (e Entity[T]) UpdateColumns() []Column {
+	var columns []Column
+	for _, column := range e.VisibleColumns() {
+		if column.Name != "id" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Equal reports whether a and b have the same visible column values.
+func (e Entity[T]) Equal(a, b T) bool {
+	columns := e.VisibleColumns()
+	return reflect.DeepEqual(e.Values(a, columns), e.Values(b, columns))
+}
+
+// ApplyPatch sets the fields of a partial update, keyed by JSON name, on item
+// and returns the changed columns. The id is ignored; unknown and hidden
+// fields or values of the wrong type are rejected.
+func (e Entity[T]) ApplyPatch(item *T, fields map[string]interface{}) ([]Column, error) {
+	byJSONName := make(map[string]Column)
+	for _, column := range e.VisibleColumns() {
+		byJSONName[column.JSONName] = column
+	}
+
+	itemValue := reflect.ValueOf(item).Elem()
+	var changed []Column
+	for key, value := range fields {
+		if key == "id" {
+			continue
+		}
+		column, ok := byJSONName[key]
+		if !ok {
+			return nil, utils.ValidationError(nil, fmt.Sprintf("invalid field: %s", key))
+		}
+		if err := setField(itemValue.Field(column.Index), value); err != nil {
+			return nil, utils.ValidationError(err, fmt.Sprintf("type mismatch for field: %s", key)).WithDetails(err.Error())
+		}
+		changed = append(changed, column)
+	}
+
+	if len(changed) == 0 {
+		return nil, utils.ValidationError(nil, "no valid fields provided for update")
+	}
+	return changed, nil
+}
+
+// setField assigns a decoded JSON value to a struct field. Nullable fields
+// such as sql.NullString are set through their Scan method; pointers are
+// set to nil by a JSON null.
+func setField(field reflect.Value, value interface{}) error {
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if field.Kind() == reflect.Ptr {
+		if value == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	val := reflect.ValueOf(value)
+	if !val.IsValid() || !val.Type().ConvertibleTo(field.Type()) {
+		return fmt.Errorf("cannot assign %T to %s", value, field.Type())
+	}
+	// reflect converts numbers to strings as runes, reject them instead
+	if field.Kind() == reflect.String && val.Kind() != reflect.String {
+		return fmt.Errorf("cannot assign %T to %s", value, field.Type())
+	}
+	// JSON numbers are float64, only whole numbers in range fit an integer
+	if f, ok := value.(float64); ok {
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || field.OverflowInt(int64(f)) {
+				return fmt.Errorf("%v is not a whole number within the range of %s", value, field.Type())
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || field.OverflowUint(uint64(f)) {
+				return fmt.Errorf("%v is not a whole number within the range of %s", value, field.Type())
+			}
+		}
+	}
+	field.Set(val.Convert(field.Type()))
+	return nil
+}
+
+// Error messages shared by the repository implementations so both report
+// the same failures the same way.
+
+// NotFoundError reports a missing row looked up by id.
+func (e Entity[T]) NotFoundError(err error, id int) error {
+	return utils.NotFoundError(err, fmt.Sprintf("%s with ID: %d not found in database", e.Title(), id))
+}
+
+// NoChangesError reports a full update that would not change anything.
+func (e Entity[T]) NoChangesError() error {
+	return utils.ValidationError(nil, fmt.Sprintf("no changes detected in the %s's details", e.Name))
+}
+
+// Message returns the message of a failed operation, e.g.
+// Message("inserting %s data into") is "Error inserting student data into database".
+func (e Entity[T]) Message(action string) string {
+	return "Error " + fmt.Sprintf(action, e.Name) + " database"
+}
+
+// PluralMessage is Message with the plural name, e.g.
+// PluralMessage("retrieving %s from") is "Error retrieving students from database".
+func (e Entity[T]) PluralMessage(action string) string {
+	return "Error " + fmt.Sprintf(action, e.Plural()) + " database"
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"school_management_api/internal/models"
+	"testing"
+)
+
+func TestApplyPatchNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "whole number", value: 30.0, want: 30},
+		{name: "fraction", value: 3.7, wantErr: true},
+		{name: "out of range", value: 1e20, wantErr: true},
+		{name: "string", value: "30", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class := models.Class{Capacity: 25}
+			_, err := Classes.ApplyPatch(&class, map[string]interface{}{"capacity": tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && class.Capacity != tt.want {
+				t.Errorf("Capacity = %d, want %d", class.Capacity, tt.want)
+			}
+		})
+	}
+}
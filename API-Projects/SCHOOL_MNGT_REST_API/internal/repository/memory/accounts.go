@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccountStore is the account repository. Accounts whose teacher or student
+// was deleted are ignored, like the rows the foreign keys cascade to.
+type AccountStore struct {
+	mu       *sync.RWMutex
+	accounts map[int]models.Account
+	nextID   int
+
+	teachers *TeacherTable
+	students *Table[models.Student]
+	audit    *AuditStore
+}
+
+// newAccountStore creates an empty account store guarded by the store's
+// lock, recording its writes in audit.
+func newAccountStore(mu *sync.RWMutex, teachers *TeacherTable, students *Table[models.Student], audit *AuditStore) *AccountStore {
+	return &AccountStore{mu: mu, accounts: make(map[int]models.Account), nextID: 1, teachers: teachers, students: students, audit: audit}
+}
+
+// subjectExists reports whether the teacher or student of an account
+// exists. The caller must hold the lock.
+func (s *AccountStore) subjectExists(subjectType string, subjectID int) bool {
+	switch subjectType {
+	case models.SubjectTeacher:
+		_, ok := s.teachers.rows[subjectID]
+		return ok
+	case models.SubjectStudent:
+		_, ok := s.students.rows[subjectID]
+		return ok
+	}
+	return false
+}
+
+// find returns the first live account matching match. The caller must hold the lock.
+func (s *AccountStore) find(match func(models.Account) bool) (models.Account, bool) {
+	for _, account := range s.accounts {
+		if s.subjectExists(account.SubjectType, account.SubjectID) && match(account) {
+			return account, true
+		}
+	}
+	return models.Account{}, false
+}
+
+// CreateAccount stores a new account.
+func (s *AccountStore) CreateAccount(ctx context.Context, account models.Account) (models.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := "Error creating account"
+	if !s.subjectExists(account.SubjectType, account.SubjectID) {
+		return models.Account{}, utils.ValidationError(errForeignKey, message).WithDetails("a referenced record does not exist")
+	}
+	if _, ok := s.find(func(a models.Account) bool { return strings.EqualFold(a.Username, account.Username) }); ok {
+		return models.Account{}, utils.ConflictError(errDuplicate, message).
+			WithDetails("duplicate value for username")
+	}
+	if _, ok := s.find(func(a models.Account) bool {
+		return a.SubjectType == account.SubjectType && a.SubjectID == account.SubjectID
+	}); ok {
+		return models.Account{}, utils.ConflictError(errDuplicate, message).
+			WithDetails(fmt.Sprintf("the %s already has an account", account.SubjectType))
+	}
+
+	// Drop the account of a deleted subject with the same ID, as the cascade would have
+	for id, a := range s.accounts {
+		if a.SubjectType == account.SubjectType && a.SubjectID == account.SubjectID {
+			delete(s.accounts, id)
+		}
+	}
+
+	now := time.Now()
+	account.ID = s.nextID
+	s.nextID++
+	account.CreatedAt = now.UTC().Format(time.DateTime)
+	s.accounts[account.ID] = account
+	s.audit.record(repository.AccountAuditEntry(ctx, models.AuditCreate, account.ID, nil, &account, now))
+	return account, nil
+}
+
+// GetAccountByUsername returns the account with the username.
+func (s *AccountStore) GetAccountByUsername(username string) (models.Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account, ok := s.find(func(a models.Account) bool { return strings.EqualFold(a.Username, username) })
+	if !ok {
+		return models.Account{}, utils.NotFoundError(errNotFound, "account not found in database")
+	}
+	return account, nil
+}
+
+// GetAccountBySubject returns the account of a teacher or student.
+func (s *AccountStore) GetAccountBySubject(subjectType string, subjectID int) (models.Account, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account, ok := s.find(func(a models.Account) bool { return a.SubjectType == subjectType && a.SubjectID == subjectID })
+	if !ok {
+		return models.Account{}, utils.NotFoundError(errNotFound, fmt.Sprintf("%s with ID %d has no account", subjectType, subjectID))
+	}
+	return account, nil
+}
+
+// UpdateAccountPassword stores a new password hash and the time it was changed.
+func (s *AccountStore) UpdateAccountPassword(ctx context.Context, id int, passwordHash string, changedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, ok := s.accounts[id]
+	if !ok {
+		return utils.NotFoundError(errNotFound, fmt.Sprintf("account with ID %d not found", id))
+	}
+	account := before
+	account.Password = passwordHash
+	account.PasswordChangedAt = sql.NullString{String: changedAt.UTC().Format(utils.DateTimeMicro), Valid: true}
+	s.accounts[id] = account
+	s.audit.record(repository.AccountAuditEntry(ctx, models.AuditUpdate, id, &before, &account, time.Now()))
+	return nil
+}
+
+// ReplaceAccountPasswordHash stores a new hash of the same password unless
+// the password was changed meanwhile.
+func (s *AccountStore) ReplaceAccountPasswordHash(ctx context.Context, id int, oldHash string, newHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, ok := s.accounts[id]
+	if !ok {
+		return utils.NotFoundError(errNotFound, fmt.Sprintf("account with ID %d not found", id))
+	}
+	if before.Password != oldHash {
+		return nil
+	}
+	account := before
+	account.Password = newHash
+	s.accounts[id] = account
+	s.audit.record(repository.AccountAuditEntry(ctx, models.AuditUpdate, id, &before, &account, time.Now()))
+	return nil
+}
+
+// DeleteAccount deletes the account of a teacher or student.
+func (s *AccountStore) DeleteAccount(ctx context.Context, subjectType string, subjectID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.find(func(a models.Account) bool { return a.SubjectType == subjectType && a.SubjectID == subjectID })
+	if !ok {
+		return utils.NotFoundError(errNotFound, fmt.Sprintf("%s with ID %d has no account", subjectType, subjectID))
+	}
+	delete(s.accounts, account.ID)
+	s.audit.record(repository.AccountAuditEntry(ctx, models.AuditDelete, account.ID, &account, nil, time.Now()))
+	return nil
+}
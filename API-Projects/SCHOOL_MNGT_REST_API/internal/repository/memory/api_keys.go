@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"slices"
+	"sync"
+	"time"
+)
+
+// APIKeyStore is the API key repository. Keys whose executive was deleted
+// are ignored, like the rows the foreign key cascades to.
+type APIKeyStore struct {
+	mu     *sync.RWMutex
+	keys   map[int]models.APIKey
+	nextID int
+
+	executives *ExecutiveTable
+	audit      *AuditStore
+}
+
+// newAPIKeyStore creates an empty API key store guarded by the store's
+// lock, recording its writes in audit.
+func newAPIKeyStore(mu *sync.RWMutex, executives *ExecutiveTable, audit *AuditStore) *APIKeyStore {
+	return &APIKeyStore{mu: mu, keys: make(map[int]models.APIKey), nextID: 1, executives: executives, audit: audit}
+}
+
+// live reports whether the executive of a key exists. The caller must hold the lock.
+func (s *APIKeyStore) live(key models.APIKey) bool {
+	_, ok := s.executives.rows[key.ExecutiveID]
+	return ok
+}
+
+// CreateAPIKey stores a new key.
+func (s *APIKeyStore) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := "Error creating API key"
+	if !s.live(key) {
+		return models.APIKey{}, utils.ValidationError(errForeignKey, message).WithDetails("a referenced record does not exist")
+	}
+	for _, existing := range s.keys {
+		if existing.KeyHash == key.KeyHash {
+			return models.APIKey{}, utils.ConflictError(errDuplicate, message)
+		}
+	}
+
+	// Mirror the DATETIME columns, which drop fractional seconds
+	key.CreatedAt = key.CreatedAt.UTC().Truncate(time.Second)
+	key.ExpiresAt = key.ExpiresAt.UTC().Truncate(time.Second)
+	key.Scopes = slices.Clone(key.Scopes)
+	key.ID = s.nextID
+	s.nextID++
+	s.keys[key.ID] = key
+	s.audit.record(repository.APIKeyAuditEntry(ctx, models.AuditCreate, key.ID, nil, &key, key.CreatedAt))
+	return key, nil
+}
+
+// ListAPIKeys returns the keys of an executive, newest first.
+func (s *APIKeyStore) ListAPIKeys(execID int) ([]models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := []models.APIKey{}
+	for _, key := range s.keys {
+		if key.ExecutiveID == execID && s.live(key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b models.APIKey) int { return cmp.Compare(b.ID, a.ID) })
+	return keys, nil
+}
+
+// GetAPIKeyByHash returns the key with the given hash.
+func (s *APIKeyStore) GetAPIKeyByHash(keyHash string) (models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.KeyHash == keyHash && s.live(key) {
+			return key, nil
+		}
+	}
+	return models.APIKey{}, utils.NotFoundError(errNotFound, "API key not found in database")
+}
+
+// RevokeAPIKey revokes a key of an executive. Revoking it again keeps the
+// first revocation time.
+func (s *APIKeyStore) RevokeAPIKey(ctx context.Context, execID int, id int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, ok := s.keys[id]
+	if !ok || before.ExecutiveID != execID || !s.live(before) {
+		return utils.NotFoundError(errNotFound, fmt.Sprintf("API key with ID %d not found", id))
+	}
+	if before.RevokedAt == nil {
+		key := before
+		revokedAt := now.UTC().Truncate(time.Second)
+		key.RevokedAt = &revokedAt
+		s.keys[id] = key
+		s.audit.record(repository.APIKeyAuditEntry(ctx, models.AuditUpdate, id, &before, &key, now))
+	}
+	return nil
+}
+
+// TouchAPIKey records that a key was used.
+func (s *APIKeyStore) TouchAPIKey(id int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return utils.NotFoundError(errNotFound, fmt.Sprintf("API key with ID %d not found", id))
+	}
+	usedAt := now.UTC().Truncate(time.Second)
+	key.LastUsedAt = &usedAt
+	s.keys[id] = key
+	return nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"slices"
+	"time"
+)
+
+// AssessmentTable is the assessment repository. Besides the generic
+// operations it stores the grades given in the assessments. Grades of
+// deleted assessments are ignored, as the database deletes them along with
+// the assessment.
+type AssessmentTable struct {
+	*Table[models.Assessment]
+	students *Table[models.Student]
+	grades   *Table[models.Grade]
+}
+
+// gradesOf returns the grades of existing assessments matching keep, by ID.
+// The caller must hold the lock.
+func (t *AssessmentTable) gradesOf(keep func(grade models.Grade) bool) []models.Grade {
+	var grades []models.Grade
+	for _, grade := range t.grades.rows {
+		if _, ok := t.rows[grade.AssessmentID]; ok && keep(grade) {
+			grades = append(grades, grade)
+		}
+	}
+	sortItems(grades, nil)
+	return grades
+}
+
+// GetGradesByAssessmentID returns the grades of an assessment.
+func (t *AssessmentTable) GetGradesByAssessmentID(id int) ([]models.Grade, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.rows[id]; !ok {
+		return nil, t.entity.NotFoundError(errNotFound, id)
+	}
+
+	grades := t.gradesOf(func(grade models.Grade) bool { return grade.AssessmentID == id })
+	slices.SortFunc(grades, func(a, b models.Grade) int { return cmp.Compare(a.StudentID, b.StudentID) })
+	return grades, nil
+}
+
+// SetGrades stores the scores of students in an assessment.
+func (t *AssessmentTable) SetGrades(ctx context.Context, assessmentID int, grades []models.Grade) ([]models.Grade, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	assessment, ok := t.rows[assessmentID]
+	if !ok {
+		return nil, t.entity.NotFoundError(errNotFound, assessmentID)
+	}
+
+	classOf := func(studentID int) (int, bool, error) {
+		student, ok := t.students.rows[studentID]
+		return student.ClassID, ok, nil
+	}
+	if err := repository.CheckGrades(assessment, grades, classOf); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[int]models.Grade)
+	for _, grade := range t.gradesOf(func(grade models.Grade) bool { return grade.AssessmentID == assessmentID }) {
+		existing[grade.StudentID] = grade
+	}
+
+	now := time.Now()
+	stored := make([]models.Grade, len(grades))
+	var entries []models.AuditEntry
+	for i, grade := range grades {
+		grade.AssessmentID = assessmentID
+
+		before, ok := existing[grade.StudentID]
+		switch {
+		case !ok:
+			grade.ID = t.grades.nextID
+			t.grades.nextID++
+			entries = append(entries, repository.Grades.AuditEntry(ctx, models.AuditCreate, grade.ID, nil, &grade, now))
+		case before.Score != grade.Score:
+			grade.ID = before.ID
+			entries = append(entries, repository.Grades.AuditEntry(ctx, models.AuditUpdate, grade.ID, &before, &grade, now))
+		default:
+			grade.ID = before.ID
+		}
+		t.grades.rows[grade.ID] = grade
+		stored[i] = grade
+	}
+	t.record(entries...)
+	return stored, nil
+}
+
+// GetGradesByStudentID returns the grades of a student with their assessments.
+func (t *AssessmentTable) GetGradesByStudentID(id int, academicYear string, term int) ([]models.StudentGrade, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.students.rows[id]; !ok {
+		return nil, repository.Students.NotFoundError(errNotFound, id)
+	}
+
+	var studentGrades []models.StudentGrade
+	for _, grade := range t.gradesOf(func(grade models.Grade) bool { return grade.StudentID == id }) {
+		assessment := t.rows[grade.AssessmentID]
+		if (academicYear == "" || assessment.AcademicYear == academicYear) && (term == 0 || assessment.Term == term) {
+			studentGrades = append(studentGrades, models.StudentGrade{Assessment: assessment, Score: grade.Score})
+		}
+	}
+	slices.SortFunc(studentGrades, func(a, b models.StudentGrade) int {
+		return cmp.Or(cmp.Compare(a.Assessment.Date, b.Assessment.Date), cmp.Compare(a.Assessment.ID, b.Assessment.ID))
+	})
+	return studentGrades, nil
+}
+
+// GetClassGrades returns the assessments of a class in a term and every
+// grade given in them.
+func (t *AssessmentTable) GetClassGrades(classID int, academicYear string, term int) ([]models.Assessment, []models.Grade, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	inTerm := func(assessment models.Assessment) bool {
+		return assessment.ClassID == classID && assessment.AcademicYear == academicYear && assessment.Term == term
+	}
+
+	var assessments []models.Assessment
+	for _, assessment := range t.rows {
+		if inTerm(assessment) {
+			assessments = append(assessments, assessment)
+		}
+	}
+	sortItems(assessments, []utils.SortField{{Column: "date"}})
+
+	grades := t.gradesOf(func(grade models.Grade) bool { return inTerm(t.rows[grade.AssessmentID]) })
+	return assessments, grades, nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"slices"
+	"time"
+)
+
+// AttendanceTable is the attendance repository. Besides the generic
+// operations it takes the roll of a class and counts the records for the
+// attendance reports.
+type AttendanceTable struct {
+	*Table[models.Attendance]
+	classes  *Table[models.Class]
+	students *Table[models.Student]
+}
+
+// TakeRoll stores the attendance of every student of a class.
+func (t *AttendanceTable) TakeRoll(ctx context.Context, classID int, roll models.Roll) ([]models.Attendance, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.classes.rows[classID]; !ok {
+		return nil, repository.Classes.NotFoundError(errNotFound, classID)
+	}
+
+	var studentIDs []int
+	for _, student := range t.students.rows {
+		if student.ClassID == classID {
+			studentIDs = append(studentIDs, student.ID)
+		}
+	}
+	slices.Sort(studentIDs)
+
+	records, err := repository.RollRecords(classID, roll, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[int]models.Attendance)
+	for _, record := range t.rows {
+		if record.Date == roll.Date && record.Period == roll.Period {
+			existing[record.StudentID] = record
+		}
+	}
+
+	now := time.Now()
+	var entries []models.AuditEntry
+	for i, record := range records {
+		before, ok := existing[record.StudentID]
+		switch {
+		case !ok:
+			record.ID = t.nextID
+			t.nextID++
+			entries = append(entries, t.entity.AuditEntry(ctx, models.AuditCreate, record.ID, nil, &record, now))
+		default:
+			record.ID = before.ID
+			if !t.entity.Equal(before, record) {
+				entries = append(entries, t.entity.AuditEntry(ctx, models.AuditUpdate, record.ID, &before, &record, now))
+			}
+		}
+		t.rows[record.ID] = record
+		records[i] = record
+	}
+	t.record(entries...)
+	return records, nil
+}
+
+// GetRegister returns the attendance records of a class from one date to another.
+func (t *AttendanceTable) GetRegister(classID int, from string, to string) ([]models.Attendance, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.classes.rows[classID]; !ok {
+		return nil, repository.Classes.NotFoundError(errNotFound, classID)
+	}
+
+	filter := repository.AttendanceFilter{ClassID: classID, From: from, To: to}
+	var records []models.Attendance
+	for _, record := range t.rows {
+		if filter.Match(record) {
+			records = append(records, record)
+		}
+	}
+	slices.SortFunc(records, func(a, b models.Attendance) int {
+		return cmp.Or(cmp.Compare(a.Date, b.Date), cmp.Compare(a.Period, b.Period), cmp.Compare(a.StudentID, b.StudentID))
+	})
+	return records, nil
+}
+
+// summarize counts the records of the filter per class, or per student and
+// class when byStudent is set, ordered by class and student.
+func (t *AttendanceTable) summarize(filter repository.AttendanceFilter, byStudent bool) []models.AttendanceSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	type key struct{ classID, studentID int }
+	summaries := make(map[key]*models.AttendanceSummary)
+	students := make(map[key]map[int]bool)
+	for _, record := range t.rows {
+		if !filter.Match(record) {
+			continue
+		}
+		k := key{classID: record.ClassID}
+		if byStudent {
+			k.studentID = record.StudentID
+		}
+		if summaries[k] == nil {
+			summaries[k] = &models.AttendanceSummary{StudentID: k.studentID, ClassID: k.classID}
+			students[k] = make(map[int]bool)
+		}
+		repository.CountAttendance(summaries[k], record.Status)
+		students[k][record.StudentID] = true
+	}
+
+	var result []models.AttendanceSummary
+	for k, summary := range summaries {
+		if !byStudent {
+			summary.Students = len(students[k])
+		}
+		repository.SetAttendanceRate(summary)
+		result = append(result, *summary)
+	}
+	slices.SortFunc(result, func(a, b models.AttendanceSummary) int {
+		return cmp.Or(cmp.Compare(a.ClassID, b.ClassID), cmp.Compare(a.StudentID, b.StudentID))
+	})
+	return result
+}
+
+// SummarizeByStudent counts the records of the filter per student and class.
+func (t *AttendanceTable) SummarizeByStudent(filter repository.AttendanceFilter) ([]models.AttendanceSummary, error) {
+	return t.summarize(filter, true), nil
+}
+
+// SummarizeByClass counts the records of the filter per class.
+func (t *AttendanceTable) SummarizeByClass(filter repository.AttendanceFilter) ([]models.AttendanceSummary, error) {
+	return t.summarize(filter, false), nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"slices"
+	"sync"
+)
+
+// AuditStore is the audit repository. The tables append to it under the
+// store's lock once a write has succeeded, so entries and changes are
+// kept together.
+type AuditStore struct {
+	mu      *sync.RWMutex
+	entries []models.AuditEntry
+	nextID  int
+}
+
+// newAuditStore creates an empty audit log guarded by the store's lock.
+func newAuditStore(mu *sync.RWMutex) *AuditStore {
+	return &AuditStore{mu: mu, nextID: 1}
+}
+
+// record appends entries, skipping updates that changed nothing. The
+// caller must hold the lock.
+func (s *AuditStore) record(entries ...models.AuditEntry) {
+	for _, entry := range entries {
+		if entry.Action == models.AuditUpdate && len(entry.Changes) == 0 {
+			continue
+		}
+		entry.ID = s.nextID
+		s.nextID++
+		s.entries = append(s.entries, entry)
+	}
+}
+
+// ListAudit returns one page of the entries matching filter, newest first.
+func (s *AuditStore) ListAudit(filter repository.AuditFilter, page utils.Pagination) ([]models.AuditEntry, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := []models.AuditEntry{}
+	for _, entry := range slices.Backward(s.entries) {
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return paginateItems(entries, page), len(entries), nil
+}
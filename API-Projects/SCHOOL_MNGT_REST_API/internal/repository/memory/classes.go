@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"slices"
+	"time"
+)
+
+// classTeacher is the assignment of a teacher to a class.
+type classTeacher struct {
+	classID   int
+	teacherID int
+}
+
+// ClassTable is the class repository. Besides the generic operations it
+// lists the students and teachers of a class and assigns teachers to it.
+// Assignments of deleted classes and teachers are ignored, as the database
+// deletes them along with either.
+type ClassTable struct {
+	*Table[models.Class]
+	students    *Table[models.Student]
+	teachers    *Table[models.Teacher]
+	assignments map[classTeacher]bool
+}
+
+// teacherIDs returns the IDs of the teachers assigned to a class, in order.
+// The caller must hold the lock.
+func (t *ClassTable) teacherIDs(classID int) []int {
+	IDs := []int{}
+	for assignment := range t.assignments {
+		if _, ok := t.teachers.rows[assignment.teacherID]; ok && assignment.classID == classID {
+			IDs = append(IDs, assignment.teacherID)
+		}
+	}
+	slices.Sort(IDs)
+	return IDs
+}
+
+// GetStudentsByClassID returns the students of a class.
+func (t *ClassTable) GetStudentsByClassID(id int) ([]models.Student, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.rows[id]; !ok {
+		return nil, t.entity.NotFoundError(errNotFound, id)
+	}
+
+	var students []models.Student
+	for _, student := range t.students.rows {
+		if student.ClassID == id {
+			students = append(students, student)
+		}
+	}
+	sortItems(students, nil)
+	return students, nil
+}
+
+// GetTeachersByClassID returns the teachers assigned to a class.
+func (t *ClassTable) GetTeachersByClassID(id int) ([]models.Teacher, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.rows[id]; !ok {
+		return nil, t.entity.NotFoundError(errNotFound, id)
+	}
+
+	var teachers []models.Teacher
+	for _, teacherID := range t.teacherIDs(id) {
+		teachers = append(teachers, t.teachers.rows[teacherID])
+	}
+	return teachers, nil
+}
+
+// AssignTeacher assigns a teacher to a class.
+func (t *ClassTable) AssignTeacher(ctx context.Context, classID int, teacherID int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rows[classID]; !ok {
+		return t.entity.NotFoundError(errNotFound, classID)
+	}
+	if _, ok := t.teachers.rows[teacherID]; !ok {
+		return repository.Teachers.NotFoundError(errNotFound, teacherID)
+	}
+
+	before := t.teacherIDs(classID)
+	if slices.Contains(before, teacherID) {
+		return nil
+	}
+	t.assignments[classTeacher{classID: classID, teacherID: teacherID}] = true
+
+	t.record(repository.ClassTeachersAuditEntry(ctx, classID, before, t.teacherIDs(classID), time.Now()))
+	return nil
+}
+
+// UnassignTeacher removes a teacher from a class.
+func (t *ClassTable) UnassignTeacher(ctx context.Context, classID int, teacherID int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.rows[classID]; !ok {
+		return t.entity.NotFoundError(errNotFound, classID)
+	}
+
+	before := t.teacherIDs(classID)
+	if !slices.Contains(before, teacherID) {
+		return utils.NotFoundError(nil, fmt.Sprintf("Teacher with ID %d is not assigned to class %d", teacherID, classID))
+	}
+	delete(t.assignments, classTeacher{classID: classID, teacherID: teacherID})
+
+	t.record(repository.ClassTeachersAuditEntry(ctx, classID, before, t.teacherIDs(classID), time.Now()))
+	return nil
+}
@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"time"
+)
+
+// ExecutiveTable is the executive repository. Besides the generic operations
+// it looks up executives with their password hash for logging in.
+type ExecutiveTable struct {
+	*Table[models.Executive]
+}
+
+// setCreatedAt mirrors the default of the user_created_at column.
+func setCreatedAt(executive *models.Executive) {
+	if !executive.UserCreatedAt.Valid {
+		executive.UserCreatedAt = sql.NullString{String: time.Now().UTC().Format(time.DateTime), Valid: true}
+	}
+}
+
+// GetUserByUsername retrieves an executive, including the hidden columns, by their username.
+func (t *ExecutiveTable) GetUserByUsername(username string) (models.Executive, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, executive := range t.rows {
+		if executive.Username == username {
+			return executive, nil
+		}
+	}
+	return models.Executive{}, utils.NotFoundError(errNotFound, "executive not found in database")
+}
+
+// GetUserByID retrieves an executive, including the hidden columns, by their ID.
+func (t *ExecutiveTable) GetUserByID(id int) (models.Executive, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	executive, ok := t.rows[id]
+	if !ok {
+		return models.Executive{}, t.entity.NotFoundError(errNotFound, id)
+	}
+	return executive, nil
+}
+
+// save stores a changed executive and records the change in the audit log.
+// The caller must hold the lock.
+func (t *ExecutiveTable) save(ctx context.Context, before models.Executive, after models.Executive) {
+	t.rows[after.ID] = after
+	t.record(t.entity.AuditEntry(ctx, models.AuditUpdate, after.ID, &before, &after, time.Now()))
+}
+
+// UpdatePassword stores a new password hash and the time it was changed.
+func (t *ExecutiveTable) UpdatePassword(ctx context.Context, id int, passwordHash string, changedAt time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	before, ok := t.rows[id]
+	if !ok {
+		return t.entity.NotFoundError(errNotFound, id)
+	}
+	executive := before
+	executive.Password = passwordHash
+	executive.PasswordChangedAt = sql.NullString{String: changedAt.UTC().Format(utils.DateTimeMicro), Valid: true}
+	t.save(ctx, before, executive)
+	return nil
+}
+
+// ReplacePasswordHash stores a new hash of the same password unless the
+// password was changed meanwhile.
+func (t *ExecutiveTable) ReplacePasswordHash(ctx context.Context, id int, oldHash string, newHash string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	before, ok := t.rows[id]
+	if !ok {
+		return t.entity.NotFoundError(errNotFound, id)
+	}
+	executive := before
+	if executive.Password == oldHash {
+		executive.Password = newHash
+		t.save(ctx, before, executive)
+	}
+	return nil
+}
+
+// SetPasswordResetToken stores the hash and expiry of a new password reset token.
+func (t *ExecutiveTable) SetPasswordResetToken(ctx context.Context, id int, tokenHash string, expires time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	before, ok := t.rows[id]
+	if !ok {
+		return t.entity.NotFoundError(errNotFound, id)
+	}
+	executive := before
+	executive.PasswordResetToken = sql.NullString{String: tokenHash, Valid: true}
+	executive.PasswordTokenExpires = sql.NullString{String: expires.UTC().Format(time.DateTime), Valid: true}
+	t.save(ctx, before, executive)
+	return nil
+}
+
+// ResetPassword sets a new password hash for the holder of an unexpired
+// reset token and clears the token.
+func (t *ExecutiveTable) ResetPassword(ctx context.Context, tokenHash string, passwordHash string, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	nowUTC := now.UTC().Format(time.DateTime)
+	for _, before := range t.rows {
+		if !before.PasswordResetToken.Valid || before.PasswordResetToken.String != tokenHash {
+			continue
+		}
+		// Both timestamps use time.DateTime in UTC, so they compare as strings
+		if before.PasswordTokenExpires.String <= nowUTC {
+			break
+		}
+
+		executive := before
+		executive.Password = passwordHash
+		executive.PasswordChangedAt = sql.NullString{String: now.UTC().Format(utils.DateTimeMicro), Valid: true}
+		executive.PasswordResetToken = sql.NullString{}
+		executive.PasswordTokenExpires = sql.NullString{}
+		t.save(ctx, before, executive)
+		return nil
+	}
+	return utils.NotFoundError(nil, "password reset code is invalid or has expired")
+}
+
+// SetTOTP stores the TOTP secret and state of an executive.
+func (t *ExecutiveTable) SetTOTP(ctx context.Context, id int, secret sql.NullString, enabled bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	before, ok := t.rows[id]
+	if !ok {
+		return t.entity.NotFoundError(errNotFound, id)
+	}
+	executive := before
+	executive.TOTPSecret = secret
+	executive.TOTPEnabled = enabled
+	executive.TOTPLastStep = sql.NullInt64{}
+	t.save(ctx, before, executive)
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code unless it is not
+// after the last one.
+func (t *ExecutiveTable) UseTOTPStep(ctx context.Context, id int, step int64) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	before, ok := t.rows[id]
+	if !ok {
+		return false, t.entity.NotFoundError(errNotFound, id)
+	}
+	executive := before
+	if executive.TOTPLastStep.Valid && executive.TOTPLastStep.Int64 >= step {
+		return false, nil
+	}
+	executive.TOTPLastStep = sql.NullInt64{Int64: step, Valid: true}
+	t.save(ctx, before, executive)
+	return true, nil
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"school_management_api/pkg/utils"
+	"sort"
+	"strconv"
+)
+
+// valueString formats a field value the way it is compared against query parameters.
+// Nil pointers, NULL in the database, give an empty string.
+func valueString(value reflect.Value) string {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	switch v := value.Interface().(type) {
+	case sql.NullString:
+		return v.String
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareValues returns -1, 0 or 1 depending on how a sorts relative to b.
+// Nil pointers and NULL nullable fields, e.g. sql.NullString, sort first
+// and strings ignore case, like NULL and the collation in MariaDB.
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return compareOrdered(boolToInt(!a.IsNil()), boolToInt(!b.IsNil()))
+		}
+		return compareValues(a.Elem(), b.Elem())
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Float64, reflect.Float32:
+		return compareOrdered(a.Float(), b.Float())
+	case reflect.Bool:
+		return compareOrdered(boolToInt(a.Bool()), boolToInt(b.Bool()))
+	case reflect.Struct:
+		valueA, valueB := utils.CursorValue(a), utils.CursorValue(b)
+		if valueA == nil || valueB == nil {
+			return compareOrdered(boolToInt(valueA != nil), boolToInt(valueB != nil))
+		}
+		return utils.CompareFold(valueString(a), valueString(b))
+	default:
+		return utils.CompareFold(valueString(a), valueString(b))
+	}
+}
+
+func compareOrdered[T int64 | int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// filterItems keeps the items matching every filter of the spec.
+func filterItems[T any](items []T, spec utils.QuerySpec) []T {
+	var filtered []T
+	for _, item := range items {
+		if spec.Match(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// sortItems orders the items by the sort fields of a validated query spec.
+// The ID breaks ties so the result is stable when no sort is given.
+func sortItems[T any](items []T, fields []utils.SortField) {
+	keys := append(append([]utils.SortField{}, fields...), utils.SortField{Column: "id"})
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := reflect.ValueOf(items[i]), reflect.ValueOf(items[j])
+		for _, key := range keys {
+			fieldA, _ := utils.FieldByColumn(a, key.Column)
+			fieldB, _ := utils.FieldByColumn(b, key.Column)
+			cmp := compareValues(fieldA, fieldB)
+			if cmp == 0 {
+				continue
+			}
+			if key.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// paginateItems returns the page of sorted items selected by the pagination,
+// including the extra row requested by FetchLimit.
+func paginateItems[T any](items []T, page utils.Pagination) []T {
+	if page.After != nil {
+		var after []T
+		for _, item := range items {
+			if page.IsAfterCursor(item) {
+				after = append(after, item)
+			}
+		}
+		items = after
+	}
+
+	offset := min(page.Offset(), len(items))
+	items = items[offset:]
+	if limit := page.FetchLimit(); limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
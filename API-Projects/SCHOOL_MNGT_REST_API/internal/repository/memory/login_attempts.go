@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"school_management_api/internal/models"
+	"sync"
+	"time"
+)
+
+// LoginAttemptStore is the login attempt repository.
+type LoginAttemptStore struct {
+	mu       *sync.RWMutex
+	attempts map[string]models.LoginAttempts
+}
+
+// newLoginAttemptStore creates an empty login attempt store guarded by the store's lock.
+func newLoginAttemptStore(mu *sync.RWMutex) *LoginAttemptStore {
+	return &LoginAttemptStore{mu: mu, attempts: make(map[string]models.LoginAttempts)}
+}
+
+// GetLoginAttempts returns the counter of key.
+func (s *LoginAttemptStore) GetLoginAttempts(key string) (models.LoginAttempts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok {
+		return models.LoginAttempts{Key: key}, nil
+	}
+	return attempts, nil
+}
+
+// RecordLoginFailure adds a failure to the counter of key.
+func (s *LoginAttemptStore) RecordLoginFailure(key string, now time.Time, resetBefore time.Time) (models.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok || attempts.LastFailure.Before(resetBefore) {
+		attempts = models.LoginAttempts{Key: key, LockedUntil: attempts.LockedUntil}
+	}
+	attempts.Failures++
+	attempts.LastFailure = now
+	s.attempts[key] = attempts
+	return attempts, nil
+}
+
+// LockLogin blocks the logins of key until the given time.
+func (s *LoginAttemptStore) LockLogin(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.attempts[key]
+	attempts.Key = key
+	attempts.LockedUntil = until
+	s.attempts[key] = attempts
+	return nil
+}
+
+// ResetLoginAttempts clears the counter and the lock of key.
+func (s *LoginAttemptStore) ResetLoginAttempts(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"errors"
+	"reflect"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strings"
+	"sync"
+)
+
+// Store is a thread-safe in-memory implementation of the student, teacher,
+// class, subject, enrollment, assessment, attendance, executive, account,
+// token, login attempt, two-factor, API key and audit repositories. It
+// mirrors the behaviour of the SQL repository so the API can be started and
+// exercised without a MariaDB instance.
+// All tables share one lock so constraints spanning tables stay consistent.
+type Store struct {
+	mu sync.RWMutex
+
+	Students    *Table[models.Student]
+	Teachers    *TeacherTable
+	Classes     *ClassTable
+	Subjects    *Table[models.Subject]
+	Enrollments *Table[models.Enrollment]
+	Assessments *AssessmentTable
+	Attendance  *AttendanceTable
+	Executives  *ExecutiveTable
+	Accounts    *AccountStore
+	Tokens      *TokenStore
+	Logins      *LoginAttemptStore
+	TwoFactor   *TwoFactorStore
+	APIKeys     *APIKeyStore
+	Audit       *AuditStore
+}
+
+// NewStore creates an empty in-memory store. The unique columns match the
+// unique keys of the schema.
+func NewStore() *Store {
+	s := &Store{}
+	s.Students = newTable(&s.mu, repository.Students, "email")
+	s.Teachers = &TeacherTable{Table: newTable(&s.mu, repository.Teachers, "email"), students: s.Students}
+	s.Classes = &ClassTable{
+		Table:       newTable(&s.mu, repository.Classes, "name,academic_year"),
+		students:    s.Students,
+		teachers:    s.Teachers.Table,
+		assignments: make(map[classTeacher]bool),
+	}
+	s.Subjects = newTable(&s.mu, repository.Subjects, "name")
+	s.Enrollments = newTable(&s.mu, repository.Enrollments, "student_id,subject_id,academic_year,term")
+	s.Teachers.enrollments = s.Enrollments
+	s.Assessments = &AssessmentTable{
+		Table:    newTable(&s.mu, repository.Assessments),
+		students: s.Students,
+		grades:   newTable(&s.mu, repository.Grades, "assessment_id,student_id"),
+	}
+	s.Attendance = &AttendanceTable{
+		Table:    newTable(&s.mu, repository.Attendance, "student_id,date,period"),
+		classes:  s.Classes.Table,
+		students: s.Students,
+	}
+	s.Executives = &ExecutiveTable{Table: newTable(&s.mu, repository.Executives, "email", "username")}
+	s.Audit = newAuditStore(&s.mu)
+	s.Accounts = newAccountStore(&s.mu, s.Teachers, s.Students, s.Audit)
+	s.Tokens = newTokenStore(&s.mu)
+	s.Logins = newLoginAttemptStore(&s.mu)
+	s.TwoFactor = newTwoFactorStore(&s.mu)
+	s.APIKeys = newAPIKeyStore(&s.mu, s.Executives, s.Audit)
+
+	s.Students.check = s.checkStudentClass
+	s.Students.checkDelete = s.checkStudentReferences
+	s.Classes.check = s.checkHomeroomTeacher
+	s.Classes.checkDelete = s.checkClassReferences
+	s.Teachers.checkDelete = s.checkTeacherReferences
+	s.Subjects.checkDelete = s.checkSubjectReferences
+	s.Enrollments.check = s.checkEnrollment
+	s.Assessments.check = s.checkAssessment
+	s.Attendance.check = s.checkAttendance
+	s.Executives.onCreate = setCreatedAt
+	s.Students.audit = s.Audit
+	s.Teachers.audit = s.Audit
+	s.Classes.audit = s.Audit
+	s.Subjects.audit = s.Audit
+	s.Enrollments.audit = s.Audit
+	s.Assessments.audit = s.Audit
+	s.Attendance.audit = s.Audit
+	s.Executives.audit = s.Audit
+	return s
+}
+
+// checkStudentClass mirrors the foreign key from students.class_id to classes.id.
+func (s *Store) checkStudentClass(student models.Student) error {
+	if _, ok := s.Classes.rows[student.ClassID]; !ok {
+		return errForeignKey
+	}
+	return nil
+}
+
+// checkHomeroomTeacher mirrors the foreign key from classes.homeroom_teacher_id to teachers.id.
+func (s *Store) checkHomeroomTeacher(class models.Class) error {
+	if class.HomeroomTeacherID == nil {
+		return nil
+	}
+	if _, ok := s.Teachers.rows[*class.HomeroomTeacherID]; !ok {
+		return errForeignKey
+	}
+	return nil
+}
+
+// checkAssessment mirrors the foreign keys of assessments.
+func (s *Store) checkAssessment(assessment models.Assessment) error {
+	_, subjectOK := s.Subjects.rows[assessment.SubjectID]
+	_, classOK := s.Classes.rows[assessment.ClassID]
+	if !subjectOK || !classOK {
+		return errForeignKey
+	}
+	return nil
+}
+
+// checkAttendance mirrors the foreign keys of attendance records.
+func (s *Store) checkAttendance(record models.Attendance) error {
+	_, studentOK := s.Students.rows[record.StudentID]
+	_, classOK := s.Classes.rows[record.ClassID]
+	if !studentOK || !classOK {
+		return errForeignKey
+	}
+	return nil
+}
+
+// checkClassReferences rejects deleting a class that still has students,
+// assessments or attendance records.
+func (s *Store) checkClassReferences(class models.Class) error {
+	for _, student := range s.Students.rows {
+		if student.ClassID == class.ID {
+			return errForeignKey
+		}
+	}
+	for _, assessment := range s.Assessments.rows {
+		if assessment.ClassID == class.ID {
+			return errForeignKey
+		}
+	}
+	for _, record := range s.Attendance.rows {
+		if record.ClassID == class.ID {
+			return errForeignKey
+		}
+	}
+	return nil
+}
+
+// checkTeacherReferences rejects deleting the homeroom teacher of a class
+// or a teacher with enrollments.
+func (s *Store) checkTeacherReferences(teacher models.Teacher) error {
+	for _, class := range s.Classes.rows {
+		if class.HomeroomTeacherID != nil && *class.HomeroomTeacherID == teacher.ID {
+			return errForeignKey
+		}
+	}
+	for _, enrollment := range s.Enrollments.rows {
+		if enrollment.TeacherID == teacher.ID {
+			return errForeignKey
+		}
+	}
+	return nil
+}
+
+// checkStudentReferences rejects deleting a student with enrollments, grades
+// or attendance records.
+func (s *Store) checkStudentReferences(student models.Student) error {
+	for _, enrollment := range s.Enrollments.rows {
+		if enrollment.StudentID == student.ID {
+			return errForeignKey
+		}
+	}
+	grades := s.Assessments.gradesOf(func(grade models.Grade) bool { return grade.StudentID == student.ID })
+	if len(grades) > 0 {
+		return errForeignKey
+	}
+	for _, record := range s.Attendance.rows {
+		if record.StudentID == student.ID {
+			return errForeignKey
+		}
+	}
+	return nil
+}
+
+// checkSubjectReferences rejects deleting a subject with enrollments or
+// assessments.
+func (s *Store) checkSubjectReferences(subject models.Subject) error {
+	for _, enrollment := range s.Enrollments.rows {
+		if enrollment.SubjectID == subject.ID {
+			return errForeignKey
+		}
+	}
+	for _, assessment := range s.Assessments.rows {
+		if assessment.SubjectID == subject.ID {
+			return errForeignKey
+		}
+	}
+	return nil
+}
+
+// checkEnrollment mirrors the foreign keys of enrollments and keeps the
+// section of the enrollment within the capacity of its subject.
+func (s *Store) checkEnrollment(enrollment models.Enrollment) error {
+	_, studentOK := s.Students.rows[enrollment.StudentID]
+	_, teacherOK := s.Teachers.rows[enrollment.TeacherID]
+	subject, subjectOK := s.Subjects.rows[enrollment.SubjectID]
+	if !studentOK || !teacherOK || !subjectOK {
+		return errForeignKey
+	}
+
+	section := repository.SectionOf(enrollment)
+	enrolled := 0
+	for _, other := range s.Enrollments.rows {
+		if other.ID != enrollment.ID && repository.SectionOf(other) == section {
+			enrolled++
+		}
+	}
+	if enrolled >= subject.Capacity {
+		return repository.EnrollmentFullError(section, subject.Capacity)
+	}
+	return nil
+}
+
+// Errors logged by the store in place of the database driver errors.
+var (
+	errNotFound   = errors.New("record not found")
+	errForeignKey = errors.New("foreign key constraint fails")
+	errDuplicate  = errors.New("duplicate entry")
+)
+
+// rowsOf returns the values of a table map.
+func rowsOf[T any](table map[int]T) []T {
+	rows := make([]T, 0, len(table))
+	for _, row := range table {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// checkUnique returns a Conflict error when item shares a unique key with
+// another of rows, compared case-insensitively like the database collation.
+// A key is a column or comma separated columns, e.g. "name,academic_year".
+// Rows with the same non-zero ID as item are the item itself and are skipped.
+func checkUnique[T any](rows []T, item T, message string, keys ...string) error {
+	itemValue := reflect.ValueOf(item)
+	itemID, _ := utils.FieldByColumn(itemValue, "id")
+
+	for _, row := range rows {
+		rowValue := reflect.ValueOf(row)
+		if rowID, _ := utils.FieldByColumn(rowValue, "id"); itemID.Int() != 0 && rowID.Int() == itemID.Int() {
+			continue
+		}
+		for _, key := range keys {
+			duplicate := true
+			for _, column := range strings.Split(key, ",") {
+				itemField, _ := utils.FieldByColumn(itemValue, column)
+				rowField, _ := utils.FieldByColumn(rowValue, column)
+				duplicate = duplicate && strings.EqualFold(valueString(itemField), valueString(rowField))
+			}
+			if duplicate {
+				return utils.ConflictError(errDuplicate, message).
+					WithDetails("duplicate value for " + strings.ReplaceAll(key, ",", ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Make sure the store satisfies the repository interfaces.
+var (
+	_ repository.StudentRepository      = (*Table[models.Student])(nil)
+	_ repository.TeacherRepository      = (*TeacherTable)(nil)
+	_ repository.ClassRepository        = (*ClassTable)(nil)
+	_ repository.SubjectRepository      = (*Table[models.Subject])(nil)
+	_ repository.EnrollmentRepository   = (*Table[models.Enrollment])(nil)
+	_ repository.AssessmentRepository   = (*AssessmentTable)(nil)
+	_ repository.AttendanceRepository   = (*AttendanceTable)(nil)
+	_ repository.ExecutiveRepository    = (*ExecutiveTable)(nil)
+	_ repository.AccountRepository      = (*AccountStore)(nil)
+	_ repository.TokenRepository        = (*TokenStore)(nil)
+	_ repository.LoginAttemptRepository = (*LoginAttemptStore)(nil)
+	_ repository.TwoFactorRepository    = (*TwoFactorStore)(nil)
+	_ repository.APIKeyRepository       = (*APIKeyStore)(nil)
+	_ repository.AuditRepository        = (*AuditStore)(nil)
+)
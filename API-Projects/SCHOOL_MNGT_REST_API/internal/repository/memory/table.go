@@ -0,0 +1,285 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"sync"
+	"time"
+)
+
+// Table implements repository.Resource for one entity in memory. The hooks
+// mirror the constraints of the matching database table.
+type Table[T any] struct {
+	mu     *sync.RWMutex
+	entity repository.Entity[T]
+	rows   map[int]T
+	nextID int
+
+	// unique lists the unique keys of the table, see checkUnique.
+	unique []string
+	// check returns errForeignKey when item references a missing row, or
+	// an error of its own for other constraints. The caller holds the lock.
+	check func(item T) error
+	// checkDelete returns errForeignKey when other rows still reference a
+	// deleted item. The item is already removed; the caller holds the lock.
+	checkDelete func(item T) error
+	// onCreate fills the columns the database sets by default.
+	onCreate func(item *T)
+	// audit records the writes of the table when set.
+	audit *AuditStore
+}
+
+// newTable creates an empty table guarded by the store's lock.
+func newTable[T any](mu *sync.RWMutex, entity repository.Entity[T], unique ...string) *Table[T] {
+	return &Table[T]{mu: mu, entity: entity, rows: make(map[int]T), nextID: 1, unique: unique}
+}
+
+// public returns item without its hidden columns.
+func (t *Table[T]) public(item T) T {
+	t.entity.Hide(&item)
+	return item
+}
+
+// record adds the audit entries of a successful write. The caller must hold the lock.
+func (t *Table[T]) record(entries ...models.AuditEntry) {
+	if t.audit != nil {
+		t.audit.record(entries...)
+	}
+}
+
+// put stores item after checking the unique keys and foreign keys.
+// The caller must hold the lock.
+func (t *Table[T]) put(item T, message string) error {
+	if err := checkUnique(rowsOf(t.rows), item, message, t.unique...); err != nil {
+		return err
+	}
+	if t.check != nil {
+		if err := t.check(item); errors.Is(err, errForeignKey) {
+			return utils.ValidationError(err, message).WithDetails("a referenced record does not exist")
+		} else if err != nil {
+			return err
+		}
+	}
+	t.rows[t.entity.ID(item)] = item
+	return nil
+}
+
+// List returns one page of the items matching the filters and sort order of
+// spec, along with the total number of matching items.
+func (t *Table[T]) List(spec utils.QuerySpec, page utils.Pagination) ([]T, int, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	items := filterItems(rowsOf(t.rows), spec)
+	sortItems(items, spec.Sort)
+
+	result := paginateItems(items, page)
+	for i := range result {
+		result[i] = t.public(result[i])
+	}
+	return result, len(items), nil
+}
+
+// GetByID retrieves a single item by its ID.
+func (t *Table[T]) GetByID(id int) (T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	item, ok := t.rows[id]
+	if !ok {
+		var zero T
+		return zero, t.entity.NotFoundError(errNotFound, id)
+	}
+	return t.public(item), nil
+}
+
+// Create adds new items to the table. Nothing is added if any item fails.
+func (t *Table[T]) Create(ctx context.Context, items []T) ([]T, error) {
+	// Run the hooks outside the lock, hashing passwords is deliberately slow
+	addedItems := make([]T, len(items))
+	for i, item := range items {
+		t.entity.Protect(&item)
+		if t.entity.BeforeCreate != nil {
+			if err := t.entity.BeforeCreate(&item); err != nil {
+				return nil, err
+			}
+		}
+		addedItems[i] = item
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	message := t.entity.Message("inserting %s data into")
+	backup := maps.Clone(t.rows)
+	now := time.Now()
+	entries := make([]models.AuditEntry, len(addedItems))
+	for i := range addedItems {
+		if t.onCreate != nil {
+			t.onCreate(&addedItems[i])
+		}
+		t.entity.SetID(&addedItems[i], t.nextID)
+		t.nextID++
+
+		if err := t.put(addedItems[i], message); err != nil {
+			t.rows = backup
+			return nil, err
+		}
+		entries[i] = t.entity.AuditEntry(ctx, models.AuditCreate, t.entity.ID(addedItems[i]), nil, &addedItems[i], now)
+		addedItems[i] = t.public(addedItems[i])
+	}
+	t.record(entries...)
+	return addedItems, nil
+}
+
+// Update replaces the visible columns of an existing item by its ID.
+func (t *Table[T]) Update(ctx context.Context, id int, updatedItem T) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var zero T
+	itemToUpdate, ok := t.rows[id]
+	if !ok {
+		return zero, t.entity.NotFoundError(errNotFound, id)
+	}
+
+	// Check if there are any changes before updating
+	t.entity.SetID(&updatedItem, id)
+	if t.entity.Equal(updatedItem, itemToUpdate) {
+		return zero, t.entity.NoChangesError()
+	}
+
+	t.entity.KeepHidden(&updatedItem, itemToUpdate)
+	if err := t.put(updatedItem, t.entity.Message("updating %s in the")); err != nil {
+		return zero, err
+	}
+	t.record(t.entity.AuditEntry(ctx, models.AuditUpdate, id, &itemToUpdate, &updatedItem, time.Now()))
+	return t.public(updatedItem), nil
+}
+
+// patch applies a partial update to the item with the given ID and returns
+// its audit entry. The caller must hold the lock.
+func (t *Table[T]) patch(ctx context.Context, id int, fields map[string]interface{}) (T, models.AuditEntry, error) {
+	var zero T
+	item, ok := t.rows[id]
+	if !ok {
+		return zero, models.AuditEntry{}, t.entity.NotFoundError(errNotFound, id)
+	}
+	before := item
+
+	if _, err := t.entity.ApplyPatch(&item, fields); err != nil {
+		return zero, models.AuditEntry{}, err
+	}
+	if err := t.put(item, t.entity.Message("updating %s data into")); err != nil {
+		return zero, models.AuditEntry{}, err
+	}
+	return t.public(item), t.entity.AuditEntry(ctx, models.AuditUpdate, id, &before, &item, time.Now()), nil
+}
+
+// Patch performs a partial update on a single item by its ID.
+func (t *Table[T]) Patch(ctx context.Context, id int, fields map[string]interface{}) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, entry, err := t.patch(ctx, id, fields)
+	if err != nil {
+		return item, err
+	}
+	t.record(entry)
+	return item, nil
+}
+
+// PatchMany performs partial updates on multiple items.
+// Either every update is applied or none of them.
+func (t *Table[T]) PatchMany(ctx context.Context, updates []map[string]interface{}) ([]T, error) {
+	IDs := make([]int, len(updates))
+	for i, update := range updates {
+		id, err := utils.GetIDFromMap(update)
+		if err != nil {
+			return nil, utils.ValidationError(err, err.Error())
+		}
+		IDs[i] = id
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backup := maps.Clone(t.rows)
+	var patchedItems []T
+	var entries []models.AuditEntry
+	for i, update := range updates {
+		item, entry, err := t.patch(ctx, IDs[i], update)
+		if err != nil {
+			t.rows = backup
+			return nil, err
+		}
+		patchedItems = append(patchedItems, item)
+		entries = append(entries, entry)
+	}
+	t.record(entries...)
+	return patchedItems, nil
+}
+
+// remove deletes the item with the given ID unless other rows still
+// reference it, and returns its audit entry. The caller must hold the lock.
+func (t *Table[T]) remove(ctx context.Context, id int, message string) (models.AuditEntry, error) {
+	item, ok := t.rows[id]
+	if !ok {
+		return models.AuditEntry{}, utils.NotFoundError(nil, fmt.Sprintf("%s with ID %d not found", t.entity.Name, id))
+	}
+
+	delete(t.rows, id)
+	if t.checkDelete != nil {
+		if err := t.checkDelete(item); err != nil {
+			t.rows[id] = item
+			return models.AuditEntry{}, utils.ConflictError(err, message).WithDetails("the record is still referenced by other records")
+		}
+	}
+	// Like the database, which reads the visible columns only
+	item = t.public(item)
+	return t.entity.AuditEntry(ctx, models.AuditDelete, id, &item, nil, time.Now()), nil
+}
+
+// Delete deletes a single item by its ID.
+func (t *Table[T]) Delete(ctx context.Context, id int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, err := t.remove(ctx, id, t.entity.Message("deleting %s from"))
+	if err != nil {
+		return err
+	}
+	t.record(entry)
+	return nil
+}
+
+// DeleteMany deletes multiple items by their IDs and returns the list of
+// deleted IDs. Nothing is deleted if any of the deletions fails.
+func (t *Table[T]) DeleteMany(ctx context.Context, IDs []int) ([]int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backup := maps.Clone(t.rows)
+	deletedIDs := []int{}
+	var entries []models.AuditEntry
+	for _, id := range IDs {
+		entry, err := t.remove(ctx, id, fmt.Sprintf("Failed to delete %s with ID %d", t.entity.Name, id))
+		if err != nil {
+			t.rows = backup
+			return nil, err
+		}
+		deletedIDs = append(deletedIDs, id)
+		entries = append(entries, entry)
+	}
+	t.record(entries...)
+
+	if len(deletedIDs) == 0 {
+		return nil, utils.NotFoundError(nil, fmt.Sprintf("no %s found", t.entity.Plural()))
+	}
+	return deletedIDs, nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"school_management_api/internal/models"
+	"strconv"
+)
+
+// TeacherTable is the teacher repository. Besides the generic operations it
+// looks up the students enrolled with a teacher.
+type TeacherTable struct {
+	*Table[models.Teacher]
+	students    *Table[models.Student]
+	enrollments *Table[models.Enrollment]
+}
+
+// GetStudentsByTeacherID returns the students enrolled with the given teacher.
+func (t *TeacherTable) GetStudentsByTeacherID(teacherId string) ([]models.Student, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var students []models.Student
+	teacher, ok := t.teacherByIDString(teacherId)
+	if !ok {
+		return students, nil
+	}
+
+	enrolled := make(map[int]bool)
+	for _, enrollment := range t.enrollments.rows {
+		if enrollment.TeacherID == teacher.ID {
+			enrolled[enrollment.StudentID] = true
+		}
+	}
+	for _, student := range t.students.rows {
+		if enrolled[student.ID] {
+			students = append(students, student)
+		}
+	}
+	sortItems(students, nil)
+	return students, nil
+}
+
+// GetStudentCountByTeacherID returns the number of students enrolled with the given teacher.
+func (t *TeacherTable) GetStudentCountByTeacherID(teacherId string) (int, error) {
+	students, err := t.GetStudentsByTeacherID(teacherId)
+	if err != nil {
+		return 0, err
+	}
+	return len(students), nil
+}
+
+// teacherByIDString looks up a teacher from an ID taken from the URL path.
+// The caller must hold the lock.
+func (t *TeacherTable) teacherByIDString(teacherId string) (models.Teacher, bool) {
+	id, err := strconv.Atoi(teacherId)
+	if err != nil {
+		return models.Teacher{}, false
+	}
+	teacher, ok := t.rows[id]
+	return teacher, ok
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"sync"
+	"time"
+)
+
+// TokenStore is the token repository. Refresh tokens are keyed by their
+// hash and revoked access tokens by their ID.
+type TokenStore struct {
+	mu      *sync.RWMutex
+	refresh map[string]models.RefreshToken
+	revoked map[string]time.Time
+	nextID  int
+}
+
+// newTokenStore creates an empty token store guarded by the store's lock.
+func newTokenStore(mu *sync.RWMutex) *TokenStore {
+	return &TokenStore{
+		mu:      mu,
+		refresh: make(map[string]models.RefreshToken),
+		revoked: make(map[string]time.Time),
+		nextID:  1,
+	}
+}
+
+// put stores a new refresh token. The caller must hold the lock.
+func (s *TokenStore) put(token models.RefreshToken) error {
+	if _, ok := s.refresh[token.TokenHash]; ok {
+		return utils.ConflictError(errDuplicate, "Error saving refresh token")
+	}
+	// Mirror the DATETIME(6) and DATETIME columns
+	token.CreatedAt = token.CreatedAt.UTC().Truncate(time.Microsecond)
+	token.ExpiresAt = token.ExpiresAt.UTC().Truncate(time.Second)
+	token.ID = s.nextID
+	s.nextID++
+	s.refresh[token.TokenHash] = token
+	return nil
+}
+
+// revokeFamily revokes every unrevoked token of a family. The caller must hold the lock.
+func (s *TokenStore) revokeFamily(familyID string, now time.Time) {
+	for hash, token := range s.refresh {
+		if token.FamilyID == familyID && token.RevokedAt.IsZero() {
+			token.RevokedAt = now.UTC().Truncate(time.Second)
+			s.refresh[hash] = token
+		}
+	}
+}
+
+// CreateRefreshToken stores a new refresh token.
+func (s *TokenStore) CreateRefreshToken(token models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.put(token)
+}
+
+// RotateRefreshToken replaces a valid refresh token with next, revoking the
+// whole family when a revoked token is presented again.
+func (s *TokenStore) RotateRefreshToken(tokenHash string, next models.RefreshToken, now time.Time) (models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refresh[tokenHash]
+	if !ok || !token.ExpiresAt.After(now) {
+		return models.RefreshToken{}, utils.NotFoundError(nil, "refresh token is invalid or has expired")
+	}
+	if !token.RevokedAt.IsZero() {
+		s.revokeFamily(token.FamilyID, now)
+		return models.RefreshToken{}, utils.UnauthorizedError(repository.ErrRefreshTokenReused,
+			"refresh token was already used, every session of this login has been revoked")
+	}
+
+	token.RevokedAt = now.UTC().Truncate(time.Second)
+	s.refresh[tokenHash] = token
+
+	next.FamilyID = token.FamilyID
+	next.ExecutiveID = token.ExecutiveID
+	if err := s.put(next); err != nil {
+		return models.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+// RevokeRefreshToken revokes the family of a refresh token of the executive execID.
+func (s *TokenStore) RevokeRefreshToken(tokenHash string, execID int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refresh[tokenHash]
+	if !ok {
+		return nil
+	}
+	if token.ExecutiveID != execID {
+		return utils.ForbiddenError(nil, "refresh token belongs to another executive")
+	}
+	s.revokeFamily(token.FamilyID, now)
+	return nil
+}
+
+// RevokeAccessToken deny-lists an access token ID until it expires.
+// Entries whose token has expired are dropped on the way.
+func (s *TokenStore) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, expires := range s.revoked {
+		if !expires.After(now) {
+			delete(s.revoked, id)
+		}
+	}
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token ID is deny-listed.
+func (s *TokenStore) IsAccessTokenRevoked(jti string, now time.Time) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expires, ok := s.revoked[jti]
+	return ok && expires.After(now), nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"school_management_api/pkg/utils"
+	"slices"
+	"sync"
+	"time"
+)
+
+// TwoFactorStore is the two-factor repository. Recovery codes are keyed by
+// executive and code hash, with the time they were used.
+type TwoFactorStore struct {
+	mu       *sync.RWMutex
+	recovery map[int]map[string]time.Time
+	roles    []string
+}
+
+// newTwoFactorStore creates an empty two-factor store guarded by the store's lock.
+func newTwoFactorStore(mu *sync.RWMutex) *TwoFactorStore {
+	return &TwoFactorStore{mu: mu, recovery: make(map[int]map[string]time.Time)}
+}
+
+// ReplaceRecoveryCodes replaces every recovery code of the executive.
+func (s *TwoFactorStore) ReplaceRecoveryCodes(execID int, codeHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	codes := make(map[string]time.Time, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes[hash] = time.Time{}
+	}
+	s.recovery[execID] = codes
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of the executive as used.
+func (s *TwoFactorStore) UseRecoveryCode(execID int, codeHash string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usedAt, ok := s.recovery[execID][codeHash]
+	if !ok || !usedAt.IsZero() {
+		return utils.NotFoundError(errNotFound, "recovery code is invalid or was already used")
+	}
+	s.recovery[execID][codeHash] = now.UTC().Truncate(time.Second)
+	return nil
+}
+
+// RequiredRoles returns the roles that must use two-factor authentication.
+func (s *TwoFactorStore) RequiredRoles() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string{}, s.roles...), nil
+}
+
+// SetRequiredRoles replaces the roles that must use two-factor authentication.
+func (s *TwoFactorStore) SetRequiredRoles(roles []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roles = slices.Compact(slices.Sorted(slices.Values(roles)))
+	return nil
+}
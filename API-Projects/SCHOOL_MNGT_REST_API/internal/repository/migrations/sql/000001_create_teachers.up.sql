@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS teachers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    class VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    UNIQUE KEY uq_teachers_email (email),
+    INDEX idx_teachers_class (class)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
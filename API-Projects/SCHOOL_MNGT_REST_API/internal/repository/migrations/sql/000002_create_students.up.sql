@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS students (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    class VARCHAR(255) NOT NULL,
+    UNIQUE KEY uq_students_email (email),
+    INDEX idx_students_class (class),
+    FOREIGN KEY (class) REFERENCES teachers (class)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
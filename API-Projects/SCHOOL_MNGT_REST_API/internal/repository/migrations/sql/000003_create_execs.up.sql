@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS execs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    password_changed_at DATETIME NULL,
+    user_created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
+    password_reset_token VARCHAR(255) NULL,
+    password_token_expires DATETIME NULL,
+    inactive_status BOOLEAN NOT NULL DEFAULT FALSE,
+    role VARCHAR(50) NOT NULL,
+    UNIQUE KEY uq_execs_email (email),
+    UNIQUE KEY uq_execs_username (username)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS two_factor_roles;
+DROP TABLE IF EXISTS recovery_codes;
+ALTER TABLE execs
+    DROP COLUMN totp_last_step,
+    DROP COLUMN totp_enabled,
+    DROP COLUMN totp_secret;
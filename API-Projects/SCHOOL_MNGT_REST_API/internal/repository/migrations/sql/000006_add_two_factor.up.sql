@@ -0,0 +1,17 @@
+ALTER TABLE execs
+    ADD COLUMN totp_secret VARCHAR(64) NULL,
+    ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN totp_last_step BIGINT NULL;
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    exec_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at DATETIME NULL,
+    UNIQUE KEY uq_recovery_codes_exec_id_code_hash (exec_id, code_hash),
+    FOREIGN KEY (exec_id) REFERENCES execs (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS two_factor_roles (
+    role VARCHAR(50) PRIMARY KEY
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
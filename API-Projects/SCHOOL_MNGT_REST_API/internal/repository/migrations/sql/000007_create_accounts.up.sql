@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS accounts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    teacher_id INT NULL,
+    student_id INT NULL,
+    username VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    password_changed_at DATETIME NULL,
+    inactive_status BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_accounts_username (username),
+    UNIQUE KEY uq_accounts_teacher_id (teacher_id),
+    UNIQUE KEY uq_accounts_student_id (student_id),
+    CONSTRAINT chk_accounts_subject CHECK ((teacher_id IS NULL) <> (student_id IS NULL)),
+    FOREIGN KEY (teacher_id) REFERENCES teachers (id) ON DELETE CASCADE,
+    FOREIGN KEY (student_id) REFERENCES students (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
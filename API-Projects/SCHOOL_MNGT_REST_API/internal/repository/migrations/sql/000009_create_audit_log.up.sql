@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id INT NOT NULL,
+    actor_name VARCHAR(255) NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    entity VARCHAR(50) NOT NULL,
+    entity_id INT NOT NULL,
+    changes LONGTEXT NOT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_audit_log_entity (entity, entity_id),
+    INDEX idx_audit_log_actor (actor_type, actor_id),
+    INDEX idx_audit_log_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
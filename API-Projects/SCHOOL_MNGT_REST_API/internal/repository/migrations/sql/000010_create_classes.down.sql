@@ -0,0 +1,30 @@
+-- Classes go back to strings: students keep the name of their class and
+-- teachers that of their latest class. This fails when a class has
+-- students but no teacher, as the old foreign key requires one.
+ALTER TABLE teachers ADD COLUMN class VARCHAR(255) NOT NULL DEFAULT '' AFTER email;
+
+UPDATE teachers t
+SET t.class = COALESCE((
+    SELECT c.name FROM class_teachers ct JOIN classes c ON c.id = ct.class_id
+    WHERE ct.teacher_id = t.id ORDER BY c.academic_year DESC, c.id LIMIT 1
+), '');
+
+ALTER TABLE teachers
+    ALTER COLUMN class DROP DEFAULT,
+    ADD INDEX idx_teachers_class (class);
+
+ALTER TABLE students ADD COLUMN class VARCHAR(255) NOT NULL DEFAULT '' AFTER email;
+
+UPDATE students s JOIN classes c ON c.id = s.class_id SET s.class = c.name;
+
+ALTER TABLE students DROP FOREIGN KEY fk_students_class_id;
+
+ALTER TABLE students
+    DROP INDEX idx_students_class_id,
+    DROP COLUMN class_id,
+    ALTER COLUMN class DROP DEFAULT,
+    ADD INDEX idx_students_class (class),
+    ADD CONSTRAINT students_ibfk_1 FOREIGN KEY (class) REFERENCES teachers (class);
+
+DROP TABLE IF EXISTS class_teachers;
+DROP TABLE IF EXISTS classes;
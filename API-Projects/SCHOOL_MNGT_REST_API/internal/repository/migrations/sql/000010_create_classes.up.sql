@@ -0,0 +1,74 @@
+-- Students cannot be placed in a class without a grade from 1 to 12, so
+-- the migration stops before changing anything when one has such a class.
+IF EXISTS (SELECT 1 FROM students WHERE class NOT REGEXP '^(1[0-2]|[1-9])([^0-9]|$)')
+THEN SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'students.class must start with a grade from 1 to 12, rename those classes first'; END IF;
+
+CREATE TABLE IF NOT EXISTS classes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    grade_level TINYINT UNSIGNED NOT NULL,
+    capacity INT NOT NULL,
+    homeroom_teacher_id INT NULL,
+    academic_year CHAR(9) NOT NULL,
+    UNIQUE KEY uq_classes_name_academic_year (name, academic_year),
+    INDEX idx_classes_academic_year (academic_year),
+    FOREIGN KEY (homeroom_teacher_id) REFERENCES teachers (id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS class_teachers (
+    class_id INT NOT NULL,
+    teacher_id INT NOT NULL,
+    PRIMARY KEY (class_id, teacher_id),
+    INDEX idx_class_teachers_teacher_id (teacher_id),
+    FOREIGN KEY (class_id) REFERENCES classes (id) ON DELETE CASCADE,
+    FOREIGN KEY (teacher_id) REFERENCES teachers (id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- Existing class strings become classes of the current academic year,
+-- which starts in August, graded by their leading number. The first
+-- teacher of a class is its homeroom teacher and the capacity fits at
+-- least its current students. Teacher class strings without a grade, e.g.
+-- "Physics 808", named rooms rather than classes and are dropped.
+INSERT INTO classes (name, grade_level, capacity, homeroom_teacher_id, academic_year)
+SELECT names.class,
+    CAST(REGEXP_SUBSTR(names.class, '^[0-9]+') AS UNSIGNED),
+    GREATEST(30, (SELECT COUNT(*) FROM students s WHERE s.class = names.class)),
+    (SELECT MIN(t.id) FROM teachers t WHERE t.class = names.class),
+    CONCAT(YEAR(CURDATE()) - IF(MONTH(CURDATE()) < 8, 1, 0), '-', YEAR(CURDATE()) + IF(MONTH(CURDATE()) < 8, 0, 1))
+FROM (SELECT class FROM teachers UNION SELECT class FROM students) AS names
+WHERE names.class REGEXP '^(1[0-2]|[1-9])([^0-9]|$)';
+
+INSERT INTO class_teachers (class_id, teacher_id)
+SELECT c.id, t.id FROM teachers t JOIN classes c ON c.name = t.class;
+
+ALTER TABLE students ADD COLUMN class_id INT NULL AFTER email;
+
+UPDATE students s JOIN classes c ON c.name = s.class SET s.class_id = c.id;
+
+-- The foreign key to teachers.class is created unnamed by 000002, so its
+-- generated name is looked up
+SET @students_class_fk = (
+    SELECT CONSTRAINT_NAME FROM information_schema.KEY_COLUMN_USAGE
+    WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'students' AND COLUMN_NAME = 'class'
+        AND REFERENCED_TABLE_NAME = 'teachers'
+    LIMIT 1
+);
+
+SET @drop_students_class_fk = COALESCE(CONCAT('ALTER TABLE students DROP FOREIGN KEY `', @students_class_fk, '`'), 'DO 0');
+
+PREPARE drop_students_class_fk FROM @drop_students_class_fk;
+
+EXECUTE drop_students_class_fk;
+
+DEALLOCATE PREPARE drop_students_class_fk;
+
+ALTER TABLE students
+    DROP INDEX idx_students_class,
+    DROP COLUMN class,
+    MODIFY class_id INT NOT NULL,
+    ADD INDEX idx_students_class_id (class_id),
+    ADD CONSTRAINT fk_students_class_id FOREIGN KEY (class_id) REFERENCES classes (id);
+
+ALTER TABLE teachers
+    DROP INDEX idx_teachers_class,
+    DROP COLUMN class;
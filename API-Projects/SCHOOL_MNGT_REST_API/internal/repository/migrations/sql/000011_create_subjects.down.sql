@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS enrollments;
+DROP TABLE IF EXISTS subjects;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS subjects (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    capacity INT NOT NULL,
+    UNIQUE KEY uq_subjects_name (name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS enrollments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    student_id INT NOT NULL,
+    subject_id INT NOT NULL,
+    teacher_id INT NOT NULL,
+    academic_year CHAR(9) NOT NULL,
+    term TINYINT UNSIGNED NOT NULL,
+    UNIQUE KEY uq_enrollments_student_subject_term (student_id, subject_id, academic_year, term),
+    INDEX idx_enrollments_subject_teacher_term (subject_id, teacher_id, academic_year, term),
+    INDEX idx_enrollments_teacher_id (teacher_id),
+    FOREIGN KEY (student_id) REFERENCES students (id),
+    FOREIGN KEY (subject_id) REFERENCES subjects (id),
+    FOREIGN KEY (teacher_id) REFERENCES teachers (id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- The subjects teachers already teach become subjects of their own
+INSERT INTO subjects (name, capacity)
+SELECT DISTINCT subject, 30 FROM teachers WHERE subject <> '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS grades;
+DROP TABLE IF EXISTS assessments;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS assessments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    subject_id INT NOT NULL,
+    class_id INT NOT NULL,
+    weight DECIMAL(5,2) NOT NULL,
+    max_score DECIMAL(6,2) NOT NULL,
+    date DATE NOT NULL,
+    academic_year CHAR(9) NOT NULL,
+    term TINYINT UNSIGNED NOT NULL,
+    INDEX idx_assessments_class_term (class_id, academic_year, term),
+    INDEX idx_assessments_subject_id (subject_id),
+    FOREIGN KEY (subject_id) REFERENCES subjects (id),
+    FOREIGN KEY (class_id) REFERENCES classes (id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- The scores of an assessment go with it, students keep theirs
+CREATE TABLE IF NOT EXISTS grades (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    assessment_id INT NOT NULL,
+    student_id INT NOT NULL,
+    score DECIMAL(6,2) NOT NULL,
+    UNIQUE KEY uq_grades_assessment_student (assessment_id, student_id),
+    INDEX idx_grades_student_id (student_id),
+    FOREIGN KEY (assessment_id) REFERENCES assessments (id) ON DELETE CASCADE,
+    FOREIGN KEY (student_id) REFERENCES students (id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,15 @@
+-- Period 0 is the attendance of the whole day
+CREATE TABLE IF NOT EXISTS attendance (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    student_id INT NOT NULL,
+    class_id INT NOT NULL,
+    date DATE NOT NULL,
+    period TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    status VARCHAR(10) NOT NULL,
+    note VARCHAR(500) NOT NULL DEFAULT '',
+    UNIQUE KEY uq_attendance_student_date_period (student_id, date, period),
+    INDEX idx_attendance_class_date (class_id, date),
+    INDEX idx_attendance_date (date),
+    FOREIGN KEY (student_id) REFERENCES students (id),
+    FOREIGN KEY (class_id) REFERENCES classes (id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,8 @@
+ALTER TABLE refresh_tokens
+    MODIFY COLUMN created_at DATETIME NOT NULL;
+
+ALTER TABLE accounts
+    MODIFY COLUMN password_changed_at DATETIME NULL;
+
+ALTER TABLE execs
+    MODIFY COLUMN password_changed_at DATETIME NULL;
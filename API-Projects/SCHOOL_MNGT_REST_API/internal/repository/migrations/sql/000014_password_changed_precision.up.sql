@@ -0,0 +1,8 @@
+ALTER TABLE execs
+    MODIFY COLUMN password_changed_at DATETIME(6) NULL;
+
+ALTER TABLE accounts
+    MODIFY COLUMN password_changed_at DATETIME(6) NULL;
+
+ALTER TABLE refresh_tokens
+    MODIFY COLUMN created_at DATETIME(6) NOT NULL;
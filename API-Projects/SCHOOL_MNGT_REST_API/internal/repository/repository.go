@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"time"
+)
+
+// Resource defines the data operations available on every entity. The SQL
+// and in-memory stores implement it once for all models, driven by the
+// model's db tags and its Entity definition. Every write records an audit
+// entry per item, by the actor of ctx, along with the change.
+type Resource[T any] interface {
+	// List returns one page of the items matching the filters and sort
+	// order of spec, along with the total number of matching items.
+	List(spec utils.QuerySpec, page utils.Pagination) ([]T, int, error)
+	GetByID(id int) (T, error)
+	// Create stores all items or none of them and returns them with their IDs.
+	Create(ctx context.Context, items []T) ([]T, error)
+	// Update replaces every visible column of the item with the given ID.
+	Update(ctx context.Context, id int, item T) (T, error)
+	// Patch updates the fields, keyed by JSON name, of the item with the given ID.
+	Patch(ctx context.Context, id int, fields map[string]interface{}) (T, error)
+	// PatchMany applies partial updates that each carry the item's id.
+	// Either every update is applied or none of them.
+	PatchMany(ctx context.Context, updates []map[string]interface{}) ([]T, error)
+	Delete(ctx context.Context, id int) error
+	// DeleteMany deletes all items or none of them and returns the deleted IDs.
+	DeleteMany(ctx context.Context, IDs []int) ([]int, error)
+}
+
+// StudentRepository defines the data operations available on students.
+type StudentRepository interface {
+	Resource[models.Student]
+}
+
+// TeacherRepository defines the data operations available on teachers.
+// The students of a teacher are those enrolled with the teacher in any
+// subject and term.
+type TeacherRepository interface {
+	Resource[models.Teacher]
+	GetStudentsByTeacherID(teacherId string) ([]models.Student, error)
+	GetStudentCountByTeacherID(teacherId string) (int, error)
+}
+
+// ClassRepository defines the data operations available on classes and
+// the teachers assigned to them. Changes to the assignments are audited as
+// updates of the class.
+type ClassRepository interface {
+	Resource[models.Class]
+	// GetStudentsByClassID returns the students of a class. It returns a
+	// NotFound error when there is no such class.
+	GetStudentsByClassID(id int) ([]models.Student, error)
+	// GetTeachersByClassID returns the teachers assigned to a class. It
+	// returns a NotFound error when there is no such class.
+	GetTeachersByClassID(id int) ([]models.Teacher, error)
+	// AssignTeacher assigns a teacher to a class; assigning a teacher twice
+	// changes nothing. It returns a NotFound error when the class or the
+	// teacher does not exist.
+	AssignTeacher(ctx context.Context, classID int, teacherID int) error
+	// UnassignTeacher removes a teacher from a class. It returns a NotFound
+	// error when the teacher is not assigned to the class.
+	UnassignTeacher(ctx context.Context, classID int, teacherID int) error
+}
+
+// SubjectRepository defines the data operations available on subjects.
+type SubjectRepository interface {
+	Resource[models.Subject]
+}
+
+// EnrollmentRepository defines the data operations available on
+// enrollments. Create enrolls every student or none of them; it returns a
+// Conflict error, see EnrollmentFullError, when a teacher would take more
+// students in a subject and term than the capacity of the subject.
+type EnrollmentRepository interface {
+	Resource[models.Enrollment]
+}
+
+// AssessmentRepository defines the data operations available on assessments
+// and the grades given in them. Grades are audited as writes of their own.
+// Deleting an assessment deletes its grades.
+type AssessmentRepository interface {
+	Resource[models.Assessment]
+	// GetGradesByAssessmentID returns the grades of an assessment. It
+	// returns a NotFound error when there is no such assessment.
+	GetGradesByAssessmentID(id int) ([]models.Grade, error)
+	// SetGrades stores the scores of students in an assessment, replacing
+	// their earlier scores, and returns the stored grades. Every score is
+	// stored or none; see CheckGrades for the Validation errors.
+	SetGrades(ctx context.Context, assessmentID int, grades []models.Grade) ([]models.Grade, error)
+	// GetGradesByStudentID returns the grades of a student with their
+	// assessments, in date order. An empty academic year or a zero term
+	// selects all of them. It returns a NotFound error when there is no
+	// such student.
+	GetGradesByStudentID(id int, academicYear string, term int) ([]models.StudentGrade, error)
+	// GetClassGrades returns the assessments of a class in a term and every
+	// grade given in them.
+	GetClassGrades(classID int, academicYear string, term int) ([]models.Assessment, []models.Grade, error)
+}
+
+// AttendanceRepository defines the data operations available on attendance
+// records. Dates are formatted as 2006-01-02 and the reports count every
+// record in the date range, a whole day and each period alike.
+type AttendanceRepository interface {
+	Resource[models.Attendance]
+	// TakeRoll stores the attendance of every student of a class on the
+	// date and period of the roll, replacing earlier records of the same
+	// date and period, and returns the stored records. Every record is
+	// stored or none; see RollRecords for the Validation errors. It returns
+	// a NotFound error when there is no such class.
+	TakeRoll(ctx context.Context, classID int, roll models.Roll) ([]models.Attendance, error)
+	// GetRegister returns the attendance records of a class from one date
+	// to another, ordered by date, period and student. It returns a
+	// NotFound error when there is no such class.
+	GetRegister(classID int, from string, to string) ([]models.Attendance, error)
+	// SummarizeByStudent counts the records of the filter per student and
+	// class, ordered by class and student.
+	SummarizeByStudent(filter AttendanceFilter) ([]models.AttendanceSummary, error)
+	// SummarizeByClass counts the records of the filter per class, ordered by class.
+	SummarizeByClass(filter AttendanceFilter) ([]models.AttendanceSummary, error)
+}
+
+// ExecutiveRepository defines the data operations available on executives.
+type ExecutiveRepository interface {
+	Resource[models.Executive]
+	// GetUserByUsername returns the executive including the stored password hash.
+	GetUserByUsername(username string) (models.Executive, error)
+	// GetUserByID returns the executive including the stored password hash
+	// and the time of the latest password change.
+	GetUserByID(id int) (models.Executive, error)
+	// UpdatePassword stores a new password hash and the time it was changed.
+	UpdatePassword(ctx context.Context, id int, passwordHash string, changedAt time.Time) error
+	// ReplacePasswordHash stores a new hash of the same password, e.g. made
+	// with stronger parameters, unless the stored hash is no longer oldHash.
+	// The password does not count as changed, so sessions are kept.
+	ReplacePasswordHash(ctx context.Context, id int, oldHash string, newHash string) error
+	// SetPasswordResetToken stores the hash of a password reset token and
+	// its expiry, replacing any earlier token of the executive.
+	SetPasswordResetToken(ctx context.Context, id int, tokenHash string, expires time.Time) error
+	// ResetPassword sets the password hash of the executive holding the
+	// unexpired token and clears the token, so it can be used only once.
+	// It returns a NotFound error when no executive holds a valid token.
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string, now time.Time) error
+	// SetTOTP stores the TOTP secret of the executive and whether two-factor
+	// authentication is enabled, forgetting the last used code.
+	SetTOTP(ctx context.Context, id int, secret sql.NullString, enabled bool) error
+	// UseTOTPStep records that the code of a time step was used and reports
+	// false when a code of that step, or a later one, was already used.
+	UseTOTPStep(ctx context.Context, id int, step int64) (bool, error)
+}
+
+// ErrRefreshTokenReused is wrapped by the error of RotateRefreshToken when a
+// token that was already rotated or revoked is presented again.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+// TokenRepository stores the refresh tokens issued at login and the IDs of
+// the access tokens revoked before they expire.
+type TokenRepository interface {
+	// CreateRefreshToken stores a new refresh token.
+	CreateRefreshToken(token models.RefreshToken) error
+	// RotateRefreshToken revokes the valid refresh token with the given hash
+	// and stores next in its place, in the same family and for the same
+	// executive. It returns the rotated token. A token that was already
+	// revoked revokes its whole family and returns an Unauthorized error
+	// wrapping ErrRefreshTokenReused; an unknown or expired token returns a
+	// NotFound error.
+	RotateRefreshToken(tokenHash string, next models.RefreshToken, now time.Time) (models.RefreshToken, error)
+	// RevokeRefreshToken revokes every token in the family of the refresh
+	// token with the given hash, which must belong to the executive execID:
+	// a token of another executive returns a Forbidden error and revokes
+	// nothing. Unknown tokens are ignored.
+	RevokeRefreshToken(tokenHash string, execID int, now time.Time) error
+	// RevokeAccessToken deny-lists the ID (jti) of an access token until it expires.
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	// IsAccessTokenRevoked reports whether the access token ID is deny-listed.
+	IsAccessTokenRevoked(jti string, now time.Time) (bool, error)
+}
+
+// LoginAttemptRepository keeps the failed login counters of usernames and
+// client IPs.
+type LoginAttemptRepository interface {
+	// GetLoginAttempts returns the counter of key, or a zero counter when it has none.
+	GetLoginAttempts(key string) (models.LoginAttempts, error)
+	// RecordLoginFailure adds a failure to the counter of key and returns it.
+	// A counter whose last failure is before resetBefore starts again from one.
+	RecordLoginFailure(key string, now time.Time, resetBefore time.Time) (models.LoginAttempts, error)
+	// LockLogin blocks the logins of key until the given time.
+	LockLogin(key string, until time.Time) error
+	// ResetLoginAttempts clears the counter and the lock of key.
+	ResetLoginAttempts(key string) error
+}
+
+// TwoFactorRepository stores the recovery codes of executives using
+// two-factor authentication and the roles that must use it.
+type TwoFactorRepository interface {
+	// ReplaceRecoveryCodes replaces every recovery code of the executive
+	// with the given hashes.
+	ReplaceRecoveryCodes(execID int, codeHashes []string) error
+	// UseRecoveryCode marks the unused recovery code with the given hash as
+	// used. It returns a NotFound error when the executive has no such code.
+	UseRecoveryCode(execID int, codeHash string, now time.Time) error
+	// RequiredRoles returns the roles that must use two-factor authentication.
+	RequiredRoles() ([]string, error)
+	// SetRequiredRoles replaces the roles that must use two-factor authentication.
+	SetRequiredRoles(roles []string) error
+}
+
+// AccountRepository stores the logins of teachers and students. An account
+// is deleted along with its teacher or student.
+type AccountRepository interface {
+	// CreateAccount stores a new account and returns it with its ID. It
+	// returns a Conflict error when the username is taken or the subject
+	// already has an account, and a Validation error when the subject does not exist.
+	CreateAccount(ctx context.Context, account models.Account) (models.Account, error)
+	// GetAccountByUsername returns the account including its password hash.
+	GetAccountByUsername(username string) (models.Account, error)
+	// GetAccountBySubject returns the account of a teacher or student,
+	// including its password hash.
+	GetAccountBySubject(subjectType string, subjectID int) (models.Account, error)
+	// UpdateAccountPassword stores a new password hash and the time it was changed.
+	UpdateAccountPassword(ctx context.Context, id int, passwordHash string, changedAt time.Time) error
+	// ReplaceAccountPasswordHash stores a new hash of the same password,
+	// e.g. made with stronger parameters, unless the stored hash is no longer
+	// oldHash. The password does not count as changed.
+	ReplaceAccountPasswordHash(ctx context.Context, id int, oldHash string, newHash string) error
+	// DeleteAccount deletes the account of a teacher or student.
+	DeleteAccount(ctx context.Context, subjectType string, subjectID int) error
+}
+
+// APIKeyRepository stores the API keys of executives.
+type APIKeyRepository interface {
+	// CreateAPIKey stores a new key and returns it with its ID.
+	CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error)
+	// ListAPIKeys returns the keys of an executive, revoked ones included,
+	// newest first.
+	ListAPIKeys(execID int) ([]models.APIKey, error)
+	// GetAPIKeyByHash returns the key with the given hash, or a NotFound error.
+	GetAPIKeyByHash(keyHash string) (models.APIKey, error)
+	// RevokeAPIKey revokes a key of an executive. It returns a NotFound error
+	// when the executive has no such key.
+	RevokeAPIKey(ctx context.Context, execID int, id int, now time.Time) error
+	// TouchAPIKey records that a key was used.
+	TouchAPIKey(id int, now time.Time) error
+}
@@ -0,0 +1,209 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"time"
+)
+
+// AccountTable is the account repository, backed by the accounts table.
+// Each account references its teacher or its student in its own column,
+// so the foreign keys delete it along with them.
+type AccountTable struct {
+	db *sql.DB
+}
+
+// NewAccountTable creates the account repository backed by db.
+func NewAccountTable(db *sql.DB) *AccountTable {
+	return &AccountTable{db: db}
+}
+
+// accountColumns are the columns read by scanAccount.
+const accountColumns = "id, teacher_id, student_id, username, password, password_changed_at, inactive_status, created_at"
+
+// subjectColumn returns the column referencing subjects of the given type.
+func subjectColumn(subjectType string) (string, error) {
+	switch subjectType {
+	case models.SubjectTeacher:
+		return "teacher_id", nil
+	case models.SubjectStudent:
+		return "student_id", nil
+	}
+	return "", utils.ValidationError(nil, fmt.Sprintf("unknown subject type %q", subjectType))
+}
+
+// scanAccount reads one account row.
+func scanAccount(row *sql.Row, message string) (models.Account, error) {
+	var account models.Account
+	var teacherID, studentID sql.NullInt64
+	err := row.Scan(&account.ID, &teacherID, &studentID, &account.Username, &account.Password,
+		&account.PasswordChangedAt, &account.InactiveStatus, &account.CreatedAt)
+	if err != nil {
+		return models.Account{}, dbError(err, message)
+	}
+
+	if teacherID.Valid {
+		account.SubjectType, account.SubjectID = models.SubjectTeacher, int(teacherID.Int64)
+	} else {
+		account.SubjectType, account.SubjectID = models.SubjectStudent, int(studentID.Int64)
+	}
+	return account, nil
+}
+
+// CreateAccount stores a new account.
+func (t *AccountTable) CreateAccount(ctx context.Context, account models.Account) (models.Account, error) {
+	message := "Error creating account"
+	column, err := subjectColumn(account.SubjectType)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return models.Account{}, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	account.CreatedAt = now.UTC().Format(time.DateTime)
+	query := fmt.Sprintf("INSERT INTO accounts (%s, username, password, created_at) VALUES (?, ?, ?, ?)", column)
+	result, err := tx.Exec(query, account.SubjectID, account.Username, account.Password, account.CreatedAt)
+	if err != nil {
+		return models.Account{}, dbError(err, message)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return models.Account{}, dbError(err, message)
+	}
+	account.ID = int(id)
+
+	if err := insertAudit(tx, repository.AccountAuditEntry(ctx, models.AuditCreate, account.ID, nil, &account, now)); err != nil {
+		return models.Account{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Account{}, dbError(err, message)
+	}
+	return account, nil
+}
+
+// GetAccountByUsername returns the account with the username.
+func (t *AccountTable) GetAccountByUsername(username string) (models.Account, error) {
+	query := fmt.Sprintf("SELECT %s FROM accounts WHERE username = ?", accountColumns)
+	return scanAccount(t.db.QueryRow(query, username), "account not found in database")
+}
+
+// GetAccountBySubject returns the account of a teacher or student.
+func (t *AccountTable) GetAccountBySubject(subjectType string, subjectID int) (models.Account, error) {
+	column, err := subjectColumn(subjectType)
+	if err != nil {
+		return models.Account{}, err
+	}
+	query := fmt.Sprintf("SELECT %s FROM accounts WHERE %s = ?", accountColumns, column)
+	return scanAccount(t.db.QueryRow(query, subjectID), fmt.Sprintf("%s with ID %d has no account", subjectType, subjectID))
+}
+
+// UpdateAccountPassword stores a new password hash and the time it was changed.
+func (t *AccountTable) UpdateAccountPassword(ctx context.Context, id int, passwordHash string, changedAt time.Time) error {
+	message := "Error updating password"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("SELECT %s FROM accounts WHERE id = ? FOR UPDATE", accountColumns)
+	before, err := scanAccount(tx.QueryRow(query, id), fmt.Sprintf("account with ID %d not found", id))
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.Password = passwordHash
+	after.PasswordChangedAt = sql.NullString{String: changedAt.UTC().Format(utils.DateTimeMicro), Valid: true}
+	if _, err := tx.Exec("UPDATE accounts SET password = ?, password_changed_at = ? WHERE id = ?",
+		after.Password, after.PasswordChangedAt, id); err != nil {
+		return dbError(err, message)
+	}
+	if err := insertAudit(tx, repository.AccountAuditEntry(ctx, models.AuditUpdate, id, &before, &after, time.Now())); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// ReplaceAccountPasswordHash stores a new hash of the same password unless
+// the password was changed meanwhile.
+func (t *AccountTable) ReplaceAccountPasswordHash(ctx context.Context, id int, oldHash string, newHash string) error {
+	message := "Error updating password"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("SELECT %s FROM accounts WHERE id = ? FOR UPDATE", accountColumns)
+	before, err := scanAccount(tx.QueryRow(query, id), fmt.Sprintf("account with ID %d not found", id))
+	if err != nil {
+		return err
+	}
+	if before.Password != oldHash {
+		return nil
+	}
+
+	after := before
+	after.Password = newHash
+	if _, err := tx.Exec("UPDATE accounts SET password = ? WHERE id = ?", after.Password, id); err != nil {
+		return dbError(err, message)
+	}
+	if err := insertAudit(tx, repository.AccountAuditEntry(ctx, models.AuditUpdate, id, &before, &after, time.Now())); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// DeleteAccount deletes the account of a teacher or student.
+func (t *AccountTable) DeleteAccount(ctx context.Context, subjectType string, subjectID int) error {
+	message := "Error deleting account"
+	column, err := subjectColumn(subjectType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("SELECT %s FROM accounts WHERE %s = ? FOR UPDATE", accountColumns, column)
+	account, err := scanAccount(tx.QueryRow(query, subjectID), fmt.Sprintf("%s with ID %d has no account", subjectType, subjectID))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM accounts WHERE id = ?", account.ID); err != nil {
+		return dbError(err, message)
+	}
+	if err := insertAudit(tx, repository.AccountAuditEntry(ctx, models.AuditDelete, account.ID, &account, nil, time.Now())); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strings"
+	"time"
+)
+
+// APIKeyTable is the API key repository, backed by the api_keys table.
+// Scopes are stored in one column, separated by spaces.
+type APIKeyTable struct {
+	db *sql.DB
+}
+
+// NewAPIKeyTable creates the API key repository backed by db.
+func NewAPIKeyTable(db *sql.DB) *APIKeyTable {
+	return &APIKeyTable{db: db}
+}
+
+// apiKeyColumns are the columns read by scanAPIKey.
+const apiKeyColumns = "id, exec_id, name, key_prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked_at"
+
+// scanAPIKey reads one api_keys row from a *sql.Row or *sql.Rows.
+func scanAPIKey(row interface{ Scan(dest ...any) error }, message string) (models.APIKey, error) {
+	var key models.APIKey
+	var scopes, createdAt, expiresAt string
+	var lastUsedAt, revokedAt sql.NullString
+	err := row.Scan(&key.ID, &key.ExecutiveID, &key.Name, &key.Prefix, &key.KeyHash, &scopes,
+		&createdAt, &expiresAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return models.APIKey{}, dbError(err, message)
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	if key.CreatedAt, err = time.ParseInLocation(time.DateTime, createdAt, time.UTC); err != nil {
+		return models.APIKey{}, utils.ErrorHandler(err, message)
+	}
+	if key.ExpiresAt, err = time.ParseInLocation(time.DateTime, expiresAt, time.UTC); err != nil {
+		return models.APIKey{}, utils.ErrorHandler(err, message)
+	}
+	if key.LastUsedAt, err = parseNullTime(lastUsedAt); err != nil {
+		return models.APIKey{}, utils.ErrorHandler(err, message)
+	}
+	if key.RevokedAt, err = parseNullTime(revokedAt); err != nil {
+		return models.APIKey{}, utils.ErrorHandler(err, message)
+	}
+	return key, nil
+}
+
+// parseNullTime parses a nullable DATETIME column, nil when it is NULL.
+func parseNullTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(time.DateTime, value.String, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateAPIKey stores a new key.
+func (t *APIKeyTable) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	message := "Error creating API key"
+	key.CreatedAt = key.CreatedAt.UTC().Truncate(time.Second)
+	key.ExpiresAt = key.ExpiresAt.UTC().Truncate(time.Second)
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return models.APIKey{}, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO api_keys (exec_id, name, key_prefix, key_hash, scopes, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, key.ExecutiveID, key.Name, key.Prefix, key.KeyHash, strings.Join(key.Scopes, " "),
+		key.CreatedAt.Format(time.DateTime), key.ExpiresAt.Format(time.DateTime))
+	if err != nil {
+		return models.APIKey{}, dbError(err, message)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return models.APIKey{}, dbError(err, message)
+	}
+	key.ID = int(id)
+
+	if err := insertAudit(tx, repository.APIKeyAuditEntry(ctx, models.AuditCreate, key.ID, nil, &key, key.CreatedAt)); err != nil {
+		return models.APIKey{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.APIKey{}, dbError(err, message)
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns the keys of an executive, newest first.
+func (t *APIKeyTable) ListAPIKeys(execID int) ([]models.APIKey, error) {
+	message := "Error retrieving API keys"
+
+	rows, err := t.db.Query("SELECT "+apiKeyColumns+" FROM api_keys WHERE exec_id = ? ORDER BY id DESC", execID)
+	if err != nil {
+		return nil, dbError(err, message)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows, message)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, message)
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash returns the key with the given hash.
+func (t *APIKeyTable) GetAPIKeyByHash(keyHash string) (models.APIKey, error) {
+	row := t.db.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?", keyHash)
+	return scanAPIKey(row, "API key not found in database")
+}
+
+// RevokeAPIKey revokes a key of an executive. Revoking it again keeps the
+// first revocation time.
+func (t *APIKeyTable) RevokeAPIKey(ctx context.Context, execID int, id int, now time.Time) error {
+	message := "Error revoking API key"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys WHERE id = ? AND exec_id = ? FOR UPDATE", id, execID)
+	before, err := scanAPIKey(row, fmt.Sprintf("API key with ID %d not found", id))
+	if err != nil || before.RevokedAt != nil {
+		return err
+	}
+
+	after := before
+	revokedAt := now.UTC().Truncate(time.Second)
+	after.RevokedAt = &revokedAt
+	if _, err := tx.Exec("UPDATE api_keys SET revoked_at = ? WHERE id = ?", revokedAt.Format(time.DateTime), id); err != nil {
+		return dbError(err, message)
+	}
+	if err := insertAudit(tx, repository.APIKeyAuditEntry(ctx, models.AuditUpdate, id, &before, &after, now)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// TouchAPIKey records that a key was used.
+func (t *APIKeyTable) TouchAPIKey(id int, now time.Time) error {
+	if _, err := t.db.Exec("UPDATE api_keys SET last_used_at = ? WHERE id = ?", now.UTC().Format(time.DateTime), id); err != nil {
+		return dbError(err, "Error recording API key use")
+	}
+	return nil
+}
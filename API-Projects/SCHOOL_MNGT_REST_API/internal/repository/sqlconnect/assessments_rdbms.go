@@ -0,0 +1,175 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"time"
+)
+
+// AssessmentTable is the assessment repository. Besides the generic
+// operations it stores the grades given in the assessments.
+type AssessmentTable struct {
+	*Table[models.Assessment]
+	grades   *Table[models.Grade]
+	students *Table[models.Student]
+}
+
+// NewAssessmentTable creates the assessment repository backed by the connection pool.
+func NewAssessmentTable(db *sql.DB, students *Table[models.Student]) *AssessmentTable {
+	return &AssessmentTable{
+		Table:    NewTable(db, repository.Assessments),
+		grades:   NewTable(db, repository.Grades),
+		students: students,
+	}
+}
+
+// GetGradesByAssessmentID returns the grades of an assessment.
+func (t *AssessmentTable) GetGradesByAssessmentID(id int) ([]models.Grade, error) {
+	if _, err := t.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	grades, err := t.grades.queryRows(t.grades.selectQuery()+"assessment_id = ? ORDER BY student_id ASC", id)
+	if err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+	return grades, nil
+}
+
+// SetGrades stores the scores of students in an assessment. The assessment
+// is locked, so the scores of an assessment are stored one batch at a time.
+func (t *AssessmentTable) SetGrades(ctx context.Context, assessmentID int, grades []models.Grade) ([]models.Grade, error) {
+	message := repository.Grades.Message("storing %s data in")
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	assessment, err := t.scan(tx.QueryRow(t.selectQuery()+"id = ? FOR UPDATE", assessmentID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, t.entity.NotFoundError(err, assessmentID)
+		}
+		return nil, dbError(err, message)
+	}
+
+	classOf := func(studentID int) (int, bool, error) {
+		var classID int
+		err := tx.QueryRow("SELECT class_id FROM students WHERE id = ?", studentID).Scan(&classID)
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, dbError(err, message)
+		}
+		return classID, true, nil
+	}
+	if err := repository.CheckGrades(assessment, grades, classOf); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored := make([]models.Grade, len(grades))
+	for i, grade := range grades {
+		grade.AssessmentID = assessmentID
+
+		query := t.grades.selectQuery() + "assessment_id = ? AND student_id = ? FOR UPDATE"
+		existing, err := t.grades.scan(tx.QueryRow(query, assessmentID, grade.StudentID))
+		switch {
+		case err == sql.ErrNoRows:
+			res, err := tx.Exec("INSERT INTO grades (assessment_id, student_id, score) VALUES (?, ?, ?)",
+				grade.AssessmentID, grade.StudentID, grade.Score)
+			if err != nil {
+				return nil, dbError(err, message)
+			}
+			lastId, err := res.LastInsertId()
+			if err != nil {
+				return nil, dbError(err, message)
+			}
+			grade.ID = int(lastId)
+			if err := insertAudit(tx, repository.Grades.AuditEntry(ctx, models.AuditCreate, grade.ID, nil, &grade, now)); err != nil {
+				return nil, err
+			}
+
+		case err != nil:
+			return nil, dbError(err, message)
+
+		case existing.Score != grade.Score:
+			grade.ID = existing.ID
+			if _, err := tx.Exec("UPDATE grades SET score = ? WHERE id = ?", grade.Score, grade.ID); err != nil {
+				return nil, dbError(err, message)
+			}
+			if err := insertAudit(tx, repository.Grades.AuditEntry(ctx, models.AuditUpdate, grade.ID, &existing, &grade, now)); err != nil {
+				return nil, err
+			}
+
+		default:
+			grade.ID = existing.ID
+		}
+		stored[i] = grade
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(err, message)
+	}
+	return stored, nil
+}
+
+// GetGradesByStudentID returns the grades of a student with their assessments.
+func (t *AssessmentTable) GetGradesByStudentID(id int, academicYear string, term int) ([]models.StudentGrade, error) {
+	if _, err := t.students.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	query := t.selectQuery() + "id IN (SELECT assessment_id FROM grades WHERE student_id = ?)"
+	args := []interface{}{id}
+	if academicYear != "" {
+		query += " AND academic_year = ?"
+		args = append(args, academicYear)
+	}
+	if term != 0 {
+		query += " AND term = ?"
+		args = append(args, term)
+	}
+	assessments, err := t.queryRows(query+" ORDER BY date ASC, id ASC", args...)
+	if err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+
+	grades, err := t.grades.queryRows(t.grades.selectQuery()+"student_id = ?", id)
+	if err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+	scores := make(map[int]float64, len(grades))
+	for _, grade := range grades {
+		scores[grade.AssessmentID] = grade.Score
+	}
+
+	studentGrades := make([]models.StudentGrade, len(assessments))
+	for i, assessment := range assessments {
+		studentGrades[i] = models.StudentGrade{Assessment: assessment, Score: scores[assessment.ID]}
+	}
+	return studentGrades, nil
+}
+
+// GetClassGrades returns the assessments of a class in a term and every
+// grade given in them.
+func (t *AssessmentTable) GetClassGrades(classID int, academicYear string, term int) ([]models.Assessment, []models.Grade, error) {
+	condition := "class_id = ? AND academic_year = ? AND term = ?"
+
+	assessments, err := t.queryRows(t.selectQuery()+condition+" ORDER BY date ASC, id ASC", classID, academicYear, term)
+	if err != nil {
+		return nil, nil, dbError(err, "Error retrieving data from database")
+	}
+
+	query := t.grades.selectQuery() + "assessment_id IN (SELECT id FROM assessments WHERE " + condition + ") ORDER BY id ASC"
+	grades, err := t.grades.queryRows(query, classID, academicYear, term)
+	if err != nil {
+		return nil, nil, dbError(err, "Error retrieving data from database")
+	}
+	return assessments, grades, nil
+}
@@ -0,0 +1,193 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"strings"
+	"time"
+)
+
+// AttendanceTable is the attendance repository. Besides the generic
+// operations it takes the roll of a class and counts the records for the
+// attendance reports.
+type AttendanceTable struct {
+	*Table[models.Attendance]
+	classes *ClassTable
+}
+
+// NewAttendanceTable creates the attendance repository backed by the connection pool.
+func NewAttendanceTable(db *sql.DB, classes *ClassTable) *AttendanceTable {
+	return &AttendanceTable{Table: NewTable(db, repository.Attendance), classes: classes}
+}
+
+// TakeRoll stores the attendance of every student of a class. The class is
+// locked, so the rolls of a class are stored one at a time.
+func (t *AttendanceTable) TakeRoll(ctx context.Context, classID int, roll models.Roll) ([]models.Attendance, error) {
+	message := t.entity.Message("storing %s data in")
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	if err := t.classes.exists(tx, classID, true); err != nil {
+		return nil, err
+	}
+	studentIDs, err := classStudentIDs(tx, classID)
+	if err != nil {
+		return nil, dbError(err, message)
+	}
+	records, err := repository.RollRecords(classID, roll, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, record := range records {
+		query := t.selectQuery() + "student_id = ? AND date = ? AND period = ? FOR UPDATE"
+		existing, err := t.scan(tx.QueryRow(query, record.StudentID, record.Date, record.Period))
+		switch {
+		case err == sql.ErrNoRows:
+			res, err := tx.Exec("INSERT INTO attendance (student_id, class_id, date, period, status, note) VALUES (?, ?, ?, ?, ?, ?)",
+				record.StudentID, record.ClassID, record.Date, record.Period, record.Status, record.Note)
+			if err != nil {
+				return nil, dbError(err, message)
+			}
+			lastId, err := res.LastInsertId()
+			if err != nil {
+				return nil, dbError(err, message)
+			}
+			record.ID = int(lastId)
+			if err := insertAudit(tx, t.entity.AuditEntry(ctx, models.AuditCreate, record.ID, nil, &record, now)); err != nil {
+				return nil, err
+			}
+
+		case err != nil:
+			return nil, dbError(err, message)
+
+		default:
+			record.ID = existing.ID
+			if t.entity.Equal(existing, record) {
+				break
+			}
+			if _, err := tx.Exec("UPDATE attendance SET class_id = ?, status = ?, note = ? WHERE id = ?",
+				record.ClassID, record.Status, record.Note, record.ID); err != nil {
+				return nil, dbError(err, message)
+			}
+			if err := insertAudit(tx, t.entity.AuditEntry(ctx, models.AuditUpdate, record.ID, &existing, &record, now)); err != nil {
+				return nil, err
+			}
+		}
+		records[i] = record
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(err, message)
+	}
+	return records, nil
+}
+
+// classStudentIDs returns the IDs of the students of a class, in order.
+func classStudentIDs(tx *sql.Tx, classID int) ([]int, error) {
+	rows, err := tx.Query("SELECT id FROM students WHERE class_id = ? ORDER BY id", classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var IDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		IDs = append(IDs, id)
+	}
+	return IDs, rows.Err()
+}
+
+// GetRegister returns the attendance records of a class from one date to another.
+func (t *AttendanceTable) GetRegister(classID int, from string, to string) ([]models.Attendance, error) {
+	if err := t.classes.exists(t.db, classID, false); err != nil {
+		return nil, err
+	}
+
+	condition, args := attendanceCondition(repository.AttendanceFilter{ClassID: classID, From: from, To: to})
+	records, err := t.queryRows(t.selectQuery()+condition+" ORDER BY date ASC, period ASC, student_id ASC", args...)
+	if err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+	return records, nil
+}
+
+// attendanceCondition returns the WHERE condition selecting the records of a filter.
+func attendanceCondition(filter repository.AttendanceFilter) (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	if filter.ClassID != 0 {
+		conditions = append(conditions, "class_id = ?")
+		args = append(args, filter.ClassID)
+	}
+	if filter.From != "" {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, filter.To)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// attendanceCounts are the aggregates of a summary, in the order scanned by summarize.
+const attendanceCounts = `SUM(status = 'present'), SUM(status = 'absent'), SUM(status = 'late'),
+	SUM(status = 'excused'), COUNT(*)`
+
+// summarize runs a summary query selecting the key columns followed by
+// attendanceCounts and scans every row, keys into the summary by keys.
+func (t *AttendanceTable) summarize(query string, args []interface{},
+	keys func(summary *models.AttendanceSummary) []interface{}) ([]models.AttendanceSummary, error) {
+	rows, err := t.db.Query(query, args...)
+	if err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+	defer rows.Close()
+
+	var summaries []models.AttendanceSummary
+	for rows.Next() {
+		var summary models.AttendanceSummary
+		pointers := append(keys(&summary), &summary.Present, &summary.Absent, &summary.Late, &summary.Excused, &summary.Total)
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, dbError(err, "Error retrieving data from database")
+		}
+		repository.SetAttendanceRate(&summary)
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+	return summaries, nil
+}
+
+// SummarizeByStudent counts the records of the filter per student and class.
+func (t *AttendanceTable) SummarizeByStudent(filter repository.AttendanceFilter) ([]models.AttendanceSummary, error) {
+	condition, args := attendanceCondition(filter)
+	query := "SELECT student_id, class_id, " + attendanceCounts + " FROM attendance WHERE " + condition +
+		" GROUP BY class_id, student_id ORDER BY class_id ASC, student_id ASC"
+	return t.summarize(query, args, func(summary *models.AttendanceSummary) []interface{} {
+		return []interface{}{&summary.StudentID, &summary.ClassID}
+	})
+}
+
+// SummarizeByClass counts the records of the filter per class.
+func (t *AttendanceTable) SummarizeByClass(filter repository.AttendanceFilter) ([]models.AttendanceSummary, error) {
+	condition, args := attendanceCondition(filter)
+	query := "SELECT class_id, COUNT(DISTINCT student_id), " + attendanceCounts + " FROM attendance WHERE " + condition +
+		" GROUP BY class_id ORDER BY class_id ASC"
+	return t.summarize(query, args, func(summary *models.AttendanceSummary) []interface{} {
+		return []interface{}{&summary.ClassID, &summary.Students}
+	})
+}
@@ -0,0 +1,132 @@
+package sqlconnect
+
+import (
+	"database/sql"
+	"encoding/json"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strings"
+	"time"
+)
+
+// AuditTable is the audit repository, backed by the audit_log table. The
+// tables of the entities write to it in the transaction of each change.
+type AuditTable struct {
+	db *sql.DB
+}
+
+// NewAuditTable creates the audit repository backed by db.
+func NewAuditTable(db *sql.DB) *AuditTable {
+	return &AuditTable{db: db}
+}
+
+// insertAudit stores an audit entry, inside the transaction of the change
+// when db is one. Updates that changed nothing are not recorded.
+func insertAudit(db execer, entry models.AuditEntry) error {
+	if entry.Action == models.AuditUpdate && len(entry.Changes) == 0 {
+		return nil
+	}
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return utils.ErrorHandler(err, "Error recording audit entry")
+	}
+
+	query := `INSERT INTO audit_log (actor_type, actor_id, actor_name, action, entity, entity_id, changes, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = db.Exec(query, entry.ActorType, entry.ActorID, entry.ActorName, entry.Action, entry.Entity, entry.EntityID,
+		string(changes), entry.RequestID, entry.CreatedAt.UTC().Format(time.DateTime))
+	if err != nil {
+		return dbError(err, "Error recording audit entry")
+	}
+	return nil
+}
+
+// auditWhereClause returns the conditions of filter and their arguments.
+func auditWhereClause(filter repository.AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+
+	if filter.Entity != "" {
+		add("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		add("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		add("action = ?", filter.Action)
+	}
+	if filter.ActorType != "" {
+		add("actor_type = ?", filter.ActorType)
+	}
+	if filter.ActorID != 0 {
+		add("actor_id = ?", filter.ActorID)
+	}
+	if filter.ActorName != "" {
+		add("actor_name = ?", filter.ActorName)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= ?", filter.From.UTC().Format(time.DateTime))
+	}
+	if !filter.To.IsZero() {
+		add("created_at < ?", filter.To.UTC().Format(time.DateTime))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " AND " + strings.Join(conditions, " AND "), args
+}
+
+// ListAudit returns one page of the entries matching filter, newest first.
+func (t *AuditTable) ListAudit(filter repository.AuditFilter, page utils.Pagination) ([]models.AuditEntry, int, error) {
+	message := "Error retrieving audit log"
+	where, args := auditWhereClause(filter)
+
+	var total int
+	if err := t.db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE 1=1"+where, args...).Scan(&total); err != nil {
+		return nil, 0, dbError(err, message)
+	}
+
+	query := `SELECT id, actor_type, actor_id, actor_name, action, entity, entity_id, changes, request_id, created_at
+		FROM audit_log WHERE 1=1` + where
+	keyset, keysetArgs := page.BuildKeysetClause()
+	query += keyset + " ORDER BY id DESC"
+	args = append(args, keysetArgs...)
+	limit, limitArgs := page.BuildLimitClause()
+	query += limit
+	args = append(args, limitArgs...)
+
+	rows, err := t.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, dbError(err, message)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var changes, createdAt string
+		err := rows.Scan(&entry.ID, &entry.ActorType, &entry.ActorID, &entry.ActorName, &entry.Action,
+			&entry.Entity, &entry.EntityID, &changes, &entry.RequestID, &createdAt)
+		if err != nil {
+			return nil, 0, dbError(err, message)
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, 0, utils.ErrorHandler(err, message)
+		}
+		if entry.CreatedAt, err = time.ParseInLocation(time.DateTime, createdAt, time.UTC); err != nil {
+			return nil, 0, utils.ErrorHandler(err, message)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, dbError(err, message)
+	}
+	return entries, total, nil
+}
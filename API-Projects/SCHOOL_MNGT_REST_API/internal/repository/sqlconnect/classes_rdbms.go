@@ -0,0 +1,170 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"slices"
+	"time"
+)
+
+// ClassTable is the class repository. Besides the generic operations it
+// lists the students and teachers of a class and assigns teachers to it.
+type ClassTable struct {
+	*Table[models.Class]
+	students *Table[models.Student]
+	teachers *Table[models.Teacher]
+}
+
+// NewClassTable creates the class repository backed by the connection pool.
+func NewClassTable(db *sql.DB, students *Table[models.Student], teachers *Table[models.Teacher]) *ClassTable {
+	return &ClassTable{Table: NewTable(db, repository.Classes), students: students, teachers: teachers}
+}
+
+// exists returns a NotFound error when there is no class with the given ID.
+// Inside a transaction the row is locked, so changes to the teachers of a
+// class are made one at a time.
+func (t *ClassTable) exists(db queryer, id int, lock bool) error {
+	query := "SELECT id FROM classes WHERE id = ?"
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var found int
+	if err := db.QueryRow(query, id).Scan(&found); err != nil {
+		if err == sql.ErrNoRows {
+			return t.entity.NotFoundError(err, id)
+		}
+		return dbError(err, t.entity.Message("retrieving %s from"))
+	}
+	return nil
+}
+
+// GetStudentsByClassID returns the students of a class.
+func (t *ClassTable) GetStudentsByClassID(id int) ([]models.Student, error) {
+	if err := t.exists(t.db, id, false); err != nil {
+		return nil, err
+	}
+
+	students, err := t.students.queryRows(t.students.selectQuery()+"class_id = ? ORDER BY id ASC", id)
+	if err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+	return students, nil
+}
+
+// GetTeachersByClassID returns the teachers assigned to a class.
+func (t *ClassTable) GetTeachersByClassID(id int) ([]models.Teacher, error) {
+	if err := t.exists(t.db, id, false); err != nil {
+		return nil, err
+	}
+
+	query := t.teachers.selectQuery() + "id IN (SELECT teacher_id FROM class_teachers WHERE class_id = ?) ORDER BY id ASC"
+	teachers, err := t.teachers.queryRows(query, id)
+	if err != nil {
+		return nil, dbError(err, "Error retrieving data from database")
+	}
+	return teachers, nil
+}
+
+// teacherIDs returns the IDs of the teachers assigned to a class, in order.
+func (t *ClassTable) teacherIDs(tx *sql.Tx, classID int) ([]int, error) {
+	rows, err := tx.Query("SELECT teacher_id FROM class_teachers WHERE class_id = ? ORDER BY teacher_id", classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	IDs := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		IDs = append(IDs, id)
+	}
+	return IDs, rows.Err()
+}
+
+// AssignTeacher assigns a teacher to a class.
+func (t *ClassTable) AssignTeacher(ctx context.Context, classID int, teacherID int) error {
+	message := "Error assigning teacher to class in database"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	if err := t.exists(tx, classID, true); err != nil {
+		return err
+	}
+	if _, err := t.teachers.getByID(tx, teacherID); err != nil {
+		if err == sql.ErrNoRows {
+			return repository.Teachers.NotFoundError(err, teacherID)
+		}
+		return dbError(err, message)
+	}
+
+	before, err := t.teacherIDs(tx, classID)
+	if err != nil {
+		return dbError(err, message)
+	}
+	if slices.Contains(before, teacherID) {
+		return nil
+	}
+
+	if _, err := tx.Exec("INSERT INTO class_teachers (class_id, teacher_id) VALUES (?, ?)", classID, teacherID); err != nil {
+		return dbError(err, message)
+	}
+
+	after := slices.Sorted(slices.Values(append(slices.Clone(before), teacherID)))
+	if err := insertAudit(tx, repository.ClassTeachersAuditEntry(ctx, classID, before, after, time.Now())); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// UnassignTeacher removes a teacher from a class.
+func (t *ClassTable) UnassignTeacher(ctx context.Context, classID int, teacherID int) error {
+	message := "Error removing teacher from class in database"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	if err := t.exists(tx, classID, true); err != nil {
+		return err
+	}
+
+	before, err := t.teacherIDs(tx, classID)
+	if err != nil {
+		return dbError(err, message)
+	}
+	if !slices.Contains(before, teacherID) {
+		return utils.NotFoundError(nil, fmt.Sprintf("Teacher with ID %d is not assigned to class %d", teacherID, classID))
+	}
+
+	if _, err := tx.Exec("DELETE FROM class_teachers WHERE class_id = ? AND teacher_id = ?", classID, teacherID); err != nil {
+		return dbError(err, message)
+	}
+
+	after := slices.DeleteFunc(slices.Clone(before), func(id int) bool { return id == teacherID })
+	if err := insertAudit(tx, repository.ClassTeachersAuditEntry(ctx, classID, before, after, time.Now())); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
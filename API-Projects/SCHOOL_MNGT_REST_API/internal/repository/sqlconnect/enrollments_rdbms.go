@@ -0,0 +1,66 @@
+package sqlconnect
+
+import (
+	"cmp"
+	"database/sql"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"slices"
+)
+
+// EnrollmentTable is the enrollment repository. Enrolling checks the
+// capacity of the subjects in the transaction of the inserts.
+type EnrollmentTable struct {
+	*Table[models.Enrollment]
+}
+
+// NewEnrollmentTable creates the enrollment repository backed by the connection pool.
+func NewEnrollmentTable(db *sql.DB) *EnrollmentTable {
+	t := &EnrollmentTable{Table: NewTable(db, repository.Enrollments)}
+	t.checkCreate = checkSectionCapacity
+	return t
+}
+
+// checkSectionCapacity returns an EnrollmentFullError when the enrollments
+// would take a section over the capacity of its subject. The subjects are
+// locked in ID order, so concurrent enrollments are counted one after the
+// other. Missing subjects are left to the foreign key.
+func checkSectionCapacity(tx *sql.Tx, enrollments []models.Enrollment) error {
+	message := repository.Enrollments.Message("inserting %s data into")
+
+	added := make(map[repository.Section]int)
+	var sections []repository.Section
+	for _, enrollment := range enrollments {
+		section := repository.SectionOf(enrollment)
+		if added[section] == 0 {
+			sections = append(sections, section)
+		}
+		added[section]++
+	}
+	slices.SortFunc(sections, func(a, b repository.Section) int {
+		return cmp.Compare(a.SubjectID, b.SubjectID)
+	})
+
+	for _, section := range sections {
+		var capacity int
+		err := tx.QueryRow("SELECT capacity FROM subjects WHERE id = ? FOR UPDATE", section.SubjectID).Scan(&capacity)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return dbError(err, message)
+		}
+
+		var enrolled int
+		query := `SELECT COUNT(*) FROM enrollments
+			WHERE subject_id = ? AND teacher_id = ? AND academic_year = ? AND term = ?`
+		err = tx.QueryRow(query, section.SubjectID, section.TeacherID, section.AcademicYear, section.Term).Scan(&enrolled)
+		if err != nil {
+			return dbError(err, message)
+		}
+		if enrolled+added[section] > capacity {
+			return repository.EnrollmentFullError(section, capacity)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package sqlconnect
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"school_management_api/pkg/utils"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MariaDB error numbers mapped to domain errors.
+const (
+	errDuplicateEntry   = 1062 // unique key violation
+	errRowIsReferenced  = 1451 // delete or update of a row other rows point to
+	errNoReferencedRow  = 1452 // insert or update pointing to a missing row
+	errRowIsReferenced2 = 1217
+	errNoReferencedRow2 = 1216
+)
+
+// uniqueKeyFields maps the unique keys of the migrations to the fields they
+// cover, so a duplicate can be reported without the driver message.
+var uniqueKeyFields = map[string]string{
+	"uq_teachers_email":                   "email",
+	"uq_students_email":                   "email",
+	"uq_execs_email":                      "email",
+	"uq_execs_username":                   "username",
+	"uq_refresh_tokens_token_hash":        "token_hash",
+	"uq_recovery_codes_exec_id_code_hash": "exec_id,code_hash",
+	"uq_accounts_username":                "username",
+	"uq_accounts_teacher_id":              "teacher_id",
+	"uq_accounts_student_id":              "student_id",
+	"uq_api_keys_key_hash":                "key_hash",
+	"uq_classes_name_academic_year":       "name,academic_year",
+	"uq_subjects_name":                    "name",
+	"uq_enrollments_student_subject_term": "student_id,subject_id,academic_year,term",
+	"uq_grades_assessment_student":        "assessment_id,student_id",
+	"uq_attendance_student_date_period":   "student_id,date,period",
+}
+
+// duplicateKeyPattern finds the key in a duplicate entry message, e.g.
+// "Duplicate entry 'x' for key 'uq_execs_email'", with or without the
+// table name in front of it.
+var duplicateKeyPattern = regexp.MustCompile(`for key '(?:[^'.]*\.)?([^'.]+)'$`)
+
+// duplicateDetails describes a duplicate entry by the fields of its key.
+// The driver message holds the submitted value and index names and is
+// only logged.
+func duplicateDetails(mysqlErr *mysql.MySQLError) string {
+	if match := duplicateKeyPattern.FindStringSubmatch(mysqlErr.Message); match != nil {
+		if fields, ok := uniqueKeyFields[match[1]]; ok {
+			return "duplicate value for " + strings.ReplaceAll(fields, ",", ", ")
+		}
+	}
+	return "duplicate value"
+}
+
+// dbError logs err and converts it into a typed error: missing rows become
+// NotFound, duplicate keys and referenced rows Conflict, and missing foreign
+// rows Validation. Anything else is an internal error.
+func dbError(err error, message string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.NotFoundError(err, message)
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errDuplicateEntry:
+			return utils.ConflictError(err, message).WithDetails(duplicateDetails(mysqlErr))
+		case errRowIsReferenced, errRowIsReferenced2:
+			return utils.ConflictError(err, message).WithDetails("the record is still referenced by other records")
+		case errNoReferencedRow, errNoReferencedRow2:
+			return utils.ValidationError(err, message).WithDetails("a referenced record does not exist")
+		}
+	}
+	return utils.ErrorHandler(err, message)
+}
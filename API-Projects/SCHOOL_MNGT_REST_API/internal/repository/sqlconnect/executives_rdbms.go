@@ -1,358 +1,174 @@
 package sqlconnect
 
 import (
-	"crypto/rand"
+	"context"
 	"database/sql"
-	"encoding/base64"
-	"errors"
 	"fmt"
-	"net/http"
 	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
 	"school_management_api/pkg/utils"
-	"strings"
-
-	"golang.org/x/crypto/argon2"
+	"slices"
+	"time"
 )
 
-// =========== Helper functions ===================
-
-// addExecutivesFilter adds filtering conditions to the SQL query based on URL query parameters.
-func addExecutivesFilter(r *http.Request, query string, args []interface{}) (string, []interface{}) {
-	// Handle Query parameters for filtering
-	params := map[string]string{
-		"first_name": "first_name",
-		"last_name":  "last_name",
-		"email":      "email",
-		"username":   "username",
-		"role":       "role",
-	}
-
-	for param, dbField := range params {
-		value := r.URL.Query().Get(param)
-		if value != "" {
-			query += fmt.Sprintf(" AND %s = ?", dbField)
-			args = append(args, value)
-		}
-	}
-	return query, args
-}
-
-// getExecutiveByID retrieves a executive by ID from the database
-func getExecutiveByID(db queryer, id int) (models.Executive, error) {
-	var executive models.Executive
-	query := "SELECT id, first_name, last_name, email, username, user_created_at, inactive_status, role FROM execs WHERE id = ?"
-	err := db.QueryRow(query, id).
-		Scan(&executive.ID, &executive.FirstName, &executive.LastName, &executive.Email, &executive.Username, &executive.UserCreatedAt, &executive.InactiveStatus, &executive.Role)
-	return executive, err
+// ExecutiveTable is the executive repository. Besides the generic operations
+// it looks up executives with their password hash for logging in.
+type ExecutiveTable struct {
+	*Table[models.Executive]
 }
 
-// ================ Database Operations ===================
-
-// GetExecutivesInDb retrieves a collection of executives from the database
-// with optional filtering and sorting.
-func GetExecutivesInDb(executives []models.Executive, r *http.Request) ([]models.Executive, error) {
-	db, err := ConnectDb()
-	if err != nil {
-		return nil, utils.ErrorHandler(err, "Error connecting to database")
-	}
-	defer func() {
-		if db != nil {
-			db.Close()
-		}
-	}()
-
-	// Build the SQL query with filters
-	query := "SELECT id, first_name, last_name, email, username, user_created_at, inactive_status, role FROM execs WHERE 1=1" // * id, first_name, last_name, email
-	var args []interface{}
-
-	// Add filters based on query parameters
-	query, args = addExecutivesFilter(r, query, args)
-
-	// Example: /executives/?sortby=last_name:asc&sortby=username:desc
-	query += utils.BuildOrderByClause(r)
-
-	// Execute the query
-	rows, err := db.Query(query, args...)
+// GetUserByUsername retrieves an executive, including the hidden columns, by their username.
+func (t *ExecutiveTable) GetUserByUsername(username string) (models.Executive, error) {
+	var user models.Executive
+	columns := t.entity.Columns()
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE username = ?", repository.ColumnNames(columns), t.entity.Table)
+	err := t.db.QueryRow(query, username).Scan(t.entity.Pointers(&user, columns)...)
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Error retrieving executives from database")
-	}
-
-	defer rows.Close()
-
-	for rows.Next() {
-		var executive models.Executive
-		if err := rows.Scan(&executive.ID, &executive.FirstName, &executive.LastName, &executive.Email, &executive.Username, &executive.UserCreatedAt, &executive.InactiveStatus, &executive.Role); err != nil {
-			return nil, utils.ErrorHandler(err, "Error retrieving executives from database")
+		if err == sql.ErrNoRows {
+			return models.Executive{}, dbError(err, "executive not found in database")
 		}
-		executives = append(executives, executive)
+		return models.Executive{}, dbError(err, "database query error")
 	}
-	return executives, nil
+	return user, nil
 }
 
-// GetExecutiveByID retrieves a single executive by their ID.
-func GetExecutiveByID(id int) (models.Executive, error) {
-
-	db, err := ConnectDb()
-	if err != nil {
-		return models.Executive{}, utils.ErrorHandler(err, "Error connecting to database")
-	}
-	defer func() {
-		if db != nil {
-			db.Close()
-		}
-	}()
-
-	var executive models.Executive
-	query := "SELECT id, first_name, last_name, email, username, user_created_at, inactive_status, role FROM execs WHERE id = ?" // id, first_name, last_name, email, username, user_created_at, inactive_status, role
-	err = db.QueryRow(query, id).
-		Scan(&executive.ID, &executive.FirstName, &executive.LastName, &executive.Email, &executive.Username, &executive.UserCreatedAt, &executive.InactiveStatus, &executive.Role)
-
+// GetUserByID retrieves an executive, including the hidden columns, by their ID.
+func (t *ExecutiveTable) GetUserByID(id int) (models.Executive, error) {
+	var user models.Executive
+	columns := t.entity.Columns()
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", repository.ColumnNames(columns), t.entity.Table)
+	err := t.db.QueryRow(query, id).Scan(t.entity.Pointers(&user, columns)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return models.Executive{}, utils.ErrorHandler(err, fmt.Sprintf("Executive with ID: %d not found in database", id))
+			return models.Executive{}, t.entity.NotFoundError(err, id)
 		}
-		return models.Executive{}, utils.ErrorHandler(err, "Error retrieving executive by ID from database")
+		return models.Executive{}, dbError(err, "database query error")
 	}
-	return executive, nil
+	return user, nil
 }
 
-// CreateExecutives adds new executives to the database.
-func CreateExecutives(newExecutives []models.Executive) ([]models.Executive, error) {
-	db, err := ConnectDb()
-	if err != nil {
-		return []models.Executive{}, utils.ErrorHandler(err, "Error connecting to database")
-	}
-	defer func() {
-		if db != nil {
-			db.Close()
-		}
-	}()
-
-	stmt, err := db.Prepare(utils.GenerateInsertQuery(models.Executive{}, "execs"))
+// change locks the executive selected by condition and stores the changes
+// apply makes to it, along with their audit entry, in one transaction.
+// Apply returns false to leave the executive as it is. It returns
+// sql.ErrNoRows when no executive is selected.
+func (t *ExecutiveTable) change(ctx context.Context, message string, condition string, args []interface{},
+	apply func(executive *models.Executive) bool) (bool, error) {
+	tx, err := t.db.Begin()
 	if err != nil {
-		return nil, utils.ErrorHandler(err, "Error inserting executive data into database")
+		return false, dbError(err, message)
 	}
-	defer stmt.Close()
-
-	addedExecutives := make([]models.Executive, len(newExecutives))
-
-	for i, newExecutive := range newExecutives {
-		if newExecutive.Password == "" {
-			return nil, utils.ErrorHandler(fmt.Errorf("password is required"), "Error inserting executive data into database")
-		}
-
-		// Hash the password using Argon2id with a random salt
-		salt := make([]byte, 16)
-		if _, err := rand.Read(salt); err != nil {
-			return nil, utils.ErrorHandler(errors.New("failed to generate salt"), "Error inserting executive data into database")
-		}
-
-		hash := argon2.IDKey([]byte(newExecutive.Password), salt, 1, 64*1024, 4, 32)
-		saltBase64 := base64.StdEncoding.EncodeToString(salt)
-		hashBase64 := base64.StdEncoding.EncodeToString(hash)
-		encodedHash := fmt.Sprintf("%s.%s", saltBase64, hashBase64)
-
-		newExecutive.Password = encodedHash
+	defer tx.Rollback()
 
-		values := utils.GetStructValues(newExecutive)
-		res, err := stmt.Exec(values...)
-		if err != nil {
-			return nil, utils.ErrorHandler(err, "Error inserting executive data into database")
-		}
-
-		// Get the last inserted ID
-		lastId, err := res.LastInsertId()
-		if err != nil {
-			return nil, utils.ErrorHandler(err, "Error inserting executive data into database")
+	var before models.Executive
+	columns := t.entity.Columns()
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s FOR UPDATE", repository.ColumnNames(columns), t.entity.Table, condition)
+	if err := tx.QueryRow(query, args...).Scan(t.entity.Pointers(&before, columns)...); err != nil {
+		if err == sql.ErrNoRows {
+			return false, err
 		}
-
-		newExecutive.ID = int(lastId)
-		addedExecutives[i] = newExecutive
+		return false, dbError(err, message)
 	}
 
-	return addedExecutives, nil
-}
-
-// PatchExecutivesInDb performs partial updates on multiple executives in the database.
-func PatchExecutivesInDb(updatedFields []map[string]interface{}) ([]models.Executive, error) {
-
-	var executivesFromDB []models.Executive
-	db, err := ConnectDb()
-	if err != nil {
-		return executivesFromDB, utils.ErrorHandler(err, "Error connecting to database")
-	}
-	defer db.Close()
-
-	// Validate all fields before starting the transaction
-	for _, executiveUpdate := range updatedFields {
-		id, err := utils.GetIDFromMap(executiveUpdate)
-		if err != nil {
-			return executivesFromDB, utils.ErrorHandler(err, "Error updating executive data into database")
-		}
-
-		executiveToUpdate, err := getExecutiveByID(db, id)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return executivesFromDB, utils.ErrorHandler(err, fmt.Sprintf("executive with ID: %d not found in database", id))
-			}
-			return executivesFromDB, utils.ErrorHandler(err, "Error updating executive data into database")
-		}
-
-		validFields := utils.BuildValidFieldsMap(executiveToUpdate)
-		if err := utils.ValidateUpdateFields(models.Executive{}, validFields, executiveUpdate); err != nil {
-			return executivesFromDB, utils.ErrorHandler(err, "Error updating executive data into database")
-		}
+	after := before
+	if !apply(&after) {
+		return false, nil
 	}
-
-	tx, err := db.Begin()
-	if err != nil {
-		return executivesFromDB, utils.ErrorHandler(err, "Error updating executive data into database")
+	columns = slices.DeleteFunc(columns, func(column repository.Column) bool { return column.Name == "id" })
+	if err := t.update(tx, before.ID, columns, after); err != nil {
+		return false, dbError(err, message)
 	}
-
-	for _, executiveUpdate := range updatedFields {
-		id, err := utils.GetIDFromMap(executiveUpdate)
-		if err != nil {
-			tx.Rollback()
-			return executivesFromDB, utils.ErrorHandler(err, "Error updating executive data into database")
-		}
-		executiveToUpdate, err := getExecutiveByID(tx, id)
-		if err != nil {
-			tx.Rollback()
-			return executivesFromDB, utils.ErrorHandler(err, fmt.Sprintf("executive with ID: %d not found in database", id))
-		}
-
-		validFields := utils.BuildValidFieldsMap(executiveToUpdate)
-		utils.ApplyUpdateToStruct(&executiveToUpdate, validFields, executiveUpdate)
-
-		var updateFields []string
-		var updateArgs []interface{}
-		for key, value := range executiveUpdate {
-			if key == "id" {
-				continue
-			}
-			updateFields = append(updateFields, fmt.Sprintf("%s = ?", key))
-			updateArgs = append(updateArgs, value)
-		}
-		if len(updateFields) == 0 {
-			tx.Rollback()
-			return executivesFromDB, fmt.Errorf("no valid fields provided for update")
-		}
-		updateArgs = append(updateArgs, executiveToUpdate.ID)
-		updateExecutiveQuery := fmt.Sprintf("UPDATE execs SET %s WHERE id = ?", strings.Join(updateFields, ", "))
-
-		_, err = tx.Exec(updateExecutiveQuery, updateArgs...)
-		if err != nil {
-			tx.Rollback()
-			return executivesFromDB, utils.ErrorHandler(err, "Error updating executive data into database")
-		}
-
-		executivesFromDB = append(executivesFromDB, executiveToUpdate)
+	if err := insertAudit(tx, t.entity.AuditEntry(ctx, models.AuditUpdate, before.ID, &before, &after, time.Now())); err != nil {
+		return false, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return executivesFromDB, utils.ErrorHandler(err, "Error updating executive data into database")
+		return false, dbError(err, message)
 	}
-
-	return executivesFromDB, nil
+	return true, nil
 }
 
-// PatchexecutiveByID performs a partial update on a single executive by their ID.
-func PatchExecutiveByID(id int, updatedFields map[string]interface{}) (models.Executive, error) {
-
-	db, err := ConnectDb()
-	if err != nil {
-		return models.Executive{}, utils.ErrorHandler(err, "Error connecting to database")
-	}
-	defer db.Close()
-
-	// Get existing executive by id using helper
-	executiveToUpdate, err := getExecutiveByID(db, id)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.Executive{}, utils.ErrorHandler(err, fmt.Sprintf("Executive with ID: %d not found in database", id))
-		}
-		return models.Executive{}, utils.ErrorHandler(err, "Error updating executive data into database")
-	}
-
-	// Build valid fields map using helper
-	validFields := utils.BuildValidFieldsMap(executiveToUpdate)
-
-	// Validate fields to update using helper
-	if err := utils.ValidateUpdateFields(models.Executive{}, validFields, updatedFields); err != nil {
-		return models.Executive{}, utils.ErrorHandler(err, "Error updating executive data into database")
-	}
-
-	// Apply updates to struct using helper
-	utils.ApplyUpdateToStruct(&executiveToUpdate, validFields, updatedFields)
-
-	// Build update query and args
-	var updateFields []string
-	var updateArgs []interface{}
-	for key, value := range updatedFields {
-		if key == "id" {
-			continue
-		}
-		updateFields = append(updateFields, fmt.Sprintf("%s = ?", key))
-		updateArgs = append(updateArgs, value)
-	}
-	if len(updateFields) == 0 {
-		return models.Executive{}, fmt.Errorf("no valid fields provided for update")
-	}
-
-	updateArgs = append(updateArgs, executiveToUpdate.ID)
-	updateExecutiveQuery := fmt.Sprintf("UPDATE execs SET %s WHERE id = ?", strings.Join(updateFields, ", "))
-
-	_, err = db.Exec(updateExecutiveQuery, updateArgs...)
-	if err != nil {
-		return models.Executive{}, utils.ErrorHandler(err, "Error updating executive data into database")
+// changeByID changes the executive with the given ID, see change.
+func (t *ExecutiveTable) changeByID(ctx context.Context, message string, id int, apply func(executive *models.Executive) bool) (bool, error) {
+	changed, err := t.change(ctx, message, "id = ?", []interface{}{id}, apply)
+	if err == sql.ErrNoRows {
+		return false, t.entity.NotFoundError(err, id)
 	}
-
-	return executiveToUpdate, nil
+	return changed, err
 }
 
-// DeleteExecutiveByID deletes a single executive by their ID.
-func DeleteExecutiveByID(id int) error {
-	db, err := ConnectDb()
-	if err != nil {
-		return utils.ErrorHandler(err, "Error connecting to database")
-	}
-	defer db.Close()
+// UpdatePassword stores a new password hash and the time it was changed.
+func (t *ExecutiveTable) UpdatePassword(ctx context.Context, id int, passwordHash string, changedAt time.Time) error {
+	_, err := t.changeByID(ctx, "Error updating password", id, func(executive *models.Executive) bool {
+		executive.Password = passwordHash
+		executive.PasswordChangedAt = sql.NullString{String: changedAt.UTC().Format(utils.DateTimeMicro), Valid: true}
+		return true
+	})
+	return err
+}
 
-	// Delete the executive
-	result, err := db.Exec("DELETE FROM execs WHERE id = ?", id)
-	if err != nil {
-		return utils.ErrorHandler(err, "Error deleting executive from database")
-	}
+// ReplacePasswordHash stores a new hash of the same password unless the
+// password was changed meanwhile.
+func (t *ExecutiveTable) ReplacePasswordHash(ctx context.Context, id int, oldHash string, newHash string) error {
+	_, err := t.changeByID(ctx, "Error updating password", id, func(executive *models.Executive) bool {
+		if executive.Password != oldHash {
+			return false
+		}
+		executive.Password = newHash
+		return true
+	})
+	return err
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return utils.ErrorHandler(err, "Error deleting executive from database")
-	}
+// SetPasswordResetToken stores the hash and expiry of a new password reset token.
+func (t *ExecutiveTable) SetPasswordResetToken(ctx context.Context, id int, tokenHash string, expires time.Time) error {
+	_, err := t.changeByID(ctx, "Error saving password reset token", id, func(executive *models.Executive) bool {
+		executive.PasswordResetToken = sql.NullString{String: tokenHash, Valid: true}
+		executive.PasswordTokenExpires = sql.NullString{String: expires.UTC().Format(time.DateTime), Valid: true}
+		return true
+	})
+	return err
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("executive with ID %d not found", id)
-	}
-	return nil
+// ResetPassword sets a new password hash for the holder of an unexpired
+// reset token and clears the token. The holder is locked until the token
+// is cleared, so concurrent requests with the same token cannot both succeed.
+func (t *ExecutiveTable) ResetPassword(ctx context.Context, tokenHash string, passwordHash string, now time.Time) error {
+	nowUTC := now.UTC().Format(time.DateTime)
+	changedAt := now.UTC().Format(utils.DateTimeMicro)
+	condition := "password_reset_token = ? AND password_token_expires > ?"
+	_, err := t.change(ctx, "Error resetting password", condition, []interface{}{tokenHash, nowUTC}, func(executive *models.Executive) bool {
+		executive.Password = passwordHash
+		executive.PasswordChangedAt = sql.NullString{String: changedAt, Valid: true}
+		executive.PasswordResetToken = sql.NullString{}
+		executive.PasswordTokenExpires = sql.NullString{}
+		return true
+	})
+	if err == sql.ErrNoRows {
+		return utils.NotFoundError(err, "password reset code is invalid or has expired")
+	}
+	return err
 }
 
-// GetUserByUsername retrieves a executive by their username.
-func GetUserByUsername(username string) (models.Executive, error) {
-	db, err := ConnectDb()
-	if err != nil {
-		return models.Executive{}, utils.ErrorHandler(err, "Database connection error")
-	}
-	defer db.Close()
+// SetTOTP stores the TOTP secret and state of an executive.
+func (t *ExecutiveTable) SetTOTP(ctx context.Context, id int, secret sql.NullString, enabled bool) error {
+	_, err := t.changeByID(ctx, "Error updating two-factor authentication", id, func(executive *models.Executive) bool {
+		executive.TOTPSecret = secret
+		executive.TOTPEnabled = enabled
+		executive.TOTPLastStep = sql.NullInt64{}
+		return true
+	})
+	return err
+}
 
-	// search for user if exists
-	var user models.Executive
-	query := "SELECT id, first_name, last_name, email, username, password, inactive_status, role FROM execs WHERE username = ?"
-	err = db.QueryRow(query, username).
-		Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Username, &user.Password, &user.InactiveStatus, &user.Role)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.Executive{}, utils.ErrorHandler(err, "executive not found in database")
-		}
-		return models.Executive{}, utils.ErrorHandler(err, "database query error")
-	}
-	return user, nil
+// UseTOTPStep records the time step of an accepted code unless it is not
+// after the last one. The executive is locked meanwhile, so concurrent
+// logins with the same code cannot both succeed.
+func (t *ExecutiveTable) UseTOTPStep(ctx context.Context, id int, step int64) (bool, error) {
+	return t.changeByID(ctx, "Error verifying two-factor code", id, func(executive *models.Executive) bool {
+		if executive.TOTPLastStep.Valid && executive.TOTPLastStep.Int64 >= step {
+			return false
+		}
+		executive.TOTPLastStep = sql.NullInt64{Int64: step, Valid: true}
+		return true
+	})
 }
@@ -0,0 +1,98 @@
+package sqlconnect
+
+import (
+	"database/sql"
+	"school_management_api/internal/models"
+	"school_management_api/pkg/utils"
+	"time"
+)
+
+// LoginAttemptTable is the login attempt repository, backed by the
+// login_attempts table.
+type LoginAttemptTable struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptTable creates the login attempt repository backed by db.
+func NewLoginAttemptTable(db *sql.DB) *LoginAttemptTable {
+	return &LoginAttemptTable{db: db}
+}
+
+// getLoginAttempts reads the counter of key through db or tx.
+func getLoginAttempts(db queryer, key string) (models.LoginAttempts, error) {
+	attempts := models.LoginAttempts{Key: key}
+	var lastFailure string
+	var lockedUntil sql.NullString
+
+	query := "SELECT failures, last_failure, locked_until FROM login_attempts WHERE attempt_key = ?"
+	err := db.QueryRow(query, key).Scan(&attempts.Failures, &lastFailure, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return attempts, nil
+	}
+	if err != nil {
+		return attempts, dbError(err, "database query error")
+	}
+
+	if attempts.LastFailure, err = time.ParseInLocation(time.DateTime, lastFailure, time.UTC); err != nil {
+		return attempts, utils.ErrorHandler(err, "database query error")
+	}
+	if lockedUntil.Valid {
+		if attempts.LockedUntil, err = time.ParseInLocation(time.DateTime, lockedUntil.String, time.UTC); err != nil {
+			return attempts, utils.ErrorHandler(err, "database query error")
+		}
+	}
+	return attempts, nil
+}
+
+// GetLoginAttempts returns the counter of key.
+func (t *LoginAttemptTable) GetLoginAttempts(key string) (models.LoginAttempts, error) {
+	return getLoginAttempts(t.db, key)
+}
+
+// RecordLoginFailure adds a failure to the counter of key. The increment is
+// a single statement, so concurrent failures are all counted.
+func (t *LoginAttemptTable) RecordLoginFailure(key string, now time.Time, resetBefore time.Time) (models.LoginAttempts, error) {
+	message := "Error recording failed login"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return models.LoginAttempts{}, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	// failures is assigned first, so it still sees the previous last_failure
+	query := `INSERT INTO login_attempts (attempt_key, failures, last_failure) VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE failures = IF(last_failure < ?, 1, failures + 1), last_failure = VALUES(last_failure)`
+	nowUTC := now.UTC().Format(time.DateTime)
+	if _, err := tx.Exec(query, key, nowUTC, resetBefore.UTC().Format(time.DateTime)); err != nil {
+		return models.LoginAttempts{}, dbError(err, message)
+	}
+
+	attempts, err := getLoginAttempts(tx, key)
+	if err != nil {
+		return models.LoginAttempts{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.LoginAttempts{}, dbError(err, message)
+	}
+	return attempts, nil
+}
+
+// LockLogin blocks the logins of key until the given time.
+func (t *LoginAttemptTable) LockLogin(key string, until time.Time) error {
+	query := `INSERT INTO login_attempts (attempt_key, last_failure, locked_until) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE locked_until = VALUES(locked_until)`
+	untilUTC := until.UTC().Format(time.DateTime)
+	if _, err := t.db.Exec(query, key, time.Now().UTC().Format(time.DateTime), untilUTC); err != nil {
+		return dbError(err, "Error locking login")
+	}
+	return nil
+}
+
+// ResetLoginAttempts clears the counter and the lock of key.
+func (t *LoginAttemptTable) ResetLoginAttempts(key string) error {
+	if _, err := t.db.Exec("DELETE FROM login_attempts WHERE attempt_key = ?", key); err != nil {
+		return dbError(err, "Error resetting login attempts")
+	}
+	return nil
+}
@@ -1,33 +1,98 @@
 package sqlconnect
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"strconv"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql" // Importing the MySQL driver
 )
 
-// Add this interface for query compatibility
+// queryer and execer are satisfied by both *sql.DB and *sql.Tx, so helpers
+// can run inside or outside a transaction.
 type queryer interface {
 	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
-// type dbHandler struct {
-// 	db *sql.DB
-// }
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
 
-// func (h *dbHandler) QueryRow(query string, args ...interface{}) *sql.Row {
-// 	return h.db.QueryRow(query, args...)
-// }
+// Default connection pool settings, used when the matching
+// environment variable is not set.
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 25
+	defaultConnMaxLifetime = 5 * time.Minute
+	defaultConnMaxIdleTime = 5 * time.Minute
+	defaultPingTimeout     = 5 * time.Second
+)
 
-// ConnectDb establishes a connection to the MariaDB database with the given name.
-func ConnectDb() (*sql.DB, error) {
+// Repository wraps the long-lived database connection pool shared by all
+// data access methods. It is created once at startup and its tables are
+// passed to the handlers.
+type Repository struct {
+	db *sql.DB
+
+	Students    *Table[models.Student]
+	Teachers    *TeacherTable
+	Classes     *ClassTable
+	Subjects    *Table[models.Subject]
+	Enrollments *EnrollmentTable
+	Assessments *AssessmentTable
+	Attendance  *AttendanceTable
+	Executives  *ExecutiveTable
+	Accounts    *AccountTable
+	Tokens      *TokenTable
+	Logins      *LoginAttemptTable
+	TwoFactor   *TwoFactorTable
+	APIKeys     *APIKeyTable
+	Audit       *AuditTable
+}
+
+// NewRepository creates a Repository backed by the given connection pool.
+func NewRepository(db *sql.DB) *Repository {
+	students := NewTable(db, repository.Students)
+	teachers := NewTable(db, repository.Teachers)
+	classes := NewClassTable(db, students, teachers)
+	return &Repository{
+		db:          db,
+		Students:    students,
+		Teachers:    &TeacherTable{Table: teachers, students: students},
+		Classes:     classes,
+		Subjects:    NewTable(db, repository.Subjects),
+		Enrollments: NewEnrollmentTable(db),
+		Assessments: NewAssessmentTable(db, students),
+		Attendance:  NewAttendanceTable(db, classes),
+		Executives:  &ExecutiveTable{Table: NewTable(db, repository.Executives)},
+		Accounts:    NewAccountTable(db),
+		Tokens:      NewTokenTable(db),
+		Logins:      NewLoginAttemptTable(db),
+		TwoFactor:   NewTwoFactorTable(db),
+		APIKeys:     NewAPIKeyTable(db),
+		Audit:       NewAuditTable(db),
+	}
+}
+
+// Stats returns the connection pool statistics of the underlying database handle.
+func (repo *Repository) Stats() sql.DBStats {
+	return repo.db.Stats()
+}
 
-	// Load environment variables from .env file
-	// if err := godotenv.Load(); err != nil {
-	// 	return nil, err
-	// }
+// ConnectDb opens the MariaDB connection pool, applies the pool settings
+// from the environment and verifies the connection with a ping.
+//
+// Pool settings:
+//   - DB_MAX_OPEN_CONNS: maximum number of open connections (default 25)
+//   - DB_MAX_IDLE_CONNS: maximum number of idle connections (default 25)
+//   - DB_CONN_MAX_LIFETIME: maximum lifetime of a connection, e.g. "5m" (default 5m)
+//   - DB_CONN_MAX_IDLE_TIME: maximum idle time of a connection, e.g. "5m" (default 5m)
+func ConnectDb() (*sql.DB, error) {
 
 	// Fetch database connection parameters from environment variables
 	user := os.Getenv("DB_USER")
@@ -44,6 +109,89 @@ func ConnectDb() (*sql.DB, error) {
 		return nil, err
 	}
 
-	// fmt.Println("Connected to MariaDB")
+	if err := configurePool(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// sql.Open does not connect, so ping to fail fast on bad credentials
+	ctx, cancel := context.WithTimeout(context.Background(), defaultPingTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
 	return db, nil
 }
+
+// configurePool applies the connection pool settings from the environment.
+func configurePool(db *sql.DB) error {
+	maxOpen, err := envInt("DB_MAX_OPEN_CONNS", defaultMaxOpenConns)
+	if err != nil {
+		return err
+	}
+	maxIdle, err := envInt("DB_MAX_IDLE_CONNS", defaultMaxIdleConns)
+	if err != nil {
+		return err
+	}
+	lifetime, err := envDuration("DB_CONN_MAX_LIFETIME", defaultConnMaxLifetime)
+	if err != nil {
+		return err
+	}
+	idleTime, err := envDuration("DB_CONN_MAX_IDLE_TIME", defaultConnMaxIdleTime)
+	if err != nil {
+		return err
+	}
+
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(lifetime)
+	db.SetConnMaxIdleTime(idleTime)
+	return nil
+}
+
+// envInt reads an integer environment variable, falling back to def when unset.
+func envInt(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// envDuration reads a duration environment variable, falling back to def when unset.
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// Make sure the SQL repository satisfies the repository interfaces.
+var (
+	_ repository.StudentRepository      = (*Table[models.Student])(nil)
+	_ repository.TeacherRepository      = (*TeacherTable)(nil)
+	_ repository.ClassRepository        = (*ClassTable)(nil)
+	_ repository.SubjectRepository      = (*Table[models.Subject])(nil)
+	_ repository.EnrollmentRepository   = (*EnrollmentTable)(nil)
+	_ repository.AssessmentRepository   = (*AssessmentTable)(nil)
+	_ repository.AttendanceRepository   = (*AttendanceTable)(nil)
+	_ repository.ExecutiveRepository    = (*ExecutiveTable)(nil)
+	_ repository.AccountRepository      = (*AccountTable)(nil)
+	_ repository.TokenRepository        = (*TokenTable)(nil)
+	_ repository.LoginAttemptRepository = (*LoginAttemptTable)(nil)
+	_ repository.TwoFactorRepository    = (*TwoFactorTable)(nil)
+	_ repository.APIKeyRepository       = (*APIKeyTable)(nil)
+	_ repository.AuditRepository        = (*AuditTable)(nil)
+)
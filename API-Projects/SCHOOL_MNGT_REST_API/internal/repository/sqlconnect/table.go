@@ -0,0 +1,386 @@
+package sqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strings"
+	"time"
+)
+
+// Table implements repository.Resource for one entity. Its queries are built
+// from the entity's columns, so every model is stored the same way.
+type Table[T any] struct {
+	db     *sql.DB
+	entity repository.Entity[T]
+
+	// checkCreate runs inside the transaction of Create before the items
+	// are inserted, for constraints the schema cannot express.
+	checkCreate func(tx *sql.Tx, items []T) error
+}
+
+// NewTable creates the repository of an entity backed by the connection pool.
+func NewTable[T any](db *sql.DB, entity repository.Entity[T]) *Table[T] {
+	return &Table[T]{db: db, entity: entity}
+}
+
+// selectQuery returns the SELECT of the visible columns, ready for a WHERE condition.
+func (t *Table[T]) selectQuery() string {
+	return fmt.Sprintf("SELECT %s FROM %s WHERE ", repository.ColumnNames(t.entity.VisibleColumns()), t.entity.Table)
+}
+
+// scan reads the visible columns of a row.
+func (t *Table[T]) scan(row interface{ Scan(...interface{}) error }) (T, error) {
+	var item T
+	err := row.Scan(t.entity.Pointers(&item, t.entity.VisibleColumns())...)
+	return item, err
+}
+
+// queryRows runs a SELECT of the visible columns and scans every row.
+func (t *Table[T]) queryRows(query string, args ...interface{}) ([]T, error) {
+	rows, err := t.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		item, err := t.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// getByID retrieves an item by ID, within a transaction when db is one.
+func (t *Table[T]) getByID(db queryer, id int) (T, error) {
+	return t.scan(db.QueryRow(t.selectQuery()+"id = ?", id))
+}
+
+// List retrieves one page of the items matching the filters and sort order of
+// spec. It also returns the total number of rows matching the filters.
+func (t *Table[T]) List(spec utils.QuerySpec, page utils.Pagination) ([]T, int, error) {
+	var items []T
+
+	// Add the validated filters of the query spec
+	filter, args := spec.BuildWhereClause()
+
+	// Count every matching row before the page is applied
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE 1=1", t.entity.Table) + filter
+	if err := t.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, dbError(err, t.entity.PluralMessage("retrieving %s from"))
+	}
+
+	query := t.selectQuery() + "1=1" + filter
+
+	// Continue after the cursor in cursor mode
+	keyset, keysetArgs := page.BuildKeysetClause()
+	query += keyset
+	args = append(args, keysetArgs...)
+
+	query += spec.BuildOrderByClause()
+
+	limit, limitArgs := page.BuildLimitClause()
+	query += limit
+	args = append(args, limitArgs...)
+
+	rows, err := t.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, dbError(err, t.entity.PluralMessage("retrieving %s from"))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := t.scan(rows)
+		if err != nil {
+			return nil, 0, dbError(err, t.entity.PluralMessage("retrieving %s from"))
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, dbError(err, t.entity.PluralMessage("retrieving %s from"))
+	}
+	return items, total, nil
+}
+
+// GetByID retrieves a single item by its ID.
+func (t *Table[T]) GetByID(id int) (T, error) {
+	item, err := t.getByID(t.db, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return item, t.entity.NotFoundError(err, id)
+		}
+		return item, dbError(err, t.entity.Message("retrieving %s by ID from"))
+	}
+	return item, nil
+}
+
+// Create inserts the items in one transaction, running the entity's
+// BeforeCreate hook on each, and returns them with their new IDs.
+func (t *Table[T]) Create(ctx context.Context, items []T) ([]T, error) {
+	message := t.entity.Message("inserting %s data into")
+
+	addedItems := make([]T, len(items))
+	for i, item := range items {
+		t.entity.Protect(&item)
+		if t.entity.BeforeCreate != nil {
+			if err := t.entity.BeforeCreate(&item); err != nil {
+				return nil, err
+			}
+		}
+		addedItems[i] = item
+	}
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	if t.checkCreate != nil {
+		if err := t.checkCreate(tx, addedItems); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	for i := range addedItems {
+		// NULL columns are left out so the database defaults apply
+		columns := t.entity.InsertColumns(addedItems[i])
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", t.entity.Table, repository.ColumnNames(columns), placeholders)
+
+		res, err := tx.Exec(query, t.entity.Values(addedItems[i], columns)...)
+		if err != nil {
+			return nil, dbError(err, message)
+		}
+
+		lastId, err := res.LastInsertId()
+		if err != nil {
+			return nil, dbError(err, message)
+		}
+		t.entity.SetID(&addedItems[i], int(lastId))
+
+		entry := t.entity.AuditEntry(ctx, models.AuditCreate, int(lastId), nil, &addedItems[i], now)
+		if err := insertAudit(tx, entry); err != nil {
+			return nil, err
+		}
+		t.entity.Hide(&addedItems[i])
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(err, message)
+	}
+	return addedItems, nil
+}
+
+// Update replaces the visible columns of an existing item by its ID.
+func (t *Table[T]) Update(ctx context.Context, id int, updatedItem T) (T, error) {
+	var zero T
+	message := t.entity.Message("updating %s in the")
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return zero, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	itemToUpdate, err := t.getByID(tx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return zero, t.entity.NotFoundError(err, id)
+		}
+		return zero, dbError(err, message)
+	}
+
+	// Check if there are any changes before updating
+	t.entity.SetID(&updatedItem, id)
+	t.entity.Hide(&updatedItem)
+	if t.entity.Equal(updatedItem, itemToUpdate) {
+		return zero, t.entity.NoChangesError()
+	}
+
+	columns := t.entity.UpdateColumns()
+	if err := t.update(tx, id, columns, updatedItem); err != nil {
+		return zero, dbError(err, message)
+	}
+
+	entry := t.entity.AuditEntry(ctx, models.AuditUpdate, id, &itemToUpdate, &updatedItem, time.Now())
+	if err := insertAudit(tx, entry); err != nil {
+		return zero, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zero, dbError(err, message)
+	}
+	return updatedItem, nil
+}
+
+// update writes the given columns of item to the row with the given ID.
+func (t *Table[T]) update(db execer, id int, columns []repository.Column, item T) error {
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = column.Name + " = ?"
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", t.entity.Table, strings.Join(assignments, ", "))
+
+	_, err := db.Exec(query, append(t.entity.Values(item, columns), id)...)
+	return err
+}
+
+// patch applies a partial update to the row with the given ID inside tx.
+func (t *Table[T]) patch(ctx context.Context, tx *sql.Tx, id int, fields map[string]interface{}) (T, error) {
+	var zero T
+	message := t.entity.Message("updating %s data into")
+
+	item, err := t.getByID(tx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return zero, t.entity.NotFoundError(err, id)
+		}
+		return zero, dbError(err, message)
+	}
+	before := item
+
+	columns, err := t.entity.ApplyPatch(&item, fields)
+	if err != nil {
+		return zero, err
+	}
+	if err := t.update(tx, id, columns, item); err != nil {
+		return zero, dbError(err, message)
+	}
+
+	if err := insertAudit(tx, t.entity.AuditEntry(ctx, models.AuditUpdate, id, &before, &item, time.Now())); err != nil {
+		return zero, err
+	}
+	return item, nil
+}
+
+// Patch performs a partial update on a single item by its ID.
+func (t *Table[T]) Patch(ctx context.Context, id int, fields map[string]interface{}) (T, error) {
+	var zero T
+	message := t.entity.Message("updating %s data into")
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return zero, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	item, err := t.patch(ctx, tx, id, fields)
+	if err != nil {
+		return zero, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zero, dbError(err, message)
+	}
+	return item, nil
+}
+
+// PatchMany performs partial updates on multiple items in one transaction.
+func (t *Table[T]) PatchMany(ctx context.Context, updates []map[string]interface{}) ([]T, error) {
+	message := t.entity.Message("updating %s data into")
+
+	// Validate all IDs before starting the transaction
+	IDs := make([]int, len(updates))
+	for i, update := range updates {
+		id, err := utils.GetIDFromMap(update)
+		if err != nil {
+			return nil, utils.ValidationError(err, err.Error())
+		}
+		IDs[i] = id
+	}
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	var patchedItems []T
+	for i, update := range updates {
+		item, err := t.patch(ctx, tx, IDs[i], update)
+		if err != nil {
+			return nil, err
+		}
+		patchedItems = append(patchedItems, item)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(err, message)
+	}
+	return patchedItems, nil
+}
+
+// remove deletes the row with the given ID inside tx and records it.
+func (t *Table[T]) remove(ctx context.Context, tx *sql.Tx, id int, message string) error {
+	item, err := t.getByID(tx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.NotFoundError(nil, fmt.Sprintf("%s with ID %d not found", t.entity.Name, id))
+		}
+		return dbError(err, message)
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", t.entity.Table), id); err != nil {
+		return dbError(err, message)
+	}
+	return insertAudit(tx, t.entity.AuditEntry(ctx, models.AuditDelete, id, &item, nil, time.Now()))
+}
+
+// Delete deletes a single item by its ID.
+func (t *Table[T]) Delete(ctx context.Context, id int) error {
+	message := t.entity.Message("deleting %s from")
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	if err := t.remove(ctx, tx, id, message); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// DeleteMany deletes multiple items by their IDs in one transaction and
+// returns the list of deleted IDs.
+func (t *Table[T]) DeleteMany(ctx context.Context, IDs []int) ([]int, error) {
+	message := t.entity.PluralMessage("deleting %s from")
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	deletedIDs := []int{}
+	for _, id := range IDs {
+		if err := t.remove(ctx, tx, id, fmt.Sprintf("Failed to delete %s with ID %d", t.entity.Name, id)); err != nil {
+			return nil, err
+		}
+		deletedIDs = append(deletedIDs, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(err, message)
+	}
+
+	if len(deletedIDs) == 0 {
+		return nil, utils.NotFoundError(nil, fmt.Sprintf("no %s found", t.entity.Plural()))
+	}
+	return deletedIDs, nil
+}
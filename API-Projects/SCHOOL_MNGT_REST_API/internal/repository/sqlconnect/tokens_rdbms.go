@@ -0,0 +1,162 @@
+package sqlconnect
+
+import (
+	"database/sql"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"time"
+)
+
+// TokenTable is the token repository, backed by the refresh_tokens and
+// revoked_tokens tables.
+type TokenTable struct {
+	db *sql.DB
+}
+
+// NewTokenTable creates the token repository backed by db.
+func NewTokenTable(db *sql.DB) *TokenTable {
+	return &TokenTable{db: db}
+}
+
+// insertRefreshToken stores a new refresh token through db or tx.
+func insertRefreshToken(db execer, token models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (token_hash, family_id, exec_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)`
+	_, err := db.Exec(query, token.TokenHash, token.FamilyID, token.ExecutiveID,
+		token.CreatedAt.UTC().Format(utils.DateTimeMicro), token.ExpiresAt.UTC().Format(time.DateTime))
+	if err != nil {
+		return dbError(err, "Error saving refresh token")
+	}
+	return nil
+}
+
+// revokeFamily revokes every unrevoked token of a family through db or tx.
+func revokeFamily(db execer, familyID string, now time.Time) error {
+	query := "UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL"
+	if _, err := db.Exec(query, now.UTC().Format(time.DateTime), familyID); err != nil {
+		return dbError(err, "Error revoking refresh tokens")
+	}
+	return nil
+}
+
+// CreateRefreshToken stores a new refresh token.
+func (t *TokenTable) CreateRefreshToken(token models.RefreshToken) error {
+	return insertRefreshToken(t.db, token)
+}
+
+// RotateRefreshToken replaces a valid refresh token with next, revoking the
+// whole family when a revoked token is presented again. The token row is
+// locked, so concurrent requests with the same token cannot both succeed.
+func (t *TokenTable) RotateRefreshToken(tokenHash string, next models.RefreshToken, now time.Time) (models.RefreshToken, error) {
+	message := "Error rotating refresh token"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return models.RefreshToken{}, dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	token := models.RefreshToken{TokenHash: tokenHash}
+	var createdAt, expiresAt string
+	var revokedAt sql.NullString
+	query := `SELECT id, family_id, exec_id, created_at, expires_at, revoked_at
+		FROM refresh_tokens WHERE token_hash = ? FOR UPDATE`
+	err = tx.QueryRow(query, tokenHash).Scan(&token.ID, &token.FamilyID, &token.ExecutiveID, &createdAt, &expiresAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return models.RefreshToken{}, utils.NotFoundError(nil, "refresh token is invalid or has expired")
+	}
+	if err != nil {
+		return models.RefreshToken{}, dbError(err, message)
+	}
+
+	if token.CreatedAt, err = time.ParseInLocation(time.DateTime, createdAt, time.UTC); err != nil {
+		return models.RefreshToken{}, utils.ErrorHandler(err, message)
+	}
+	if token.ExpiresAt, err = time.ParseInLocation(time.DateTime, expiresAt, time.UTC); err != nil {
+		return models.RefreshToken{}, utils.ErrorHandler(err, message)
+	}
+	if !token.ExpiresAt.After(now) {
+		return models.RefreshToken{}, utils.NotFoundError(nil, "refresh token is invalid or has expired")
+	}
+
+	if revokedAt.Valid {
+		// Keep the family revoked even though the request fails
+		if err := revokeFamily(tx, token.FamilyID, now); err != nil {
+			return models.RefreshToken{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return models.RefreshToken{}, dbError(err, message)
+		}
+		return models.RefreshToken{}, utils.UnauthorizedError(repository.ErrRefreshTokenReused,
+			"refresh token was already used, every session of this login has been revoked")
+	}
+
+	token.RevokedAt = now
+	_, err = tx.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE id = ?", now.UTC().Format(time.DateTime), token.ID)
+	if err != nil {
+		return models.RefreshToken{}, dbError(err, message)
+	}
+
+	next.FamilyID = token.FamilyID
+	next.ExecutiveID = token.ExecutiveID
+	if err := insertRefreshToken(tx, next); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.RefreshToken{}, dbError(err, message)
+	}
+	return token, nil
+}
+
+// RevokeRefreshToken revokes the family of a refresh token of the executive execID.
+func (t *TokenTable) RevokeRefreshToken(tokenHash string, execID int, now time.Time) error {
+	message := "Error revoking refresh tokens"
+
+	var familyID string
+	var owner int
+	err := t.db.QueryRow("SELECT family_id, exec_id FROM refresh_tokens WHERE token_hash = ?", tokenHash).
+		Scan(&familyID, &owner)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return dbError(err, message)
+	}
+	if owner != execID {
+		return utils.ForbiddenError(nil, "refresh token belongs to another executive")
+	}
+
+	query := "UPDATE refresh_tokens SET revoked_at = ? WHERE revoked_at IS NULL AND family_id = ?"
+	if _, err := t.db.Exec(query, now.UTC().Format(time.DateTime), familyID); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// RevokeAccessToken deny-lists an access token ID until it expires.
+// Entries whose token has expired are dropped on the way.
+func (t *TokenTable) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	message := "Error revoking access token"
+
+	if _, err := t.db.Exec("DELETE FROM revoked_tokens WHERE expires_at <= ?", time.Now().UTC().Format(time.DateTime)); err != nil {
+		return dbError(err, message)
+	}
+
+	query := "INSERT INTO revoked_tokens (jti, expires_at) VALUES (?, ?) ON DUPLICATE KEY UPDATE expires_at = VALUES(expires_at)"
+	if _, err := t.db.Exec(query, jti, expiresAt.UTC().Format(time.DateTime)); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token ID is deny-listed.
+func (t *TokenTable) IsAccessTokenRevoked(jti string, now time.Time) (bool, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM revoked_tokens WHERE jti = ? AND expires_at > ?"
+	if err := t.db.QueryRow(query, jti, now.UTC().Format(time.DateTime)).Scan(&count); err != nil {
+		return false, dbError(err, "database query error")
+	}
+	return count > 0, nil
+}
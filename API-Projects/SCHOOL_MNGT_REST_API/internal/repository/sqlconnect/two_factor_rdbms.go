@@ -0,0 +1,110 @@
+package sqlconnect
+
+import (
+	"database/sql"
+	"school_management_api/pkg/utils"
+	"time"
+)
+
+// TwoFactorTable is the two-factor repository, backed by the recovery_codes
+// and two_factor_roles tables.
+type TwoFactorTable struct {
+	db *sql.DB
+}
+
+// NewTwoFactorTable creates the two-factor repository backed by db.
+func NewTwoFactorTable(db *sql.DB) *TwoFactorTable {
+	return &TwoFactorTable{db: db}
+}
+
+// ReplaceRecoveryCodes replaces every recovery code of the executive in one transaction.
+func (t *TwoFactorTable) ReplaceRecoveryCodes(execID int, codeHashes []string) error {
+	message := "Error saving recovery codes"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE exec_id = ?", execID); err != nil {
+		return dbError(err, message)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec("INSERT INTO recovery_codes (exec_id, code_hash) VALUES (?, ?)", execID, hash); err != nil {
+			return dbError(err, message)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of the executive as used.
+// The check is part of the update, so a code cannot be used twice.
+func (t *TwoFactorTable) UseRecoveryCode(execID int, codeHash string, now time.Time) error {
+	query := "UPDATE recovery_codes SET used_at = ? WHERE exec_id = ? AND code_hash = ? AND used_at IS NULL"
+	result, err := t.db.Exec(query, now.UTC().Format(time.DateTime), execID, codeHash)
+	if err != nil {
+		return dbError(err, "Error verifying recovery code")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return dbError(err, "Error verifying recovery code")
+	}
+	if rowsAffected == 0 {
+		return utils.NotFoundError(nil, "recovery code is invalid or was already used")
+	}
+	return nil
+}
+
+// RequiredRoles returns the roles that must use two-factor authentication.
+func (t *TwoFactorTable) RequiredRoles() ([]string, error) {
+	rows, err := t.db.Query("SELECT role FROM two_factor_roles ORDER BY role")
+	if err != nil {
+		return nil, dbError(err, "database query error")
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, dbError(err, "database query error")
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, "database query error")
+	}
+	return roles, nil
+}
+
+// SetRequiredRoles replaces the roles that must use two-factor authentication
+// in one transaction.
+func (t *TwoFactorTable) SetRequiredRoles(roles []string) error {
+	message := "Error saving two-factor policy"
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return dbError(err, message)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM two_factor_roles"); err != nil {
+		return dbError(err, message)
+	}
+	for _, role := range roles {
+		if _, err := tx.Exec("INSERT IGNORE INTO two_factor_roles (role) VALUES (?)", role); err != nil {
+			return dbError(err, message)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbError(err, message)
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"school_management_api/internal/models"
+	"school_management_api/internal/repository"
+	"school_management_api/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Summary counts what happened to the rows of one seed file.
+type Summary struct {
+	Entity   string
+	Inserted int
+	Updated  int
+	Skipped  int
+	Failed   int
+}
+
+// String formats the summary as a single report line.
+func (s Summary) String() string {
+	return fmt.Sprintf("%-10s inserted: %d, updated: %d, skipped: %d, failed: %d",
+		s.Entity, s.Inserted, s.Updated, s.Skipped, s.Failed)
+}
+
+// Seeder loads rows into the repositories using the same create and update
+// operations as the API handlers. Rows are upserted: students and teachers are
+// matched by email, executives by username. The audit log records the
+// writes as made by the system.
+type Seeder struct {
+	students   repository.StudentRepository
+	teachers   repository.TeacherRepository
+	classes    repository.ClassRepository
+	subjects   repository.SubjectRepository
+	executives repository.ExecutiveRepository
+
+	// AcademicYear is the year of the classes looked up or created for the
+	// class names of teacher and student rows. Defaults to the current one.
+	AcademicYear string
+	// Log receives one line per failed row. Defaults to io.Discard.
+	Log io.Writer
+}
+
+// TeacherRow is a teacher of a seed file. Class optionally names a class of
+// the seeder's academic year to assign the teacher to, as in the files made
+// before classes were stored on their own. Those files also hold rooms,
+// e.g. "Physics 808", which are not class codes and are ignored.
+type TeacherRow struct {
+	models.Teacher
+	Class string `json:"class"`
+}
+
+// StudentRow is a student of a seed file. The class is given by class_id,
+// or by the name of a class of the seeder's academic year.
+type StudentRow struct {
+	models.Student
+	Class string `json:"class"`
+}
+
+// defaultClassCapacity is the capacity of the classes created while seeding.
+const defaultClassCapacity = 30
+
+// defaultSubjectCapacity is the capacity of the subjects created while seeding.
+const defaultSubjectCapacity = 30
+
+// lookupPage limits the lookups of existing rows to a single page.
+var lookupPage = utils.Pagination{Page: 1, Limit: 1}
+
+// NewSeeder creates a Seeder writing to the given repositories.
+func NewSeeder(students repository.StudentRepository, teachers repository.TeacherRepository,
+	classes repository.ClassRepository, subjects repository.SubjectRepository, executives repository.ExecutiveRepository) *Seeder {
+	return &Seeder{
+		students:     students,
+		teachers:     teachers,
+		classes:      classes,
+		subjects:     subjects,
+		executives:   executives,
+		AcademicYear: utils.CurrentAcademicYear(time.Now()),
+		Log:          io.Discard,
+	}
+}
+
+// ReadFile decodes a JSON array of rows from path into v,
+// e.g. students_list.json into a []models.Student.
+func ReadFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+	return nil
+}
+
+// SeedTeachers inserts new teachers and updates existing ones matched by email.
+// Teachers are assigned to the class code they name, which is created with
+// the teacher as its homeroom teacher when it does not exist yet. The subjects
+// teachers teach are created as well.
+func (s *Seeder) SeedTeachers(rows []TeacherRow) Summary {
+	teachers := make([]models.Teacher, len(rows))
+	for i, row := range rows {
+		teachers[i] = row.Teacher
+	}
+
+	return seedRows(s, "teachers", s.teachers, teachers, "email",
+		func(teacher models.Teacher) string { return teacher.Email },
+		func(stored, seeded models.Teacher) (bool, error) {
+			seeded.ID = stored.ID
+			if seeded == stored {
+				return false, nil
+			}
+			_, err := s.teachers.Update(context.Background(), stored.ID, seeded)
+			return true, err
+		},
+		nil,
+		func(i int, stored models.Teacher) error {
+			if err := s.ensureSubject(stored.Subject); err != nil {
+				return err
+			}
+			if !utils.IsClassCode(rows[i].Class) {
+				return nil
+			}
+			classID, err := s.classID(rows[i].Class, &stored.ID)
+			if err != nil {
+				return err
+			}
+			return s.classes.AssignTeacher(context.Background(), classID, stored.ID)
+		})
+}
+
+// SeedStudents inserts new students and updates existing ones matched by email.
+// Classes given by name are created when they do not exist yet.
+func (s *Seeder) SeedStudents(rows []StudentRow) Summary {
+	students := make([]models.Student, len(rows))
+	for i, row := range rows {
+		students[i] = row.Student
+	}
+
+	return seedRows(s, "students", s.students, students, "email",
+		func(student models.Student) string { return student.Email },
+		func(stored, seeded models.Student) (bool, error) {
+			seeded.ID = stored.ID
+			if seeded == stored {
+				return false, nil
+			}
+			_, err := s.students.Update(context.Background(), stored.ID, seeded)
+			return true, err
+		},
+		func(i int, student *models.Student) error {
+			if rows[i].Class == "" {
+				return nil
+			}
+			classID, err := s.classID(rows[i].Class, nil)
+			student.ClassID = classID
+			return err
+		},
+		nil)
+}
+
+// classID returns the ID of the class with the given name in the seeder's
+// academic year, creating the class with the given homeroom teacher when
+// there is none.
+func (s *Seeder) classID(name string, homeroomTeacherID *int) (int, error) {
+	spec := utils.QuerySpec{Filters: []utils.Filter{
+		utils.EqFilter("name", name),
+		utils.EqFilter("academic_year", s.AcademicYear),
+	}}
+	existing, _, err := s.classes.List(spec, lookupPage)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > 0 {
+		return existing[0].ID, nil
+	}
+
+	gradeLevel, _ := strconv.Atoi(strings.TrimRightFunc(name, unicode.IsLetter))
+	class := models.Class{
+		Name:              name,
+		GradeLevel:        gradeLevel,
+		Capacity:          defaultClassCapacity,
+		HomeroomTeacherID: homeroomTeacherID,
+		AcademicYear:      s.AcademicYear,
+	}
+	if err := utils.ValidateItem(class); err != nil {
+		return 0, fmt.Errorf("class %s: %w", name, err)
+	}
+	created, err := s.classes.Create(context.Background(), []models.Class{class})
+	if err != nil {
+		return 0, err
+	}
+	return created[0].ID, nil
+}
+
+// ensureSubject creates the subject with the given name when there is none.
+func (s *Seeder) ensureSubject(name string) error {
+	if name == "" {
+		return nil
+	}
+	existing, _, err := s.subjects.List(utils.QuerySpec{Filters: []utils.Filter{utils.EqFilter("name", name)}}, lookupPage)
+	if err != nil || len(existing) > 0 {
+		return err
+	}
+
+	subject := models.Subject{Name: name, Capacity: defaultSubjectCapacity}
+	if err := utils.ValidateItem(subject); err != nil {
+		return fmt.Errorf("subject %s: %w", name, err)
+	}
+	_, err = s.subjects.Create(context.Background(), []models.Subject{subject})
+	return err
+}
+
+// SeedExecutives inserts new executives, hashing their passwords, and updates
+// the profile fields of existing ones matched by username. Passwords of
+// existing executives are left untouched.
+func (s *Seeder) SeedExecutives(executives []models.Executive) Summary {
+	return seedRows(s, "executives", s.executives, executives, "username",
+		func(executive models.Executive) string { return executive.Username },
+		func(stored, seeded models.Executive) (bool, error) {
+			changes := executiveChanges(stored, seeded)
+			if len(changes) == 0 {
+				return false, nil
+			}
+			_, err := s.executives.Patch(context.Background(), stored.ID, changes)
+			return true, err
+		},
+		nil, nil)
+}
+
+// seedRows upserts rows into res, matching existing rows on the key column.
+// prepare, when set, completes the row of the given index before it is
+// stored. update receives the stored and the seeded row and reports whether
+// it changed anything. saved, when set, runs with the stored row once it
+// is inserted, updated or found unchanged.
+func seedRows[T any](s *Seeder, entity string, res repository.Resource[T], rows []T, key string,
+	keyOf func(row T) string, update func(stored, seeded T) (bool, error),
+	prepare func(i int, row *T) error, saved func(i int, stored T) error) Summary {
+
+	summary := Summary{Entity: entity}
+	for i, row := range rows {
+		value := keyOf(row)
+		if value == "" {
+			s.fail(&summary, i, fmt.Errorf("%s is required", key))
+			continue
+		}
+
+		if prepare != nil {
+			if err := prepare(i, &row); err != nil {
+				s.fail(&summary, i, err)
+				continue
+			}
+		}
+
+		existing, _, err := res.List(utils.QuerySpec{Filters: []utils.Filter{utils.EqFilter(key, value)}}, lookupPage)
+		if err != nil {
+			s.fail(&summary, i, err)
+			continue
+		}
+
+		var stored T
+		var changed bool
+		if len(existing) == 0 {
+			created, err := res.Create(context.Background(), []T{row})
+			if err != nil {
+				s.fail(&summary, i, err)
+				continue
+			}
+			stored = created[0]
+		} else if changed, err = update(existing[0], row); err != nil {
+			s.fail(&summary, i, err)
+			continue
+		} else {
+			stored = existing[0]
+		}
+
+		if saved != nil {
+			if err := saved(i, stored); err != nil {
+				s.fail(&summary, i, err)
+				continue
+			}
+		}
+
+		switch {
+		case len(existing) == 0:
+			summary.Inserted++
+		case changed:
+			summary.Updated++
+		default:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+// executiveChanges returns the profile fields that differ between the stored
+// and the seeded executive, keyed by column name.
+func executiveChanges(stored, seeded models.Executive) map[string]interface{} {
+	changes := make(map[string]interface{})
+	if seeded.FirstName != "" && seeded.FirstName != stored.FirstName {
+		changes["first_name"] = seeded.FirstName
+	}
+	if seeded.LastName != "" && seeded.LastName != stored.LastName {
+		changes["last_name"] = seeded.LastName
+	}
+	if seeded.Email != "" && seeded.Email != stored.Email {
+		changes["email"] = seeded.Email
+	}
+	if seeded.Role != "" && seeded.Role != stored.Role {
+		changes["role"] = seeded.Role
+	}
+	if seeded.InactiveStatus != stored.InactiveStatus {
+		changes["inactive_status"] = seeded.InactiveStatus
+	}
+	return changes
+}
+
+// fail records a failed row and logs the reason.
+func (s *Seeder) fail(summary *Summary, index int, err error) {
+	summary.Failed++
+	fmt.Fprintf(s.Log, "%s row %d: %v\n", summary.Entity, index+1, err)
+}
@@ -0,0 +1,24 @@
+package utils
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognise
+// and to find in logs and repositories.
+const APIKeyPrefix = "sms_"
+
+// apiKeyDisplayLength is how much of a key is kept in clear to tell keys apart.
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// GenerateAPIKey returns a random API key for the client, its first
+// characters shown in key listings, and the hash stored in its place.
+func GenerateAPIKey() (key string, displayPrefix string, keyHash string, err error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", "", "", ErrorHandler(err, "failed to generate API key")
+	}
+	key = APIKeyPrefix + token
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of an API key.
+func HashAPIKey(key string) string {
+	return HashResetToken(key)
+}
@@ -1,14 +1,123 @@
 package utils
 
 import (
-	"fmt"
+	"errors"
 	"log"
+	"net/http"
 	"os"
 )
 
+// ErrorCode is the machine readable code of an error response.
+type ErrorCode string
+
+// Error codes returned in the code field of error responses.
+const (
+	CodeBadRequest      ErrorCode = "bad_request"
+	CodeValidation      ErrorCode = "validation_failed"
+	CodeUnauthorized    ErrorCode = "unauthorized"
+	CodeForbidden       ErrorCode = "forbidden"
+	CodeNotFound        ErrorCode = "not_found"
+	CodeConflict        ErrorCode = "conflict"
+	CodeTooManyRequests ErrorCode = "too_many_requests"
+	CodeInternal        ErrorCode = "internal_error"
+)
+
+// statusCodes maps each error code to its HTTP status.
+var statusCodes = map[ErrorCode]int{
+	CodeBadRequest:      http.StatusBadRequest,
+	CodeValidation:      http.StatusUnprocessableEntity,
+	CodeUnauthorized:    http.StatusUnauthorized,
+	CodeForbidden:       http.StatusForbidden,
+	CodeNotFound:        http.StatusNotFound,
+	CodeConflict:        http.StatusConflict,
+	CodeTooManyRequests: http.StatusTooManyRequests,
+	CodeInternal:        http.StatusInternalServerError,
+}
+
+// AppError is a domain error carrying the code and message shown to the client.
+// The wrapped error is only logged and never sent in a response.
+type AppError struct {
+	Code    ErrorCode
+	Message string
+	Details any
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the HTTP status of the error.
+func (e *AppError) StatusCode() int {
+	if status, ok := statusCodes[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// WithDetails attaches extra information for the client, e.g. the failing fields.
+func (e *AppError) WithDetails(details any) *AppError {
+	e.Details = details
+	return e
+}
+
+var errorLogger = log.New(os.Stderr, "ERROR:", log.Ldate|log.Ltime|log.Lshortfile)
+
+// newAppError logs err, when set, and wraps it in an AppError.
+func newAppError(code ErrorCode, err error, message string) *AppError {
+	if err != nil {
+		errorLogger.Output(3, message+": "+err.Error())
+	}
+	return &AppError{Code: code, Message: message, Err: err}
+}
+
+// ErrorHandler logs err and returns an internal error with the given message.
+// An err that already is an AppError keeps its code, so a not found or conflict
+// from a helper is not turned into a 500 by its caller.
 func ErrorHandler(err error, message string) error {
-	//
-	errorLogger := log.New(os.Stderr, "ERROR:", log.Ldate|log.Ltime|log.Lshortfile)
-	errorLogger.Printf("%s: %v", message, err)
-	return fmt.Errorf("%s", message)
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		errorLogger.Output(2, message+": "+err.Error())
+		return appErr
+	}
+	return newAppError(CodeInternal, err, message)
+}
+
+// BadRequestError reports a malformed request, e.g. an invalid ID or JSON body.
+func BadRequestError(err error, message string) *AppError {
+	return newAppError(CodeBadRequest, err, message)
+}
+
+// ValidationError reports a well-formed request with invalid values.
+func ValidationError(err error, message string) *AppError {
+	return newAppError(CodeValidation, err, message)
+}
+
+// UnauthorizedError reports missing or invalid credentials.
+func UnauthorizedError(err error, message string) *AppError {
+	return newAppError(CodeUnauthorized, err, message)
+}
+
+// ForbiddenError reports an authenticated caller that may not perform the request.
+func ForbiddenError(err error, message string) *AppError {
+	return newAppError(CodeForbidden, err, message)
+}
+
+// NotFoundError reports a missing record.
+func NotFoundError(err error, message string) *AppError {
+	return newAppError(CodeNotFound, err, message)
+}
+
+// ConflictError reports a clash with existing data, e.g. a duplicate email.
+func ConflictError(err error, message string) *AppError {
+	return newAppError(CodeConflict, err, message)
+}
+
+// TooManyRequestsError reports a client that exceeded a rate limit.
+func TooManyRequestsError(err error, message string) *AppError {
+	return newAppError(CodeTooManyRequests, err, message)
 }
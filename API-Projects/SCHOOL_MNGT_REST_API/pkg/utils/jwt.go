@@ -1,44 +1,142 @@
 package utils
 
 import (
-	"errors"
 	"os"
+	"school_management_api/internal/models"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// SignToken generates a JWT token for a user with specified userId, username, and role.
-func SignToken(userId int, username string, role string) (string, error) {
-	jwtSecret := os.Getenv("JWT_SECRET")
+func init() {
+	// iat is compared with the microseconds of password_changed_at
+	jwt.TimePrecision = time.Microsecond
+}
+
+// DefaultAccessTokenTTL is how long an access token stays valid when
+// JWT_EXPIRES_IN is not set.
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+// AccessTokenTTL returns the lifetime of access tokens set with
+// JWT_EXPIRES_IN, e.g. "10m".
+func AccessTokenTTL() (time.Duration, error) {
 	jwtExpiresIn := os.Getenv("JWT_EXPIRES_IN")
+	if jwtExpiresIn == "" {
+		return DefaultAccessTokenTTL, nil
+	}
+	duration, err := time.ParseDuration(jwtExpiresIn)
+	if err != nil {
+		return 0, ErrorHandler(err, "Internal Error")
+	}
+	return duration, nil
+}
+
+// Token types, in the typ claim. Only access tokens authenticate requests.
+const (
+	AccessTokenType    = "access"
+	ChallengeTokenType = "2fa"
+)
+
+// ChallengeTokenTTL is how long the second step of a login may take.
+const ChallengeTokenTTL = 5 * time.Minute
 
-	if jwtSecret == "" {
-		return "", ErrorHandler(errors.New("JWT_SECRET not set"), "Internal Error")
+// SignChallengeToken generates the token of a login whose password was
+// checked but still needs the second factor. It cannot authenticate requests.
+func SignChallengeToken(userId int) (string, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", ErrorHandler(err, "Internal Error")
+	}
+
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", ErrorHandler(err, "Internal Error")
 	}
 
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"uid":  userId,
-		"user": username,
-		"role": role,
+		"typ": ChallengeTokenType,
+		"uid": userId,
+		"jti": jti,
+		"iat": jwt.NewNumericDate(now),
+		"exp": jwt.NewNumericDate(now.Add(ChallengeTokenTTL)),
 	}
 
-	var duration time.Duration
-	var err error
+	signedToken, err := ring.sign(claims)
+	if err != nil {
+		return "", ErrorHandler(err, "Internal Error")
+	}
+	return signedToken, nil
+}
 
-	if jwtExpiresIn == "" {
-		duration = 15 * time.Minute
-	} else {
-		duration, err = time.ParseDuration(jwtExpiresIn)
-		if err != nil {
-			return "", ErrorHandler(err, "Internal Error")
-		}
+// ParseChallengeToken verifies a challenge token and returns the ID of its
+// executive, its ID (jti) and its expiry.
+func ParseChallengeToken(token string) (int, string, time.Time, error) {
+	invalid := UnauthorizedError(nil, "two-factor challenge is invalid or has expired, please log in again")
+
+	parsedToken, err := ParseToken(token)
+	if err != nil || !parsedToken.Valid {
+		return 0, "", time.Time{}, invalid
+	}
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != ChallengeTokenType {
+		return 0, "", time.Time{}, invalid
+	}
+	uid, uidOK := claims["uid"].(float64)
+	jti, jtiOK := claims["jti"].(string)
+	exp, err := claims.GetExpirationTime()
+	if !uidOK || !jtiOK || jti == "" || err != nil || exp == nil {
+		return 0, "", time.Time{}, invalid
+	}
+	return int(uid), jti, exp.Time, nil
+}
+
+// SignToken generates a JWT token for a user with specified userId, username, and role.
+// Every token gets a unique ID (jti) so it can be revoked before it expires.
+// It is signed with the signing key of the key ring, named in its kid header.
+func SignToken(userId int, username string, role string) (string, error) {
+	return signAccessToken(models.SubjectExecutive, userId, username, role)
+}
+
+// SignAccountToken generates the token of a teacher or student account. Its
+// role is the subject type, so only routes open to that role accept it.
+func SignAccountToken(subjectType string, subjectID int, username string) (string, error) {
+	return signAccessToken(subjectType, subjectID, username, subjectType)
+}
+
+// signAccessToken generates an access token. uid is the ID of the subject,
+// in the table its subject_type names.
+func signAccessToken(subjectType string, userId int, username string, role string) (string, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", ErrorHandler(err, "Internal Error")
+	}
+
+	duration, err := AccessTokenTTL()
+	if err != nil {
+		return "", err
+	}
+
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", ErrorHandler(err, "Internal Error")
+	}
+
+	claims := jwt.MapClaims{
+		"typ":          AccessTokenType,
+		"subject_type": subjectType,
+		"uid":          userId,
+		"user":         username,
+		"role":         role,
+		"jti":          jti,
 	}
 
-	claims["exp"] = jwt.NewNumericDate(time.Now().Add(duration))
+	// iat lets the middleware reject tokens issued before a password change
+	now := time.Now()
+	claims["iat"] = jwt.NewNumericDate(now)
+	claims["exp"] = jwt.NewNumericDate(now.Add(duration))
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(jwtSecret))
+	signedToken, err := ring.sign(claims)
 	if err != nil {
 		return "", ErrorHandler(err, "Internal Error")
 	}
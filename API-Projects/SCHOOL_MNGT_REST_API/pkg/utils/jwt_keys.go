@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyConfig describes one key of the key ring. HS256 keys read their secret
+// from the environment variable named by SecretEnv. RS256 and EdDSA keys
+// read a PEM file: a private key for a key that can sign, or only a public
+// key for one that is kept to verify tokens it signed before a rotation.
+type KeyConfig struct {
+	KID            string `json:"kid"`
+	Alg            string `json:"alg"`
+	SecretEnv      string `json:"secret_env,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	PublicKeyFile  string `json:"public_key_file,omitempty"`
+}
+
+// KeyRingConfig is the JSON file named by JWT_KEYS_FILE. SigningKey is the
+// kid of the key new tokens are signed with; every key verifies tokens.
+type KeyRingConfig struct {
+	SigningKey string      `json:"signing_key"`
+	Keys       []KeyConfig `json:"keys"`
+}
+
+// jwtKey is a loaded key of the key ring.
+type jwtKey struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   any // nil for keys that only verify
+	verify any
+}
+
+// KeyRing holds the keys that verify tokens and the one that signs them.
+// Tokens name their key in the kid header, so keys can be rotated without
+// invalidating the tokens signed with the previous one.
+type KeyRing struct {
+	keys    map[string]jwtKey
+	signing jwtKey
+}
+
+// defaultKID is the kid of the HS256 key made from JWT_SECRET when no
+// JWT_KEYS_FILE is set.
+const defaultKID = "default"
+
+// keyRing is the key ring in use, swapped as a whole on reload.
+var keyRing atomic.Pointer[KeyRing]
+
+// LoadKeyRingFromEnv loads the key ring from the JSON file named by
+// JWT_KEYS_FILE or, without it, makes a single HS256 key from JWT_SECRET.
+func LoadKeyRingFromEnv() (*KeyRing, error) {
+	path := os.Getenv("JWT_KEYS_FILE")
+	if path == "" {
+		if os.Getenv("JWT_SECRET") == "" {
+			return nil, errors.New("JWT_SECRET not set")
+		}
+		return NewKeyRing(KeyRingConfig{
+			SigningKey: defaultKID,
+			Keys:       []KeyConfig{{KID: defaultKID, Alg: jwt.SigningMethodHS256.Alg(), SecretEnv: "JWT_SECRET"}},
+		})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config KeyRingConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid key ring %s: %w", path, err)
+	}
+	ring, err := NewKeyRing(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key ring %s: %w", path, err)
+	}
+	return ring, nil
+}
+
+// NewKeyRing loads the keys of config.
+func NewKeyRing(config KeyRingConfig) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]jwtKey)}
+	for _, keyConfig := range config.Keys {
+		if keyConfig.KID == "" {
+			return nil, errors.New("every key needs a kid")
+		}
+		if _, ok := ring.keys[keyConfig.KID]; ok {
+			return nil, fmt.Errorf("duplicate kid %q", keyConfig.KID)
+		}
+		key, err := loadKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", keyConfig.KID, err)
+		}
+		ring.keys[key.kid] = key
+	}
+
+	signing, ok := ring.keys[config.SigningKey]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not in the key ring", config.SigningKey)
+	}
+	if signing.sign == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", config.SigningKey)
+	}
+	ring.signing = signing
+	return ring, nil
+}
+
+// loadKey reads the secret or PEM file of a key.
+func loadKey(config KeyConfig) (jwtKey, error) {
+	key := jwtKey{kid: config.KID, method: jwt.GetSigningMethod(config.Alg)}
+
+	switch config.Alg {
+	case jwt.SigningMethodHS256.Alg():
+		secret := os.Getenv(config.SecretEnv)
+		if config.SecretEnv == "" || secret == "" {
+			return key, errors.New("HS256 keys need secret_env naming a set environment variable")
+		}
+		key.sign, key.verify = []byte(secret), []byte(secret)
+
+	case jwt.SigningMethodRS256.Alg():
+		if config.PrivateKeyFile != "" {
+			private, err := readPEM(config.PrivateKeyFile, jwt.ParseRSAPrivateKeyFromPEM)
+			if err != nil {
+				return key, err
+			}
+			key.sign, key.verify = private, &private.PublicKey
+		} else {
+			public, err := readPEM(config.PublicKeyFile, jwt.ParseRSAPublicKeyFromPEM)
+			if err != nil {
+				return key, err
+			}
+			key.verify = public
+		}
+
+	case jwt.SigningMethodEdDSA.Alg():
+		if config.PrivateKeyFile != "" {
+			private, err := readPEM(config.PrivateKeyFile, jwt.ParseEdPrivateKeyFromPEM)
+			if err != nil {
+				return key, err
+			}
+			key.sign, key.verify = private, private.(ed25519.PrivateKey).Public()
+		} else {
+			public, err := readPEM(config.PublicKeyFile, jwt.ParseEdPublicKeyFromPEM)
+			if err != nil {
+				return key, err
+			}
+			key.verify = public
+		}
+
+	default:
+		return key, fmt.Errorf("unsupported alg %q, use HS256, RS256 or EdDSA", config.Alg)
+	}
+	return key, nil
+}
+
+// readPEM reads a PEM file and parses it with parse.
+func readPEM[K any](path string, parse func([]byte) (K, error)) (K, error) {
+	var zero K
+	if path == "" {
+		return zero, errors.New("private_key_file or public_key_file is required")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return zero, err
+	}
+	return parse(data)
+}
+
+// SetKeyRing makes ring the key ring used to sign and verify tokens.
+func SetKeyRing(ring *KeyRing) {
+	keyRing.Store(ring)
+}
+
+// currentKeyRing returns the key ring in use, loading it from the
+// environment on first use.
+func currentKeyRing() (*KeyRing, error) {
+	if ring := keyRing.Load(); ring != nil {
+		return ring, nil
+	}
+	ring, err := LoadKeyRingFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	keyRing.CompareAndSwap(nil, ring)
+	return keyRing.Load(), nil
+}
+
+// sign signs claims with the signing key, naming it in the kid header.
+func (ring *KeyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ring.signing.method, claims)
+	token.Header["kid"] = ring.signing.kid
+	return token.SignedString(ring.signing.sign)
+}
+
+// keyfunc returns the key named by the kid header of a token. The token
+// must use the algorithm of that key, so a public key is never taken for
+// an HMAC secret.
+func (ring *KeyRing) keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ring.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+// ParseToken parses a token and verifies its signature and expiry with the
+// key ring.
+func ParseToken(token string) (*jwt.Token, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return nil, ErrorHandler(err, "Internal Error")
+	}
+	return jwt.Parse(token, ring.keyfunc,
+		jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}))
+}
+
+// JWK is a public key in the JSON Web Key format.
+type JWK struct {
+	KID string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS returns the public keys of the key ring, for other services to
+// verify our tokens. HS256 secrets are never published.
+func JWKS() ([]JWK, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return nil, ErrorHandler(err, "Internal Error")
+	}
+
+	keys := []JWK{}
+	for _, key := range ring.keys {
+		jwk := JWK{KID: key.kid, Alg: key.method.Alg(), Use: "sig"}
+		switch public := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	slices.SortFunc(keys, func(a, b JWK) int { return strings.Compare(a.KID, b.KID) })
+	return keys, nil
+}
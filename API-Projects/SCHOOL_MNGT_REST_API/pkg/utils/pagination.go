@@ -0,0 +1,388 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Page size limits for list endpoints.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// SortField is a single column of an ORDER BY clause.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// String formats the field the way it is given in the sortby parameter, e.g. "last_name:desc".
+func (s SortField) String() string {
+	if s.Desc {
+		return s.Column + ":desc"
+	}
+	return s.Column + ":asc"
+}
+
+// Cursor marks the position of the last row of a page for keyset pagination.
+// It holds the values of the sort columns and the ID of that row.
+type Cursor struct {
+	Sort   []string      `json:"s"`
+	Values []interface{} `json:"v"`
+	ID     int           `json:"id"`
+}
+
+// Pagination describes which page of a list to return. Either Page is used
+// (offset mode, ?page=2&limit=20) or After is set (cursor mode, ?after=<cursor>).
+// Cursor mode is forward-only: there is no before cursor, clients going back
+// keep the cursors of the pages they have seen or use offset mode.
+// A zero Limit means no limit.
+type Pagination struct {
+	Page  int
+	Limit int
+	Sort  []SortField
+	After *Cursor
+}
+
+// PageMeta is the meta block of a paginated list response. Prev is only set
+// in offset mode, cursor mode only moves forward.
+type PageMeta struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// ParsePagination reads the page, limit and after query parameters for a list
+// sorted by sort, usually the validated sort of a QuerySpec.
+// The limit defaults to DefaultPageLimit and is capped at MaxPageLimit.
+func ParsePagination(values url.Values, sort []SortField) (Pagination, error) {
+	pagination := Pagination{Page: 1, Limit: DefaultPageLimit, Sort: sort}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return Pagination{}, fmt.Errorf("invalid limit: %s", limit)
+		}
+		pagination.Limit = min(n, MaxPageLimit)
+	}
+
+	after := values.Get("after")
+	page := values.Get("page")
+	if after != "" && page != "" {
+		return Pagination{}, errors.New("page and after cannot be used together")
+	}
+
+	if page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return Pagination{}, fmt.Errorf("invalid page: %s", page)
+		}
+		pagination.Page = n
+	}
+
+	if after != "" {
+		cursor, err := decodeCursor(after)
+		if err != nil {
+			return Pagination{}, err
+		}
+		if !sameSort(cursor.Sort, pagination.Sort) {
+			return Pagination{}, errors.New("cursor does not match the sortby parameters")
+		}
+		pagination.Page = 0
+		pagination.After = &cursor
+	}
+	return pagination, nil
+}
+
+// Offset returns the number of rows to skip in offset mode.
+func (p Pagination) Offset() int {
+	if p.After != nil || p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// FetchLimit returns how many rows a repository should load: one more than
+// the page size so the caller can tell whether another page follows.
+// It returns 0 when there is no limit.
+func (p Pagination) FetchLimit() int {
+	if p.Limit == 0 {
+		return 0
+	}
+	return p.Limit + 1
+}
+
+// BuildLimitClause returns the LIMIT/OFFSET clause and its arguments for the pagination.
+func (p Pagination) BuildLimitClause() (string, []interface{}) {
+	if p.Limit == 0 {
+		return "", nil
+	}
+	return " LIMIT ? OFFSET ?", []interface{}{p.FetchLimit(), p.Offset()}
+}
+
+// BuildKeysetClause returns the condition selecting the rows after the cursor,
+// e.g. for sortby=last_name:asc:
+//
+//	AND ((last_name > ?) OR (last_name = ? AND id > ?))
+//
+// NULL sorts first, as in MariaDB, so a NULL cursor value is compared with
+// IS NULL and IS NOT NULL instead.
+func (p Pagination) BuildKeysetClause() (string, []interface{}) {
+	if p.After == nil {
+		return "", nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	for i := 0; i <= len(p.Sort); i++ {
+		var parts []string
+		for j := 0; j < i; j++ {
+			if p.After.Values[j] == nil {
+				parts = append(parts, p.Sort[j].Column+" IS NULL")
+				continue
+			}
+			parts = append(parts, p.Sort[j].Column+" = ?")
+			args = append(args, p.After.Values[j])
+		}
+		if i < len(p.Sort) {
+			part, partArgs := keysetComparison(p.Sort[i], p.After.Values[i])
+			parts = append(parts, part)
+			args = append(args, partArgs...)
+		} else {
+			parts = append(parts, "id > ?")
+			args = append(args, p.After.ID)
+		}
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return " AND (" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// keysetComparison returns the condition selecting the values of a sort
+// column that come after the cursor value. NULL comes before every value
+// in ascending order and after every value in descending order.
+func keysetComparison(field SortField, value interface{}) (string, []interface{}) {
+	switch {
+	case value == nil && field.Desc:
+		return "FALSE", nil
+	case value == nil:
+		return field.Column + " IS NOT NULL", nil
+	case field.Desc:
+		return fmt.Sprintf("(%s < ? OR %s IS NULL)", field.Column, field.Column), []interface{}{value}
+	}
+	return field.Column + " > ?", []interface{}{value}
+}
+
+// Paginate trims the rows fetched with FetchLimit to the page size and builds
+// the meta block, with next and prev links relative to the request URL.
+// The next cursor is returned in both modes so clients can switch to cursor
+// mode after the first page. Cursor mode has no prev link, see Pagination.
+func Paginate[T any](items []T, total int, p Pagination, requestURL *url.URL) ([]T, PageMeta) {
+	meta := PageMeta{Total: total, Limit: p.Limit}
+	hasNext := p.Limit > 0 && len(items) > p.Limit
+	if hasNext {
+		items = items[:p.Limit]
+		meta.NextCursor = encodeCursor(newCursor(items[len(items)-1], p.Sort))
+	}
+
+	if p.After != nil {
+		if hasNext {
+			meta.Next = pageLink(requestURL, p.Limit, "after", meta.NextCursor)
+		}
+		return items, meta
+	}
+
+	meta.Page = p.Page
+	if p.Limit > 0 {
+		meta.TotalPages = (total + p.Limit - 1) / p.Limit
+	}
+	if hasNext {
+		meta.Next = pageLink(requestURL, p.Limit, "page", strconv.Itoa(p.Page+1))
+	}
+	if p.Page > 1 {
+		meta.Prev = pageLink(requestURL, p.Limit, "page", strconv.Itoa(p.Page-1))
+	}
+	return items, meta
+}
+
+// pageLink returns the request path and query pointing at another page,
+// with key set to value and the effective limit.
+func pageLink(requestURL *url.URL, limit int, key, value string) string {
+	query := requestURL.Query()
+	query.Del("page")
+	query.Del("after")
+	query.Set(key, value)
+	query.Set("limit", strconv.Itoa(limit))
+	return requestURL.Path + "?" + query.Encode()
+}
+
+// newCursor builds the cursor pointing at item for the given sort order.
+func newCursor(item interface{}, sort []SortField) Cursor {
+	itemValue := reflect.ValueOf(item)
+	cursor := Cursor{Sort: sortStrings(sort)}
+	for _, field := range sort {
+		value, _ := FieldByColumn(itemValue, field.Column)
+		cursor.Values = append(cursor.Values, CursorValue(value))
+	}
+	if id, ok := FieldByColumn(itemValue, "id"); ok {
+		cursor.ID = int(id.Int())
+	}
+	return cursor
+}
+
+// IsAfterCursor reports whether item sorts after the cursor, the in-memory
+// equivalent of BuildKeysetClause. It is true for every item without a cursor.
+func (p Pagination) IsAfterCursor(item interface{}) bool {
+	if p.After == nil {
+		return true
+	}
+
+	itemValue := reflect.ValueOf(item)
+	for i, field := range p.Sort {
+		value, _ := FieldByColumn(itemValue, field.Column)
+		cmp := compareCursorValues(CursorValue(value), p.After.Values[i])
+		if cmp == 0 {
+			continue
+		}
+		if field.Desc {
+			return cmp < 0
+		}
+		return cmp > 0
+	}
+
+	id, _ := FieldByColumn(itemValue, "id")
+	return int(id.Int()) > p.After.ID
+}
+
+// compareCursorValues compares a field value with a value decoded from a cursor.
+// JSON decodes numbers as float64; NULL sorts first and strings ignore case,
+// as in MariaDB.
+func compareCursorValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	toFloat := func(v interface{}) (float64, bool) {
+		switch n := v.(type) {
+		case int:
+			return float64(n), true
+		case int64:
+			return float64(n), true
+		case float64:
+			return n, true
+		case bool:
+			if n {
+				return 1, true
+			}
+			return 0, true
+		}
+		return 0, false
+	}
+
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return CompareFold(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// CompareFold compares strings ignoring case, like the utf8mb4 collation of
+// the database sorts and filters them.
+func CompareFold(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// CursorValue converts a field value into the form stored in a cursor.
+// Nullable fields, valuers or pointers, give nil or their value.
+func CursorValue(value reflect.Value) interface{} {
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if valuer, ok := value.Interface().(driver.Valuer); ok {
+		v, _ := valuer.Value()
+		return v
+	}
+	return value.Interface()
+}
+
+func encodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Values) != len(cursor.Sort) {
+		return Cursor{}, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
+
+func sortStrings(sort []SortField) []string {
+	strs := make([]string, len(sort))
+	for i, field := range sort {
+		strs[i] = field.String()
+	}
+	return strs
+}
+
+func sameSort(cursorSort []string, sort []SortField) bool {
+	if len(cursorSort) != len(sort) {
+		return false
+	}
+	for i, field := range sort {
+		if cursorSort[i] != field.String() {
+			return false
+		}
+	}
+	return true
+}
+
+// FieldByColumn returns the value of the struct field mapped to the given
+// column by its db tag.
+func FieldByColumn(item reflect.Value, column string) (reflect.Value, bool) {
+	if item.Kind() == reflect.Ptr {
+		item = item.Elem()
+	}
+	itemType := item.Type()
+	for i := 0; i < itemType.NumField(); i++ {
+		dbTag := strings.TrimSpace(strings.Split(itemType.Field(i).Tag.Get("db"), ",")[0])
+		if dbTag == column {
+			return item.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"database/sql"
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestBuildKeysetClause(t *testing.T) {
+	tests := []struct {
+		name       string
+		sort       []SortField
+		values     []interface{}
+		wantClause string
+		wantArgs   []interface{}
+	}{
+		{
+			name:       "id only",
+			wantClause: " AND ((id > ?))",
+			wantArgs:   []interface{}{5},
+		},
+		{
+			name:       "ascending",
+			sort:       []SortField{{Column: "last_name"}},
+			values:     []interface{}{"Doe"},
+			wantClause: " AND ((last_name > ?) OR (last_name = ? AND id > ?))",
+			wantArgs:   []interface{}{"Doe", "Doe", 5},
+		},
+		{
+			name:       "descending passes the NULLs last",
+			sort:       []SortField{{Column: "last_name", Desc: true}},
+			values:     []interface{}{"Doe"},
+			wantClause: " AND (((last_name < ? OR last_name IS NULL)) OR (last_name = ? AND id > ?))",
+			wantArgs:   []interface{}{"Doe", "Doe", 5},
+		},
+		{
+			name:       "ascending from NULL",
+			sort:       []SortField{{Column: "homeroom_teacher_id"}},
+			values:     []interface{}{nil},
+			wantClause: " AND ((homeroom_teacher_id IS NOT NULL) OR (homeroom_teacher_id IS NULL AND id > ?))",
+			wantArgs:   []interface{}{5},
+		},
+		{
+			name:       "descending from NULL",
+			sort:       []SortField{{Column: "homeroom_teacher_id", Desc: true}},
+			values:     []interface{}{nil},
+			wantClause: " AND ((FALSE) OR (homeroom_teacher_id IS NULL AND id > ?))",
+			wantArgs:   []interface{}{5},
+		},
+		{
+			name:       "two columns",
+			sort:       []SortField{{Column: "class"}, {Column: "last_name", Desc: true}},
+			values:     []interface{}{"10A", nil},
+			wantClause: " AND ((class > ?) OR (class = ? AND FALSE) OR (class = ? AND last_name IS NULL AND id > ?))",
+			wantArgs:   []interface{}{"10A", "10A", "10A", 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Pagination{Sort: tt.sort, After: &Cursor{Sort: sortStrings(tt.sort), Values: tt.values, ID: 5}}
+			clause, args := p.BuildKeysetClause()
+			if clause != tt.wantClause {
+				t.Errorf("clause = %q, want %q", clause, tt.wantClause)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	if clause, args := (Pagination{}).BuildKeysetClause(); clause != "" || args != nil {
+		t.Errorf("without cursor: clause = %q, args = %v, want none", clause, args)
+	}
+}
+
+// pageRow has nullable columns of both kinds, pointers and valuers.
+type pageRow struct {
+	ID     int            `db:"id"`
+	Name   string         `db:"name"`
+	Grade  *int           `db:"grade"`
+	Joined sql.NullString `db:"joined"`
+}
+
+func TestIsAfterCursor(t *testing.T) {
+	grade := func(n int) *int { return &n }
+	joined := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	rows := []pageRow{
+		{ID: 1, Name: "Ann", Grade: grade(10), Joined: joined("2024-01-02")},
+		{ID: 2, Name: "Bob"},
+		{ID: 3, Name: "Cid", Grade: grade(9), Joined: joined("2024-01-01")},
+		{ID: 4, Name: "Ann", Joined: joined("2024-01-03")},
+		{ID: 5, Name: "Dee", Grade: grade(10)},
+		{ID: 6, Name: "bea"},
+	}
+
+	// Each order lists the IDs as MariaDB sorts them, NULL first when ascending
+	// and strings without case
+	tests := []struct {
+		name  string
+		sort  []SortField
+		order []int
+	}{
+		{name: "id", order: []int{1, 2, 3, 4, 5, 6}},
+		{name: "nullable int ascending", sort: []SortField{{Column: "grade"}}, order: []int{2, 4, 6, 3, 1, 5}},
+		{name: "nullable int descending", sort: []SortField{{Column: "grade", Desc: true}}, order: []int{1, 5, 3, 2, 4, 6}},
+		{name: "nullable string descending", sort: []SortField{{Column: "joined", Desc: true}}, order: []int{4, 1, 3, 2, 5, 6}},
+		{
+			name:  "two columns",
+			sort:  []SortField{{Column: "name"}, {Column: "grade", Desc: true}},
+			order: []int{1, 4, 6, 2, 3, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, id := range tt.order {
+				// Go through the encoded cursor, which turns numbers into float64
+				after := encodeCursor(newCursor(rows[id-1], tt.sort))
+				p, err := ParsePagination(url.Values{"after": {after}}, tt.sort)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var got []int
+				for _, row := range rows {
+					if p.IsAfterCursor(row) {
+						got = append(got, row.ID)
+					}
+				}
+				want := slices.Clone(tt.order[i+1:])
+				slices.Sort(want)
+				if !slices.Equal(got, want) {
+					t.Errorf("after row %d: got rows %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+
+	if !(Pagination{}).IsAfterCursor(rows[0]) {
+		t.Error("IsAfterCursor without cursor = false, want true")
+	}
+}
@@ -1,43 +1,214 @@
 package utils
 
 import (
+	"crypto/rand"
 	"crypto/subtle"
+	"database/sql"
 	"encoding/base64"
 	"errors"
-	"school_management_api/internal/models"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/crypto/argon2"
 )
 
-// VerifyPassword verifies the provided password against the stored hash.
-func VerifyPassword(user models.Executive, password string) error {
-	// split stored password into salt and hash
-	parts := strings.Split(user.Password, ".")
-	if len(parts) != 2 {
-		return ErrorHandler(errors.New("invalid stored password format"), "internal server error")
+// Argon2Params are the cost parameters of an Argon2id password hash.
+type Argon2Params struct {
+	Memory      uint32 // memory in KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params are used for the settings not given in the
+// environment. They are also the parameters of legacy "salt.hash" values.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  1,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2ParamsFromEnv returns the parameters for new password hashes:
+//   - ARGON2_MEMORY: memory in KiB (default 65536)
+//   - ARGON2_ITERATIONS: number of passes over the memory (default 1)
+//   - ARGON2_PARALLELISM: number of threads (default 4)
+//
+// Stored hashes keep the parameters they were made with, so raising them
+// does not break existing logins.
+func Argon2ParamsFromEnv() (Argon2Params, error) {
+	params := DefaultArgon2Params
+
+	for key, value := range map[string]*uint32{
+		"ARGON2_MEMORY":     &params.Memory,
+		"ARGON2_ITERATIONS": &params.Iterations,
+	} {
+		if env := os.Getenv(key); env != "" {
+			n, err := strconv.ParseUint(env, 10, 32)
+			if err != nil || n == 0 {
+				return params, fmt.Errorf("invalid value for %s: %q", key, env)
+			}
+			*value = uint32(n)
+		}
+	}
+
+	if env := os.Getenv("ARGON2_PARALLELISM"); env != "" {
+		n, err := strconv.ParseUint(env, 10, 8)
+		if err != nil || n == 0 {
+			return params, fmt.Errorf("invalid value for ARGON2_PARALLELISM: %q", env)
+		}
+		params.Parallelism = uint8(n)
+	}
+	return params, nil
+}
+
+// HashPassword hashes a plain text password using Argon2id with a random salt
+// and the parameters of Argon2ParamsFromEnv. The hash is encoded in the PHC
+// string format, $argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>, so it records
+// its own parameters.
+func HashPassword(password string) (string, error) {
+	params, err := Argon2ParamsFromEnv()
+	if err != nil {
+		return "", ErrorHandler(err, "failed to hash password")
+	}
+
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", ErrorHandler(errors.New("failed to generate salt"), "failed to hash password")
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+// decodePasswordHash returns the parameters, salt and hash of a stored
+// password, either in the PHC string format or the legacy "salt.hash" one.
+func decodePasswordHash(encodedHash string) (Argon2Params, []byte, []byte, error) {
+	if !strings.HasPrefix(encodedHash, "$") {
+		return decodeLegacyHash(encodedHash)
+	}
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, errors.New("invalid stored password format")
 	}
 
-	saltBase64, hashBase64 := parts[0], parts[1]
-	salt, err := base64.StdEncoding.DecodeString(saltBase64)
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, errors.New("unsupported argon2 version")
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode the salt: %w", err)
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
 	if err != nil {
-		return ErrorHandler(err, "failed to decode the salt")
+		return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode the hashed password: %w", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(hash))
+	return params, salt, hash, nil
+}
+
+// decodeLegacyHash decodes a "salt.hash" value, made with the default parameters.
+func decodeLegacyHash(encodedHash string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(encodedHash, ".")
+	if len(parts) != 2 {
+		return Argon2Params{}, nil, nil, errors.New("invalid stored password format")
 	}
 
-	hashedPassword, err := base64.StdEncoding.DecodeString(hashBase64)
+	salt, err := base64.StdEncoding.DecodeString(parts[0])
 	if err != nil {
-		return ErrorHandler(err, "failed to decode the hashed password")
+		return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode the salt: %w", err)
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("failed to decode the hashed password: %w", err)
 	}
 
-	hash := argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 32)
+	params := DefaultArgon2Params
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(hash))
+	return params, salt, hash, nil
+}
 
-	if len(hash) != len(hashedPassword) {
-		return ErrorHandler(errors.New("hashed password length mismatch"), "password verification failed")
+// VerifyPassword verifies the provided password against the stored hash,
+// using the parameters recorded with it.
+func VerifyPassword(encodedHash string, password string) error {
+	params, salt, hashedPassword, err := decodePasswordHash(encodedHash)
+	if err != nil {
+		return ErrorHandler(err, "internal server error")
 	}
 
+	hash := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
 	// constant time comparison to prevent timing attacks
 	if subtle.ConstantTimeCompare(hash, hashedPassword) != 1 {
 		return ErrorHandler(errors.New("incorrect password"), "password verification failed")
 	}
 	return nil
 }
+
+// PasswordNeedsRehash reports whether a stored hash is in the legacy format
+// or was made with other parameters than new hashes get.
+func PasswordNeedsRehash(encodedHash string) (bool, error) {
+	current, err := Argon2ParamsFromEnv()
+	if err != nil {
+		return false, ErrorHandler(err, "failed to hash password")
+	}
+	if !strings.HasPrefix(encodedHash, "$") {
+		return true, nil
+	}
+
+	params, _, _, err := decodePasswordHash(encodedHash)
+	if err != nil {
+		return false, ErrorHandler(err, "internal server error")
+	}
+	return params != current, nil
+}
+
+// dummyPasswordHash is checked when a login names an unknown user, so the
+// answer takes as long as for a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("dummy password for unknown users")
+	return hash
+})
+
+// VerifyDummyPassword spends the time of a password check without a user.
+func VerifyDummyPassword(password string) {
+	VerifyPassword(dummyPasswordHash(), password)
+}
+
+// DateTimeMicro is time.DateTime with microseconds, the layout of the
+// DATETIME(6) columns a token issue time is compared with.
+const DateTimeMicro = "2006-01-02 15:04:05.000000"
+
+// PasswordChangedAfter reports whether a password last changed at changedAt
+// was changed after t, e.g. after a token was issued. password_changed_at
+// keeps microseconds like the iat claim, so a token minted just before a
+// change in the same second is issued before it.
+func PasswordChangedAfter(changedAt sql.NullString, t time.Time) (bool, error) {
+	if !changedAt.Valid {
+		return false, nil
+	}
+	changed, err := time.ParseInLocation(time.DateTime, changedAt.String, time.UTC)
+	if err != nil {
+		return false, ErrorHandler(err, "Internal Error")
+	}
+	return t.Before(changed), nil
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// cheapArgon2Env makes new hashes fast to compute in tests.
+func cheapArgon2Env(t *testing.T) {
+	t.Helper()
+	t.Setenv("ARGON2_MEMORY", "1024")
+	t.Setenv("ARGON2_ITERATIONS", "1")
+	t.Setenv("ARGON2_PARALLELISM", "1")
+}
+
+// legacyHash encodes password in the legacy "salt.hash" format.
+func legacyHash(password string) string {
+	params := DefaultArgon2Params
+	salt := []byte("0123456789abcdef")
+	hash := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return base64.StdEncoding.EncodeToString(salt) + "." + base64.StdEncoding.EncodeToString(hash)
+}
+
+func TestDecodePasswordHash(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	hash := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    Argon2Params
+		wantErr bool
+	}{
+		{
+			name:    "phc",
+			encoded: "$argon2id$v=19$m=1024,t=2,p=1$" + salt + "$" + hash,
+			want:    Argon2Params{Memory: 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+		},
+		{
+			name:    "legacy",
+			encoded: base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")) + "." + base64.StdEncoding.EncodeToString(make([]byte, 32)),
+			want:    DefaultArgon2Params,
+		},
+		{name: "other algorithm", encoded: "$argon2i$v=19$m=1024,t=2,p=1$" + salt + "$" + hash, wantErr: true},
+		{name: "other version", encoded: "$argon2id$v=16$m=1024,t=2,p=1$" + salt + "$" + hash, wantErr: true},
+		{name: "bad parameters", encoded: "$argon2id$v=19$m=x,t=2,p=1$" + salt + "$" + hash, wantErr: true},
+		{name: "bad salt", encoded: "$argon2id$v=19$m=1024,t=2,p=1$!!$" + hash, wantErr: true},
+		{name: "bad hash", encoded: "$argon2id$v=19$m=1024,t=2,p=1$" + salt + "$!!", wantErr: true},
+		{name: "missing hash", encoded: "$argon2id$v=19$m=1024,t=2,p=1$" + salt, wantErr: true},
+		{name: "legacy without hash", encoded: "c2FsdA==", wantErr: true},
+		{name: "legacy bad salt", encoded: "!!.aGFzaA==", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _, _, err := decodePasswordHash(tt.encoded)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodePasswordHash(%q) succeeded, want an error", tt.encoded)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodePasswordHash(%q): %v", tt.encoded, err)
+			}
+			if params != tt.want {
+				t.Errorf("params = %+v, want %+v", params, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	cheapArgon2Env(t)
+	current, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ARGON2_ITERATIONS", "2")
+	stronger, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		wantErr  bool
+	}{
+		{name: "phc", hash: current, password: "correct horse"},
+		{name: "phc wrong password", hash: current, password: "correct horse ", wantErr: true},
+		{name: "other parameters", hash: stronger, password: "correct horse"},
+		{name: "legacy", hash: legacyHash("correct horse"), password: "correct horse"},
+		{name: "legacy wrong password", hash: legacyHash("correct horse"), password: "battery staple", wantErr: true},
+		{name: "malformed", hash: "$argon2id$v=19$m=1024", password: "correct horse", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyPassword(tt.hash, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHashPasswordRecordsParameters(t *testing.T) {
+	cheapArgon2Env(t)
+	t.Setenv("ARGON2_ITERATIONS", "3")
+
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=3,p=1$") {
+		t.Errorf("HashPassword() = %q, want the parameters in the PHC string", hash)
+	}
+
+	other, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == other {
+		t.Error("two hashes of the same password are equal, want random salts")
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	cheapArgon2Env(t)
+	current, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		hash       string
+		iterations string
+		want       bool
+		wantErr    bool
+	}{
+		{name: "current parameters", hash: current, iterations: "1", want: false},
+		{name: "raised iterations", hash: current, iterations: "2", want: true},
+		{name: "legacy", hash: legacyHash("correct horse"), iterations: "1", want: true},
+		{name: "malformed", hash: "$argon2id$v=19", iterations: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ARGON2_ITERATIONS", tt.iterations)
+			got, err := PasswordNeedsRehash(tt.hash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PasswordNeedsRehash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PasswordNeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordChangedAfter(t *testing.T) {
+	changed := time.Date(2025, 3, 3, 8, 0, 0, 500_000_000, time.UTC)
+	changedAt := sql.NullString{String: changed.Format(DateTimeMicro), Valid: true}
+
+	tests := []struct {
+		name      string
+		changedAt sql.NullString
+		issued    time.Time
+		want      bool
+	}{
+		{name: "issued earlier in the same second", changedAt: changedAt, issued: changed.Add(-400 * time.Millisecond), want: true},
+		{name: "issued later in the same second", changedAt: changedAt, issued: changed.Add(300 * time.Millisecond)},
+		{name: "issued at the change", changedAt: changedAt, issued: changed},
+		{name: "issued a second before", changedAt: changedAt, issued: changed.Add(-time.Second), want: true},
+		{name: "stored without microseconds", changedAt: sql.NullString{String: "2025-03-03 08:00:00", Valid: true}, issued: changed},
+		{name: "never changed", issued: changed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The issue time goes through the iat claim like a token's
+			data, err := json.Marshal(jwt.NewNumericDate(tt.issued))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var iat jwt.NumericDate
+			if err := json.Unmarshal(data, &iat); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := PasswordChangedAfter(tt.changedAt, iat.Time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PasswordChangedAfter(%q, %v) = %v, want %v", tt.changedAt.String, iat.Time, got, tt.want)
+			}
+		})
+	}
+}
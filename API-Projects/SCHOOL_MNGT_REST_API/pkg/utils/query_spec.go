@@ -0,0 +1,374 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterOp is a comparison operator of a list filter.
+type FilterOp string
+
+// Supported filter operators, given as field[op]=value in the query string.
+// A plain field=value means eq.
+const (
+	OpEq     FilterOp = "eq"
+	OpNe     FilterOp = "ne"
+	OpGt     FilterOp = "gt"
+	OpGte    FilterOp = "gte"
+	OpLt     FilterOp = "lt"
+	OpLte    FilterOp = "lte"
+	OpIn     FilterOp = "in"      // comma separated values: class[in]=10A,10B
+	OpLike   FilterOp = "like"    // SQL LIKE pattern with % and _ wildcards
+	OpPrefix FilterOp = "prefix"  // starts with: last_name[prefix]=Mc
+	OpIsNull FilterOp = "is_null" // true or false
+	OpRange  FilterOp = "between" // inclusive range: user_created_at[between]=2024-01-01,2024-06-30
+)
+
+// reservedParams are the query parameters that are not filters.
+var reservedParams = map[string]bool{
+	"sortby": true,
+	"page":   true,
+	"limit":  true,
+	"after":  true,
+}
+
+// Filter is a single condition on a column.
+type Filter struct {
+	Column string
+	Op     FilterOp
+	Values []interface{}
+}
+
+// QuerySpec holds the validated filters and sort order of a list request.
+type QuerySpec struct {
+	Filters []Filter
+	Sort    []SortField
+}
+
+// EqFilter returns a filter matching rows where column equals value.
+func EqFilter(column string, value interface{}) Filter {
+	return Filter{Column: column, Op: OpEq, Values: []interface{}{value}}
+}
+
+// filterParam matches "field" and "field[op]" query parameter names.
+var filterParam = regexp.MustCompile(`^([a-z_]+)(?:\[([a-z_]+)\])?$`)
+
+// QueryColumns returns the filterable and sortable columns of a model mapped
+// to their Go types. Columns come from the db tags; fields tagged query:"-"
+// are left out.
+func QueryColumns(model interface{}) map[string]reflect.Type {
+	modelType := reflect.TypeOf(model)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	columns := make(map[string]reflect.Type)
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		dbTag := strings.TrimSpace(strings.Split(field.Tag.Get("db"), ",")[0])
+		if dbTag == "" || dbTag == "-" || field.Tag.Get("query") == "-" {
+			continue
+		}
+		// Pointers are nullable columns of the type they point to
+		columnType := field.Type
+		if columnType.Kind() == reflect.Ptr {
+			columnType = columnType.Elem()
+		}
+		columns[dbTag] = columnType
+	}
+	return columns
+}
+
+// ParseQuerySpec reads the filter and sortby query parameters of a list request
+// and validates them against the columns of model. Unknown fields, operators
+// and malformed values are reported as errors.
+//
+// Examples:
+//
+//	/students?class_id=3&last_name[prefix]=Mc&sortby=last_name:asc
+//	/teachers?subject[in]=Physics,Chemistry&email[ne]=a@b.com
+//	/executives?user_created_at[between]=2024-01-01,2024-06-30&sortby=user_created_at:desc
+//	/classes?homeroom_teacher_id[is_null]=true
+func ParseQuerySpec(model interface{}, values url.Values) (QuerySpec, error) {
+	columns := QueryColumns(model)
+	var spec QuerySpec
+
+	for param, paramValues := range values {
+		if reservedParams[param] {
+			continue
+		}
+
+		match := filterParam.FindStringSubmatch(param)
+		if match == nil {
+			return QuerySpec{}, fmt.Errorf("invalid filter parameter: %s", param)
+		}
+		column, op := match[1], FilterOp(match[2])
+		if op == "" {
+			op = OpEq
+		}
+
+		columnType, ok := columns[column]
+		if !ok {
+			return QuerySpec{}, fmt.Errorf("unknown filter field: %s", column)
+		}
+
+		for _, raw := range paramValues {
+			filters, err := parseFilter(column, columnType, op, raw)
+			if err != nil {
+				return QuerySpec{}, err
+			}
+			spec.Filters = append(spec.Filters, filters...)
+		}
+	}
+
+	for _, param := range values["sortby"] {
+		field, direction, _ := strings.Cut(param, ":")
+		if _, ok := columns[field]; !ok {
+			return QuerySpec{}, fmt.Errorf("unknown sort field: %s", field)
+		}
+
+		switch strings.ToLower(direction) {
+		case "", "asc":
+			spec.Sort = append(spec.Sort, SortField{Column: field})
+		case "desc":
+			spec.Sort = append(spec.Sort, SortField{Column: field, Desc: true})
+		default:
+			return QuerySpec{}, fmt.Errorf("invalid sort direction for %s: %s", field, direction)
+		}
+	}
+
+	return spec, nil
+}
+
+// parseFilter converts one filter parameter into filters with typed values.
+// Ranges are split into gte and lte filters, and a date-only upper bound
+// includes the whole day.
+func parseFilter(column string, columnType reflect.Type, op FilterOp, raw string) ([]Filter, error) {
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt:
+		value, err := parseFilterValue(column, columnType, raw)
+		if err != nil {
+			return nil, err
+		}
+		return []Filter{{Column: column, Op: op, Values: []interface{}{value}}}, nil
+
+	case OpLte:
+		value, err := parseFilterValue(column, columnType, raw)
+		if err != nil {
+			return nil, err
+		}
+		// A date-only bound on a datetime column means "up to the end of that day"
+		if day, err := time.Parse(time.DateOnly, raw); err == nil && columnType.Kind() != reflect.Int {
+			return []Filter{{Column: column, Op: OpLt, Values: []interface{}{day.AddDate(0, 0, 1).Format(time.DateOnly)}}}, nil
+		}
+		return []Filter{{Column: column, Op: OpLte, Values: []interface{}{value}}}, nil
+
+	case OpRange:
+		from, to, ok := strings.Cut(raw, ",")
+		if !ok {
+			return nil, fmt.Errorf("%s[between] needs two comma separated values", column)
+		}
+		lower, err := parseFilter(column, columnType, OpGte, from)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := parseFilter(column, columnType, OpLte, to)
+		if err != nil {
+			return nil, err
+		}
+		return append(lower, upper...), nil
+
+	case OpIn:
+		var values []interface{}
+		for _, part := range strings.Split(raw, ",") {
+			value, err := parseFilterValue(column, columnType, part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return []Filter{{Column: column, Op: OpIn, Values: values}}, nil
+
+	case OpLike, OpPrefix:
+		if raw == "" {
+			return nil, fmt.Errorf("%s[%s] needs a value", column, op)
+		}
+		return []Filter{{Column: column, Op: op, Values: []interface{}{raw}}}, nil
+
+	case OpIsNull:
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s[is_null] must be true or false", column)
+		}
+		return []Filter{{Column: column, Op: OpIsNull, Values: []interface{}{isNull}}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown filter operator for %s: %s", column, op)
+}
+
+// parseFilterValue converts a query string value to the type of the column.
+func parseFilterValue(column string, columnType reflect.Type, raw string) (interface{}, error) {
+	switch columnType.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %s is not a number", column, raw)
+		}
+		return n, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %s is not a number", column, raw)
+		}
+		return f, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %s is not a boolean", column, raw)
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// BuildWhereClause returns the filter conditions, each starting with " AND ",
+// and their arguments. Column names come from the validated spec only.
+func (spec QuerySpec) BuildWhereClause() (string, []interface{}) {
+	var clause strings.Builder
+	var args []interface{}
+
+	for _, filter := range spec.Filters {
+		switch filter.Op {
+		case OpEq:
+			fmt.Fprintf(&clause, " AND %s = ?", filter.Column)
+		case OpNe:
+			fmt.Fprintf(&clause, " AND %s <> ?", filter.Column)
+		case OpGt:
+			fmt.Fprintf(&clause, " AND %s > ?", filter.Column)
+		case OpGte:
+			fmt.Fprintf(&clause, " AND %s >= ?", filter.Column)
+		case OpLt:
+			fmt.Fprintf(&clause, " AND %s < ?", filter.Column)
+		case OpLte:
+			fmt.Fprintf(&clause, " AND %s <= ?", filter.Column)
+		case OpIn:
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(filter.Values)), ", ")
+			fmt.Fprintf(&clause, " AND %s IN (%s)", filter.Column, placeholders)
+		case OpLike:
+			fmt.Fprintf(&clause, " AND %s LIKE ?", filter.Column)
+		case OpPrefix:
+			fmt.Fprintf(&clause, " AND %s LIKE ?", filter.Column)
+			args = append(args, escapeLike(filter.Values[0].(string))+"%")
+			continue
+		case OpIsNull:
+			if filter.Values[0].(bool) {
+				fmt.Fprintf(&clause, " AND %s IS NULL", filter.Column)
+			} else {
+				fmt.Fprintf(&clause, " AND %s IS NOT NULL", filter.Column)
+			}
+			continue
+		}
+		args = append(args, filter.Values...)
+	}
+	return clause.String(), args
+}
+
+// BuildOrderByClause returns the ORDER BY clause of the spec.
+// The id is always added last so the order is stable across pages.
+func (spec QuerySpec) BuildOrderByClause() string {
+	orderBy := " ORDER BY "
+	for _, field := range spec.Sort {
+		order := "ASC"
+		if field.Desc {
+			order = "DESC"
+		}
+		orderBy += fmt.Sprintf("%s %s, ", field.Column, order)
+	}
+	return orderBy + "id ASC"
+}
+
+// Match reports whether item satisfies every filter of the spec, the in-memory
+// equivalent of BuildWhereClause. Strings compare case-insensitively like the
+// default MariaDB collation.
+func (spec QuerySpec) Match(item interface{}) bool {
+	itemValue := reflect.ValueOf(item)
+	for _, filter := range spec.Filters {
+		field, ok := FieldByColumn(itemValue, filter.Column)
+		if !ok || !matchFilter(field, filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFilter(field reflect.Value, filter Filter) bool {
+	value := CursorValue(field)
+
+	if filter.Op == OpIsNull {
+		return (value == nil) == filter.Values[0].(bool)
+	}
+	// Comparisons with NULL never match, as in SQL
+	if value == nil {
+		return false
+	}
+
+	switch filter.Op {
+	case OpEq:
+		return compareCursorValues(value, filter.Values[0]) == 0
+	case OpNe:
+		return compareCursorValues(value, filter.Values[0]) != 0
+	case OpGt:
+		return compareCursorValues(value, filter.Values[0]) > 0
+	case OpGte:
+		return compareCursorValues(value, filter.Values[0]) >= 0
+	case OpLt:
+		return compareCursorValues(value, filter.Values[0]) < 0
+	case OpLte:
+		return compareCursorValues(value, filter.Values[0]) <= 0
+	case OpIn:
+		for _, candidate := range filter.Values {
+			if compareCursorValues(value, candidate) == 0 {
+				return true
+			}
+		}
+		return false
+	case OpLike:
+		return likePattern(filter.Values[0].(string)).MatchString(fmt.Sprint(value))
+	case OpPrefix:
+		return strings.HasPrefix(strings.ToLower(fmt.Sprint(value)), strings.ToLower(filter.Values[0].(string)))
+	}
+	return false
+}
+
+// likePattern converts a SQL LIKE pattern into a case-insensitive regular expression.
+func likePattern(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("(?is)^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+// escapeLike escapes the LIKE wildcards in a literal value.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"database/sql"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// queryModel has a column of each kind the query language converts values to.
+type queryModel struct {
+	ID        int            `db:"id"`
+	Name      string         `db:"name"`
+	Score     float64        `db:"score"`
+	Active    bool           `db:"active"`
+	TeacherID *int           `db:"teacher_id"`
+	CreatedAt sql.NullString `db:"created_at"`
+	Secret    string         `db:"secret" query:"-"`
+	Note      string         `json:"note"`
+}
+
+func TestParseQuerySpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    QuerySpec
+		wantErr bool
+	}{
+		{name: "no parameters", query: "", want: QuerySpec{}},
+		{name: "reserved parameters", query: "page=2&limit=5&after=abc", want: QuerySpec{}},
+		{
+			name:  "plain value is eq",
+			query: "name=Ann",
+			want:  QuerySpec{Filters: []Filter{{Column: "name", Op: OpEq, Values: []interface{}{"Ann"}}}},
+		},
+		{
+			name:  "int value",
+			query: "id[gt]=7",
+			want:  QuerySpec{Filters: []Filter{{Column: "id", Op: OpGt, Values: []interface{}{7}}}},
+		},
+		{
+			name:  "float value",
+			query: "score[gte]=1.5",
+			want:  QuerySpec{Filters: []Filter{{Column: "score", Op: OpGte, Values: []interface{}{1.5}}}},
+		},
+		{
+			name:  "bool value",
+			query: "active=true",
+			want:  QuerySpec{Filters: []Filter{{Column: "active", Op: OpEq, Values: []interface{}{true}}}},
+		},
+		{
+			name:  "nullable int",
+			query: "teacher_id[ne]=3",
+			want:  QuerySpec{Filters: []Filter{{Column: "teacher_id", Op: OpNe, Values: []interface{}{3}}}},
+		},
+		{
+			name:  "in",
+			query: "id[in]=1,2,3",
+			want:  QuerySpec{Filters: []Filter{{Column: "id", Op: OpIn, Values: []interface{}{1, 2, 3}}}},
+		},
+		{
+			name:  "repeated parameter",
+			query: "name[ne]=Ann&name[ne]=Bob",
+			want: QuerySpec{Filters: []Filter{
+				{Column: "name", Op: OpNe, Values: []interface{}{"Ann"}},
+				{Column: "name", Op: OpNe, Values: []interface{}{"Bob"}},
+			}},
+		},
+		{
+			name:  "prefix",
+			query: "name[prefix]=Mc",
+			want:  QuerySpec{Filters: []Filter{{Column: "name", Op: OpPrefix, Values: []interface{}{"Mc"}}}},
+		},
+		{
+			name:  "is null",
+			query: "teacher_id[is_null]=true",
+			want:  QuerySpec{Filters: []Filter{{Column: "teacher_id", Op: OpIsNull, Values: []interface{}{true}}}},
+		},
+		{
+			name:  "date range includes the last day",
+			query: "created_at[between]=2024-01-01,2024-06-30",
+			want: QuerySpec{Filters: []Filter{
+				{Column: "created_at", Op: OpGte, Values: []interface{}{"2024-01-01"}},
+				{Column: "created_at", Op: OpLt, Values: []interface{}{"2024-07-01"}},
+			}},
+		},
+		{
+			name:  "date upper bound includes the day",
+			query: "created_at[lte]=2024-12-31",
+			want:  QuerySpec{Filters: []Filter{{Column: "created_at", Op: OpLt, Values: []interface{}{"2025-01-01"}}}},
+		},
+		{
+			name:  "datetime upper bound",
+			query: "created_at[lte]=2024-12-31 10:00:00",
+			want:  QuerySpec{Filters: []Filter{{Column: "created_at", Op: OpLte, Values: []interface{}{"2024-12-31 10:00:00"}}}},
+		},
+		{
+			name:  "int range",
+			query: "id[between]=1,5",
+			want: QuerySpec{Filters: []Filter{
+				{Column: "id", Op: OpGte, Values: []interface{}{1}},
+				{Column: "id", Op: OpLte, Values: []interface{}{5}},
+			}},
+		},
+		{
+			name:  "sort",
+			query: "sortby=name:desc&sortby=id",
+			want:  QuerySpec{Sort: []SortField{{Column: "name", Desc: true}, {Column: "id"}}},
+		},
+		{
+			name:  "sort direction ignores case",
+			query: "sortby=score:ASC",
+			want:  QuerySpec{Sort: []SortField{{Column: "score"}}},
+		},
+		{name: "unknown field", query: "email=a@b.com", wantErr: true},
+		{name: "field left out of queries", query: "secret=x", wantErr: true},
+		{name: "field without db tag", query: "note=x", wantErr: true},
+		{name: "malformed parameter", query: "Name=Ann", wantErr: true},
+		{name: "unknown operator", query: "name[regex]=A", wantErr: true},
+		{name: "not a number", query: "id=abc", wantErr: true},
+		{name: "not a number in list", query: "id[in]=1,x", wantErr: true},
+		{name: "not a boolean", query: "active=maybe", wantErr: true},
+		{name: "is null not a boolean", query: "teacher_id[is_null]=maybe", wantErr: true},
+		{name: "range with one value", query: "created_at[between]=2024-01-01", wantErr: true},
+		{name: "empty like pattern", query: "name[like]=", wantErr: true},
+		{name: "unknown sort field", query: "sortby=secret", wantErr: true},
+		{name: "invalid sort direction", query: "sortby=name:up", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ParseQuerySpec(queryModel{}, values)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseQuerySpec(%q) = %+v, want an error", tt.query, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuerySpec(%q): %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuerySpec(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildWhereClause(t *testing.T) {
+	tests := []struct {
+		name       string
+		filters    []Filter
+		wantClause string
+		wantArgs   []interface{}
+	}{
+		{name: "no filters"},
+		{
+			name:       "comparisons",
+			filters:    []Filter{{"id", OpGt, []interface{}{1}}, {"id", OpLte, []interface{}{9}}, {"name", OpNe, []interface{}{"Ann"}}},
+			wantClause: " AND id > ? AND id <= ? AND name <> ?",
+			wantArgs:   []interface{}{1, 9, "Ann"},
+		},
+		{
+			name:       "eq, gte and lt",
+			filters:    []Filter{EqFilter("class_id", 3), {"score", OpGte, []interface{}{50.0}}, {"score", OpLt, []interface{}{75.0}}},
+			wantClause: " AND class_id = ? AND score >= ? AND score < ?",
+			wantArgs:   []interface{}{3, 50.0, 75.0},
+		},
+		{
+			name:       "in",
+			filters:    []Filter{{"id", OpIn, []interface{}{1, 2, 3}}},
+			wantClause: " AND id IN (?, ?, ?)",
+			wantArgs:   []interface{}{1, 2, 3},
+		},
+		{
+			name:       "like keeps the wildcards",
+			filters:    []Filter{{"name", OpLike, []interface{}{"A%_n"}}},
+			wantClause: " AND name LIKE ?",
+			wantArgs:   []interface{}{"A%_n"},
+		},
+		{
+			name:       "prefix escapes the wildcards",
+			filters:    []Filter{{"name", OpPrefix, []interface{}{`50%_off\`}}},
+			wantClause: " AND name LIKE ?",
+			wantArgs:   []interface{}{`50\%\_off\\%`},
+		},
+		{
+			name:       "is null takes no argument",
+			filters:    []Filter{{"teacher_id", OpIsNull, []interface{}{true}}, {"created_at", OpIsNull, []interface{}{false}}},
+			wantClause: " AND teacher_id IS NULL AND created_at IS NOT NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, args := QuerySpec{Filters: tt.filters}.BuildWhereClause()
+			if clause != tt.wantClause {
+				t.Errorf("clause = %q, want %q", clause, tt.wantClause)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
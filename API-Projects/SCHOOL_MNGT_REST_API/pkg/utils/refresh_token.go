@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"os"
+	"time"
+)
+
+// DefaultRefreshTokenTTL is how long a refresh token stays valid when
+// JWT_REFRESH_EXPIRES_IN is not set.
+const DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+
+// GenerateRefreshToken returns a random refresh token for the client and
+// the hash stored in its place.
+func GenerateRefreshToken() (token string, tokenHash string, err error) {
+	token, err = randomToken(32)
+	if err != nil {
+		return "", "", ErrorHandler(err, "failed to generate refresh token")
+	}
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the stored form of a refresh token.
+func HashRefreshToken(token string) string {
+	return HashResetToken(token)
+}
+
+// NewTokenFamily returns the ID shared by the refresh tokens of one login.
+func NewTokenFamily() (string, error) {
+	family, err := randomToken(16)
+	if err != nil {
+		return "", ErrorHandler(err, "failed to generate refresh token")
+	}
+	return family, nil
+}
+
+// RefreshTokenTTL returns the lifetime of refresh tokens set with
+// JWT_REFRESH_EXPIRES_IN, e.g. "72h".
+func RefreshTokenTTL() (time.Duration, error) {
+	value := os.Getenv("JWT_REFRESH_EXPIRES_IN")
+	if value == "" {
+		return DefaultRefreshTokenTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, ErrorHandler(err, "Internal Error")
+	}
+	return ttl, nil
+}
+
+// GenerateCSRFToken returns a random CSRF token for a cookie session.
+func GenerateCSRFToken() (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", ErrorHandler(err, "failed to generate CSRF token")
+	}
+	return token, nil
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"time"
+)
+
+// DefaultResetTokenTTL is how long a password reset token stays valid
+// when RESET_TOKEN_EXPIRES_IN is not set.
+const DefaultResetTokenTTL = 15 * time.Minute
+
+// GenerateResetToken returns a random password reset token for the email
+// and the hash stored in its place, so a leaked database cannot be used to
+// reset passwords.
+func GenerateResetToken() (token string, tokenHash string, err error) {
+	token, err = randomToken(32)
+	if err != nil {
+		return "", "", ErrorHandler(err, "failed to generate reset token")
+	}
+	return token, HashResetToken(token), nil
+}
+
+// randomToken returns n random bytes, hex encoded.
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashResetToken returns the stored form of a password reset token.
+// The token is random, so a fast hash is enough.
+func HashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ResetTokenTTL returns the lifetime of password reset tokens set with
+// RESET_TOKEN_EXPIRES_IN, e.g. "30m".
+func ResetTokenTTL() (time.Duration, error) {
+	value := os.Getenv("RESET_TOKEN_EXPIRES_IN")
+	if value == "" {
+		return DefaultResetTokenTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, ErrorHandler(err, "Internal Error")
+	}
+	return ttl, nil
+}
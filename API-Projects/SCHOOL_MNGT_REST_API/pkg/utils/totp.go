@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TOTP settings of RFC 6238 understood by every authenticator app:
+// HMAC-SHA1, 6 digits and 30 second steps.
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew is how many steps a code may be off, for clock drift.
+	totpSkew = 1
+)
+
+// RecoveryCodeCount is the number of recovery codes issued with 2FA.
+const RecoveryCodeCount = 10
+
+// DefaultTOTPIssuer names the API in authenticator apps when TOTP_ISSUER is not set.
+const DefaultTOTPIssuer = "School Management API"
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random 160 bit TOTP secret, base32 encoded.
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", ErrorHandler(err, "failed to generate TOTP secret")
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURI returns the otpauth URI that authenticator apps read, usually
+// from a QR code, for the account and secret.
+func TOTPURI(account string, secret string) string {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = DefaultTOTPIssuer
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + query.Encode()
+}
+
+// TOTPStep returns the time step of t.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// TOTPCode returns the code of secret for a time step.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", ErrorHandler(err, "invalid TOTP secret")
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation of RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000), nil
+}
+
+// ValidateTOTP checks a code against the steps around now and returns the
+// step it matched, so the caller can refuse the same code twice.
+func ValidateTOTP(secret string, code string, now time.Time) (int64, bool) {
+	current := TOTPStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns one-time recovery codes, e.g. "3f9a-c01e-77b2",
+// and the hashes stored in their place.
+func GenerateRecoveryCodes() (codes []string, codeHashes []string, err error) {
+	for range RecoveryCodeCount {
+		token, err := randomToken(6)
+		if err != nil {
+			return nil, nil, ErrorHandler(err, "failed to generate recovery codes")
+		}
+		code := token[:4] + "-" + token[4:8] + "-" + token[8:]
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, HashRecoveryCode(code))
+	}
+	return codes, codeHashes, nil
+}
+
+// HashRecoveryCode returns the stored form of a recovery code. Case, spaces
+// and dashes are ignored, so codes can be typed as they are read.
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return HashResetToken(normalized)
+}
@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"school_management_api/internal/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// classCodePattern matches a grade from 1 to 12 followed by a stream letter, e.g. 10A.
+var classCodePattern = regexp.MustCompile(`^(?:[1-9]|1[0-2])[A-Z]$`)
+
+// academicYearPattern matches a school year spanning two calendar years, e.g. 2026-2027.
+var academicYearPattern = regexp.MustCompile(`^(\d{4})-(\d{4})$`)
+
+// Password length limits checked by the strongpassword rule.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 128
+)
+
+// validate is shared by every handler; validator.Validate caches struct
+// metadata and is safe for concurrent use.
+var validate = newValidator()
+
+// FieldError describes one failed rule of one field. Index is the position
+// of the item in a bulk request and is left out for single items.
+type FieldError struct {
+	Index   *int   `json:"index,omitempty"`
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON names, the names clients send
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("classcode", func(fl validator.FieldLevel) bool {
+		return IsClassCode(fl.Field().String())
+	})
+	v.RegisterValidation("academicyear", func(fl validator.FieldLevel) bool {
+		return IsAcademicYear(fl.Field().String())
+	})
+	v.RegisterValidation("role", func(fl validator.FieldLevel) bool {
+		return slices.Contains(models.ExecutiveRoles, fl.Field().String())
+	})
+	v.RegisterValidation("strongpassword", func(fl validator.FieldLevel) bool {
+		return IsStrongPassword(fl.Field().String())
+	})
+	v.RegisterValidation("apikeyscope", func(fl validator.FieldLevel) bool {
+		return slices.Contains(models.APIKeyScopes, fl.Field().String())
+	})
+	v.RegisterValidation("attendancestatus", func(fl validator.FieldLevel) bool {
+		return slices.Contains(models.AttendanceStatuses, fl.Field().String())
+	})
+	return v
+}
+
+// IsClassCode reports whether name is a grade from 1 to 12 followed by a stream letter, e.g. 10A.
+func IsClassCode(name string) bool {
+	return classCodePattern.MatchString(name)
+}
+
+// IsAcademicYear reports whether year names two consecutive calendar years, e.g. 2026-2027.
+func IsAcademicYear(year string) bool {
+	match := academicYearPattern.FindStringSubmatch(year)
+	if match == nil {
+		return false
+	}
+	start, _ := strconv.Atoi(match[1])
+	end, _ := strconv.Atoi(match[2])
+	return end == start+1
+}
+
+// CurrentAcademicYear returns the academic year in progress at now. A
+// school year starts in August, e.g. 2026-2027 from August 2026 to July 2027,
+// as in the migration that created the classes of existing students.
+func CurrentAcademicYear(now time.Time) string {
+	start := now.Year()
+	if now.Month() < time.August {
+		start--
+	}
+	return fmt.Sprintf("%d-%d", start, start+1)
+}
+
+// IsStrongPassword reports whether password has the allowed length and
+// contains an upper case letter, a lower case letter, a digit and a symbol.
+func IsStrongPassword(password string) bool {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return false
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	return upper && lower && digit && symbol
+}
+
+// ValidateItem checks every validate tag of item and returns a Validation
+// error listing all failed fields, or nil.
+func ValidateItem(item any) error {
+	if fieldErrors := validateStruct(item, nil); len(fieldErrors) > 0 {
+		return validationFailed(fieldErrors)
+	}
+	return nil
+}
+
+// ValidateItems checks every item of a bulk request and returns a single
+// Validation error listing the failed fields of all items, or nil.
+func ValidateItems[T any](items []T) error {
+	var fieldErrors []FieldError
+	for i := range items {
+		fieldErrors = append(fieldErrors, validateStruct(items[i], &i)...)
+	}
+	if len(fieldErrors) > 0 {
+		return validationFailed(fieldErrors)
+	}
+	return nil
+}
+
+// ValidatePartial checks only the fields present in a PATCH update against
+// the validate tags of model. Unknown fields and values of the wrong type
+// are reported as well.
+func ValidatePartial(model any, update map[string]interface{}) error {
+	if fieldErrors := validatePartial(model, update, nil); len(fieldErrors) > 0 {
+		return validationFailed(fieldErrors)
+	}
+	return nil
+}
+
+// ValidatePartialItems is ValidatePartial for a bulk PATCH request.
+func ValidatePartialItems(model any, updates []map[string]interface{}) error {
+	var fieldErrors []FieldError
+	for i := range updates {
+		fieldErrors = append(fieldErrors, validatePartial(model, updates[i], &i)...)
+	}
+	if len(fieldErrors) > 0 {
+		return validationFailed(fieldErrors)
+	}
+	return nil
+}
+
+func validationFailed(fieldErrors []FieldError) error {
+	return ValidationError(nil, "validation failed").WithDetails(fieldErrors)
+}
+
+// validateStruct runs the validator on item and converts its errors.
+func validateStruct(item any, index *int) []FieldError {
+	return toFieldErrors(validate.Struct(item), index)
+}
+
+// validatePartial copies the update into a zero model and validates only
+// the updated fields.
+func validatePartial(model any, update map[string]interface{}, index *int) []FieldError {
+	modelType := reflect.TypeOf(model)
+	item := reflect.New(modelType)
+
+	var fieldErrors []FieldError
+	var fieldNames []string
+	for key, value := range update {
+		if key == "id" {
+			continue
+		}
+
+		field, ok := fieldByJSONName(modelType, key)
+		if !ok {
+			fieldErrors = append(fieldErrors, newFieldError(index, key, "unknown", fmt.Sprintf("%s is not a valid field", key)))
+			continue
+		}
+
+		// Pointer fields are nullable, a JSON null clears them
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+			if value == nil {
+				fieldNames = append(fieldNames, field.Name)
+				continue
+			}
+		}
+
+		val := reflect.ValueOf(value)
+		if !val.IsValid() || !val.Type().ConvertibleTo(fieldType) || isNumberToString(val, fieldType) {
+			fieldErrors = append(fieldErrors, newFieldError(index, key, "type", fmt.Sprintf("%s has the wrong type", key)))
+			continue
+		}
+		converted := val.Convert(fieldType)
+		if field.Type.Kind() == reflect.Ptr {
+			pointer := reflect.New(fieldType)
+			pointer.Elem().Set(converted)
+			converted = pointer
+		}
+		item.Elem().FieldByIndex(field.Index).Set(converted)
+		fieldNames = append(fieldNames, field.Name)
+	}
+
+	if len(fieldNames) > 0 {
+		fieldErrors = append(fieldErrors, toFieldErrors(validate.StructPartial(item.Interface(), fieldNames...), index)...)
+	}
+	return fieldErrors
+}
+
+// isNumberToString reports a JSON number bound for a string field; reflect
+// would convert it to a rune instead of rejecting it.
+func isNumberToString(val reflect.Value, fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.String && val.Kind() != reflect.String
+}
+
+func fieldByJSONName(modelType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if strings.Split(field.Tag.Get("json"), ",")[0] == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func toFieldErrors(err error, index *int) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fieldErrors := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		fieldErrors[i] = newFieldError(index, fe.Field(), fe.Tag(), ruleMessage(fe))
+	}
+	return fieldErrors
+}
+
+func newFieldError(index *int, field, rule, message string) FieldError {
+	fieldError := FieldError{Field: field, Rule: rule, Message: message}
+	if index != nil {
+		i := *index
+		fieldError.Index = &i
+	}
+	return fieldError
+}
+
+// ruleUnit returns what the min and max rules count for the field: items
+// of a list, characters of a string, or nothing for a number.
+func ruleUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	case reflect.String:
+		return "characters"
+	}
+	return ""
+}
+
+// ruleMessage returns a readable message for a failed rule.
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fe.Field())
+	case "max":
+		return strings.TrimSpace(fmt.Sprintf("%s must be at most %s %s", fe.Field(), fe.Param(), ruleUnit(fe)))
+	case "min":
+		return strings.TrimSpace(fmt.Sprintf("%s must be at least %s %s", fe.Field(), fe.Param(), ruleUnit(fe)))
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", fe.Field(), fe.Param())
+	case "datetime":
+		return fmt.Sprintf("%s must be a date formatted as %s", fe.Field(), fe.Param())
+	case "alphanum":
+		return fmt.Sprintf("%s may only contain letters and digits", fe.Field())
+	case "classcode":
+		return fmt.Sprintf("%s must be a grade from 1 to 12 followed by a capital letter, e.g. 10A", fe.Field())
+	case "academicyear":
+		return fmt.Sprintf("%s must be two consecutive years, e.g. 2026-2027", fe.Field())
+	case "role":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.Join(models.ExecutiveRoles, ", "))
+	case "attendancestatus":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.Join(models.AttendanceStatuses, ", "))
+	case "eqfield":
+		return fmt.Sprintf("%s does not match", fe.Field())
+	case "nefield":
+		return fmt.Sprintf("%s must be different", fe.Field())
+	case "strongpassword":
+		return fmt.Sprintf("%s must be %d to %d characters with upper and lower case letters, a digit and a symbol",
+			fe.Field(), MinPasswordLength, MaxPasswordLength)
+	case "apikeyscope":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.Join(models.APIKeyScopes, ", "))
+	}
+	return fmt.Sprintf("%s failed the %s rule", fe.Field(), fe.Tag())
+}